@@ -2,10 +2,24 @@ package main
 
 import (
 	"Venom-Crawler/internal/utils"
+	"Venom-Crawler/pkg/auth"
+	"Venom-Crawler/pkg/block"
 	"Venom-Crawler/pkg/crawlergo"
 	"Venom-Crawler/pkg/crawlergo/config"
 	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
 	"Venom-Crawler/pkg/katana/types"
+	"Venom-Crawler/pkg/openapi"
+	"Venom-Crawler/pkg/pathfuzz"
+	"Venom-Crawler/pkg/resolve"
+	"Venom-Crawler/pkg/rewrite"
+	"Venom-Crawler/pkg/session"
+	"Venom-Crawler/pkg/soft404"
+	"Venom-Crawler/pkg/sourcemap"
+	"Venom-Crawler/pkg/wellknown"
+	"Venom-Crawler/pkg/wsdl"
 	"flag"
 	"fmt"
 	"github.com/ttacon/chalk"
@@ -13,8 +27,11 @@ import (
 	"log"
 	"math"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Result struct {
@@ -46,7 +63,7 @@ func existCheck(filename string) {
 	}
 }
 func startCheck() {
-	arr := []string{"katana-result.txt", "result-all.txt", "crawlergo-result.txt"}
+	arr := []string{"katana-result.txt", "result-all.txt", "crawlergo-result.txt", "auth-hosts.txt", "roles-report.json", "vhost-report.json", "sourcemap-sources.json", "openapi-specs.json", "graphql-operations.json", "wsdl-services.json", "well-known.json", "soft404-report.json"}
 	for _, s := range arr {
 		existCheck(s)
 	}
@@ -56,7 +73,6 @@ var (
 	taskConfig              crawlergo.TaskConfig
 	outputMode              string
 	postData                string
-	signalChan              chan os.Signal
 	ignoreKeywords          = cli.NewStringSlice(config.DefaultIgnoreKeywords...)
 	customFormTypeValues    = cli.NewStringSlice()
 	customFormKeywordValues = cli.NewStringSlice()
@@ -101,8 +117,151 @@ V::::::V           V::::::V
 	urlTxt := flag.String("urlTxtPath", "", chalk.Green.Color("如果需求是批量爬行URL，那需要将URL写入txt，然后将路径放入"))
 	encode := flag.Bool("encodeUrlWithCharset", false, chalk.Green.Color("是否对URL进行编码"))
 	depth := flag.Int("depth", 3, chalk.Green.Color("最大爬行深度，默认是3"))
+	authRules := flag.String("auth", "", chalk.Green.Color("401/407认证凭据，用,分割，如：*.example.com=admin:123456,proxy=user:pass,intranet.local=cancel"))
+	loginScript := flag.String("login", "", chalk.Green.Color("登录脚本路径(yaml)，爬行前在浏览器中执行登录，登录后的Cookie和localStorage应用到两个爬虫"))
+	logoutUrl := flag.String("logoutUrl", "", chalk.Green.Color("会话失效特征：重定向到的登录页URL片段，如：/login"))
+	logoutRegex := flag.String("logoutRegex", "", chalk.Green.Color("会话失效特征：响应体正则，如：请先登录|Please sign in"))
+	logoutStatus := flag.String("logoutStatus", "", chalk.Green.Color("会话失效特征：响应状态码，用,分割，如：401,403"))
+	cookieFile := flag.String("cookieFile", "", chalk.Green.Color("导入Cookie文件路径，支持Netscape格式的cookies.txt和浏览器导出的JSON"))
+	storageFile := flag.String("storageFile", "", chalk.Green.Color("导入Storage文件路径(json)，按origin保存的localStorage/sessionStorage"))
+	cookieJarDir := flag.String("cookieJar", "", chalk.Green.Color("katana Cookie持久化目录，每个站点的Cookie保存为<host>.json，下次运行时自动加载"))
+	csrfRefresh := flag.Bool("csrf", false, chalk.Green.Color("katana提交表单前重新获取表单所在页面，刷新CSRF令牌"))
+	csrfTokens := flag.String("csrfTokens", "", chalk.Green.Color("CSRF令牌字段名特征，用,分割，默认：csrf,xsrf,_token,authenticity_token,__RequestVerificationToken等"))
+	rewritePath := flag.String("rewrite", "", chalk.Green.Color("请求/响应重写规则路径(yaml)，两个爬虫的浏览器拦截层都会应用"))
+	blockTrackers := flag.Bool("block", false, chalk.Green.Color("浏览器中拦截内置的统计、广告、字体、地图、社交组件等第三方域名"))
+	blockDomains := flag.String("blockDomains", "", chalk.Green.Color("浏览器中拦截的域名(包含子域名)，用,分割"))
+	blockTypes := flag.String("blockTypes", "", chalk.Green.Color("浏览器中拦截的资源类型，用,分割，可选：Image,Media,Font,Stylesheet"))
+	blockRegex := flag.String("blockRegex", "", chalk.Green.Color("浏览器中拦截的URL正则"))
+	resolveRules := flag.String("resolve", "", chalk.Green.Color("自定义域名解析，格式host:port:ip，用,分割，port为*时匹配全部端口，如：www.example.com:443:10.0.0.8"))
+	networkQuiet := flag.Int("networkQuiet", int(config.NetworkQuietTime.Milliseconds()), chalk.Green.Color("crawlergo网络空闲判定时长(毫秒)，导航、表单提交和事件触发后等待没有未完成的请求持续该时长，为0时使用固定等待"))
+	networkIdleMax := flag.Int("networkIdleMax", int(config.NetworkIdleMaxWait.Milliseconds()), chalk.Green.Color("crawlergo等待网络空闲的最长时间(毫秒)"))
+	maxScroll := flag.Int("maxScroll", config.MaxScrollTimes, chalk.Green.Color("crawlergo滚动页面加载无限滚动和懒加载内容的最大轮数，为0时不滚动"))
+	sourceMaps := flag.Bool("sourcemap", false, chalk.Green.Color("获取JS文件的源码映射(.map)，从原始源码中提取端点，恢复的源码文件列表输出到sourcemap-sources.json"))
+	openAPI := flag.Bool("openapi", false, chalk.Green.Color("探测OpenAPI/Swagger文档(/swagger.json、/v2/api-docs、/v3/api-docs、/openapi.json、/swagger-ui/等)，将其中的接口展开为带示例参数的请求进行爬行，非GET接口只记录，结果输出到openapi-specs.json"))
+	jsReplay := flag.Bool("jsReplay", false, chalk.Green.Color("同时爬行JS和源码映射中解析出的POST、PUT、PATCH、DELETE等非GET接口，会使用占位参数修改目标数据，默认只记录到结果中"))
+	openAPIReplay := flag.Bool("openapiReplay", false, chalk.Green.Color("同时爬行OpenAPI/Swagger文档中的POST、PUT、PATCH、DELETE等非GET接口，会使用示例参数修改目标数据"))
+	graphQL := flag.Bool("graphql", false, chalk.Green.Color("识别GraphQL端点，从XHR请求和JS(gql模板、查询字符串、Apollo配置)中收集不同的操作，每个操作作为单独的结果，操作及变量类型输出到graphql-operations.json"))
+	graphQLIntrospect := flag.Bool("graphqlIntrospect", false, chalk.Green.Color("对发现的GraphQL端点执行内省查询，将Schema中的每个查询作为单独的结果，变更只记录，开启时自动开启-graphql"))
+	graphQLMutations := flag.Bool("graphqlMutations", false, chalk.Green.Color("发送内省得到的变更(mutation)，默认只记录到graphql-operations.json，疑似破坏性的变更(删除、注销、重置等)始终不发送"))
+	services := flag.Bool("wsdl", false, chalk.Green.Color("枚举WSDL/SOAP和WADL服务：解析爬到的服务描述，探测SOAP端点的?wsdl和常见位置，为每个操作生成示例SOAP信封或请求，只爬行WADL的GET请求，结果输出到wsdl-services.json"))
+	servicesReplay := flag.Bool("wsdlReplay", false, chalk.Green.Color("同时发送SOAP操作和WADL的POST、PUT、DELETE等请求，会使用示例参数修改目标数据"))
+	wellKnown := flag.Bool("wellKnown", false, chalk.Green.Color("获取常见文件(robots.txt及其声明的站点地图、站点地图索引和gzip站点地图、security.txt、crossdomain.xml、clientaccesspolicy.xml、manifest.json、humans.txt、ads.txt、openid-configuration)，提取其中的URL和主机名，结果输出到well-known.json"))
+	pathFuzz := flag.Bool("fuzz", false, chalk.Green.Color("crawlergo对目标进行路径fuzz，使用内置的常见路径列表，命中的路径加入爬行队列"))
+	fuzzDict := flag.String("fuzzDict", "", chalk.Green.Color("路径fuzz字典txt路径，指定时使用字典代替内置路径列表，自动开启-fuzz"))
+	fuzzDepth := flag.Int("fuzzDepth", 2, chalk.Green.Color("路径fuzz的递归深度，根目录为第1层，发现的目录继续fuzz直到该深度"))
+	fuzzExt := flag.String("fuzzExt", "", chalk.Green.Color("路径fuzz的扩展名，用,分割，如php,asp,jsp，追加到没有扩展名的路径后"))
+	fuzzBackup := flag.Bool("fuzzBackup", false, chalk.Green.Color("请求路径fuzz发现文件的备份和编辑器残留文件(.bak、~、.swp、.old)"))
+	fuzzThreads := flag.Int("fuzzThreads", pathfuzz.DefaultThreads, chalk.Green.Color("路径fuzz的并发数"))
+	fuzzRate := flag.Int("fuzzRate", 50, chalk.Green.Color("路径fuzz对每个主机每秒的最大请求数，不大于0时不限制"))
+	fuzzFilterStatus := flag.String("fuzzFilterStatus", "", chalk.Green.Color("路径fuzz忽略的状态码，用,分割"))
+	fuzzFilterLength := flag.String("fuzzFilterLength", "", chalk.Green.Color("路径fuzz忽略的响应长度，用,分割"))
+	soft404Mode := flag.String("soft404", "", chalk.Green.Color("软404检测：按站点和目录请求几个随机的不存在路径作为基准(状态码、长度区间、标题、simhash)，与基准相同的响应视为软404，mark标记、drop丢弃，fuzz结果中的软404总是丢弃，结果输出到soft404-report.json"))
+	jsArchiveDir := flag.String("jsArchive", "", chalk.Green.Color("JS归档目录，保存两个爬虫见到的所有JS文件和内联脚本，按内容哈希去重，按站点分目录，清单写入manifest.json"))
+	vhosts := flag.String("vhosts", "", chalk.Green.Color("虚拟主机探测模式：候选主机名，用,分割或主机名字典txt路径，以-url指定的IP/URL为目标，对内容不同的虚拟主机分别爬行"))
+	rolesPath := flag.String("roles", "", chalk.Green.Color("多身份爬行配置路径(yaml)，每个身份单独爬行一次并输出越权差异报告roles-report.json"))
+	rolesReplay := flag.Bool("rolesReplay", false, chalk.Green.Color("多身份模式下同时以低权限身份重放高权限独有的POST、PUT、PATCH、DELETE等非GET接口，会修改目标数据，默认只重放GET/HEAD"))
 	flag.Parse()
 	startCheck()
+	credentials, err := auth.ParseStore(*authRules)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 认证凭据解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	resolveMap, err := resolve.Parse(*resolveRules)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 自定义域名解析规则解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	requests.DialContext = resolveMap.Dial(nil)
+	var loginState *session.State
+	if *loginScript != "" {
+		loginState = runLogin(*loginScript, *chromium, *customHeaders, *proxy, *isHeadless, resolveMap.HostResolverRules())
+	}
+	if *cookieFile != "" || *storageFile != "" {
+		if loginState == nil {
+			loginState = session.NewState()
+		}
+		importSessionFiles(loginState, *cookieFile, *storageFile)
+	}
+	signature, err := session.ParseSignature(*logoutUrl, *logoutRegex, *logoutStatus)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 会话失效特征解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	var sessionMonitor *session.Monitor
+	if signature != nil {
+		if loginState == nil {
+			loginState = session.NewState()
+		}
+		snapshot := loginState.Clone()
+		sessionMonitor = session.NewMonitor(signature, loginState, func() (*session.State, error) {
+			// 有登录脚本则重新登录，否则恢复初始的Cookie
+			if *loginScript != "" {
+				return doLogin(*loginScript, *chromium, *customHeaders, *proxy, *isHeadless, resolveMap.HostResolverRules())
+			}
+			return snapshot.Clone(), nil
+		})
+	}
+	var rewriteRules *rewrite.RuleSet
+	if *rewritePath != "" {
+		rewriteRules, err = rewrite.Load(*rewritePath)
+		if err != nil {
+			log.Println(chalk.Red.Color("error: 重写规则解析失败, " + err.Error()))
+			os.Exit(0)
+		}
+	}
+	var domains []string
+	if *blockTrackers {
+		domains = append(domains, block.DefaultTrackerDomains...)
+	}
+	if *blockDomains != "" {
+		domains = append(domains, strings.Split(*blockDomains, ",")...)
+	}
+	var blockTypeList []string
+	if *blockTypes != "" {
+		blockTypeList = strings.Split(*blockTypes, ",")
+	}
+	blockPolicy, err := block.NewPolicy(domains, blockTypeList, []string{*blockRegex})
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 拦截策略解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	var sourceMapRecorder *sourcemap.Recorder
+	if *sourceMaps {
+		sourceMapRecorder = sourcemap.NewRecorder()
+	}
+	var jsArchive *jsarchive.Archive
+	if *jsArchiveDir != "" {
+		jsArchive, err = jsarchive.New(*jsArchiveDir)
+		if err != nil {
+			log.Println(chalk.Red.Color("error: 创建JS归档目录失败, " + err.Error()))
+			os.Exit(0)
+		}
+	}
+	var openAPICatalog *openapi.Catalog
+	if *openAPI {
+		openAPICatalog = openapi.NewCatalog(*openAPIReplay)
+	}
+	var graphQLRecorder *graphql.Recorder
+	if *graphQL || *graphQLIntrospect {
+		graphQLRecorder = graphql.NewRecorder(*graphQLIntrospect, *graphQLMutations)
+	}
+	var wellKnownRecorder *wellknown.Recorder
+	if *wellKnown {
+		wellKnownRecorder = wellknown.NewRecorder()
+	}
+	var serviceCatalog *wsdl.Catalog
+	if *services {
+		serviceCatalog = wsdl.NewCatalog(*servicesReplay)
+	}
+	var soft404Detector *soft404.Detector
+	if *soft404Mode != "" {
+		soft404Detector, err = soft404.NewDetector(*soft404Mode)
+		if err != nil {
+			log.Println(chalk.Red.Color("error: 软404检测模式解析失败, " + err.Error()))
+			os.Exit(0)
+		}
+	}
 	options := &types.Options{}
 	if *urlTxt == "" && *url == "" {
 		log.Println(chalk.Red.Color("URL文件和URL必须有一个！！！"))
@@ -128,7 +287,11 @@ V::::::V           V::::::V
 		options.ScrapeJSResponses = true
 		options.AutomaticFormFill = true
 	}
+	options.JSReplay = *jsReplay
 	options.KnownFiles = ""
+	if *wellKnown {
+		options.KnownFiles = "all"
+	}
 	options.BodyReadSize = math.MaxInt
 	options.Timeout = 10
 	options.Retries = 1
@@ -154,14 +317,31 @@ V::::::V           V::::::V
 	options.Parallelism = 10
 	options.RateLimit = 150
 	options.ExtensionFilter = []string{"css", "jpg", "jpeg", "png", "ico", "gif", "webp", "mp3", "mp4", "ttf", "tif", "tiff", "woff", "woff2"}
-	katanaRun(options)
-
-	// 执行crawlergo之前将结果文件读取
-
-	urls = utils.GetUrlListFromTxt("katana-result.txt")
-	if len(urls) > 0 {
-		taskConfig.URLList = dealUrlScope(urls)
+	options.Credentials = credentials
+	options.Session = loginState
+	options.SessionMonitor = sessionMonitor
+	options.Rewrite = rewriteRules
+	options.Block = blockPolicy
+	options.Resolve = resolveMap
+	options.SourceMaps = sourceMapRecorder
+	options.JSArchive = jsArchive
+	options.OpenAPI = openAPICatalog
+	options.GraphQL = graphQLRecorder
+	options.Services = serviceCatalog
+	options.WellKnown = wellKnownRecorder
+	options.Soft404 = soft404Detector
+	options.RefreshCSRFTokens = *csrfRefresh
+	if *csrfTokens != "" {
+		options.CSRFTokenPatterns = strings.Split(*csrfTokens, ",")
 	}
+	if *cookieJarDir != "" {
+		if err := os.MkdirAll(*cookieJarDir, 0755); err != nil {
+			log.Println(chalk.Red.Color("error: 创建Cookie持久化目录失败, " + err.Error()))
+			os.Exit(0)
+		}
+		options.CookieJarDir = *cookieJarDir
+	}
+
 	// Crawlergo配置
 	ignoreList := make([]string, 0)
 	taskConfig.NoHeadless = *isHeadless
@@ -173,19 +353,113 @@ V::::::V           V::::::V
 	taskConfig.ExtraHeadersString = *customHeaders
 	taskConfig.MaxTabsCount = config.MaxTabsCount
 	taskConfig.PathFromRobots = true
+	taskConfig.PathByFuzz = *pathFuzz || *fuzzDict != ""
+	taskConfig.FuzzDictPath = *fuzzDict
+	taskConfig.PathFuzz = pathfuzz.Options{
+		Depth:     *fuzzDepth,
+		Backups:   *fuzzBackup,
+		Threads:   *fuzzThreads,
+		RateLimit: *fuzzRate,
+	}
+	if *fuzzExt != "" {
+		taskConfig.PathFuzz.Extensions = strings.Split(*fuzzExt, ",")
+	}
+	if taskConfig.PathFuzz.FilterStatus, err = parseIntList(*fuzzFilterStatus); err != nil {
+		log.Println(chalk.Red.Color("error: 路径fuzz忽略的状态码解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	if taskConfig.PathFuzz.FilterLength, err = parseIntList(*fuzzFilterLength); err != nil {
+		log.Println(chalk.Red.Color("error: 路径fuzz忽略的响应长度解析失败, " + err.Error()))
+		os.Exit(0)
+	}
 	taskConfig.TabRunTimeout = config.TabRunTimeout
 	taskConfig.DomContentLoadedTimeout = config.DomContentLoadedTimeout
 	taskConfig.EventTriggerMode = config.EventTriggerAsync
 	taskConfig.EventTriggerInterval = config.EventTriggerInterval
 	taskConfig.BeforeExitDelay = config.BeforeExitDelay
+	taskConfig.NetworkQuietTime = time.Duration(*networkQuiet) * time.Millisecond
+	if *networkQuiet <= 0 {
+		taskConfig.NetworkQuietTime = -1
+	}
+	taskConfig.NetworkIdleMaxWait = time.Duration(*networkIdleMax) * time.Millisecond
+	taskConfig.MaxScrollTimes = *maxScroll
+	if *maxScroll <= 0 {
+		taskConfig.MaxScrollTimes = -1
+	}
+	// 和katana的ScrapeJSResponses一致，simple模式不解析JS分块
+	taskConfig.ScrapeJSChunks = *mode != "simple"
+	taskConfig.JSReplay = *jsReplay
 	taskConfig.MaxRunTime = config.MaxRunTime
 	taskConfig.CustomFormValues = map[string]string{}
 	if *blackKey != "" {
 		ignoreList = strings.Split(*blackKey, ",")
 	}
 	taskConfig.IgnoreKeywords = ignoreList
+	taskConfig.Credentials = credentials
+	taskConfig.Session = loginState
+	taskConfig.SessionMonitor = sessionMonitor
+	taskConfig.Rewrite = rewriteRules
+	taskConfig.Block = blockPolicy
+	taskConfig.Resolve = resolveMap
+	taskConfig.SourceMaps = sourceMapRecorder
+	taskConfig.JSArchive = jsArchive
+	taskConfig.OpenAPI = openAPICatalog
+	taskConfig.GraphQL = graphQLRecorder
+	taskConfig.Services = serviceCatalog
+	taskConfig.WellKnown = wellKnownRecorder
+	taskConfig.Soft404 = soft404Detector
+
+	// 爬行结束后输出各项报告，每种模式都要调用
+	report := func() {
+		// 输出要求认证的站点，便于发现受保护的区域
+		for _, host := range credentials.Challenged() {
+			log.Println(chalk.Yellow.Color("要求认证的站点: " + host))
+			utils.AppendToFile("auth-hosts.txt", host)
+		}
+		reportBlocked(blockPolicy)
+		reportSourceMaps(sourceMapRecorder)
+		reportJSArchive(jsArchive, *jsArchiveDir)
+		reportOpenAPI(openAPICatalog)
+		reportGraphQL(graphQLRecorder)
+		reportServices(serviceCatalog)
+		reportWellKnown(wellKnownRecorder)
+		reportSoft404(soft404Detector)
+	}
+
+	// 虚拟主机模式：探测目标上内容不同的虚拟主机，然后分别爬行
+	if *vhosts != "" {
+		if *url == "" {
+			log.Println(chalk.Red.Color("虚拟主机探测模式需要使用-url指定目标！！！"))
+			os.Exit(0)
+		}
+		runVhosts(*url, loadVhostCandidates(*vhosts), options)
+		report()
+		return
+	}
+
+	// 多身份模式：每个身份单独爬行一次，然后输出越权差异报告
+	if *rolesPath != "" {
+		options.Session = nil
+		options.SessionMonitor = nil
+		taskConfig.Session = nil
+		taskConfig.SessionMonitor = nil
+		runRoles(*rolesPath, options, *rolesReplay)
+		report()
+		return
+	}
+
+	katanaRun(options)
+
+	// 执行crawlergo之前将结果文件读取
+
+	urls = utils.GetUrlListFromTxt("katana-result.txt")
+	if len(urls) > 0 {
+		taskConfig.URLList = dealUrlScope(urls)
+	}
 	crawlergoRun()
 
+	report()
+
 	//全部程序执行完之后将三个文件进行合并，这里暂时只有两个
 	finalResult := make([]string, 0)
 	arr := []string{"katana-result.txt", "crawlergo-result.txt"}
@@ -200,6 +474,169 @@ V::::::V           V::::::V
 	}
 }
 
+/*
+*
+输出拦截策略阻断的请求统计
+*/
+func reportBlocked(policy *block.Policy) {
+	var total int64
+	for _, count := range policy.Counts() {
+		total += count.Count
+		log.Println(chalk.Yellow.Color(fmt.Sprintf("拦截 %s: %d", count.Reason, count.Count)))
+	}
+	if total > 0 {
+		log.Println(chalk.Green.Color(fmt.Sprintf("共拦截请求%d个", total)))
+	}
+}
+
+/*
+*
+输出恢复的源码映射，未开启源码映射获取时不输出
+*/
+func reportSourceMaps(recorder *sourcemap.Recorder) {
+	if recorder == nil {
+		return
+	}
+	results := recorder.Results()
+	var sources int
+	for _, result := range results {
+		sources += len(result.Sources)
+	}
+	if err := recorder.WriteJSON("sourcemap-sources.json"); err != nil {
+		log.Println(chalk.Red.Color("error: 源码映射结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共恢复源码映射%d个, 原始源码文件%d个, 详见sourcemap-sources.json", len(results), sources)))
+}
+
+/*
+*
+写入JS归档清单，未开启JS归档时不输出
+*/
+func reportJSArchive(archive *jsarchive.Archive, dir string) {
+	if archive == nil {
+		return
+	}
+	if err := archive.WriteManifest(); err != nil {
+		log.Println(chalk.Red.Color("error: JS归档清单写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共保存JS文件%d个, 详见%s", len(archive.Entries()), filepath.Join(dir, jsarchive.ManifestFile))))
+}
+
+/*
+*
+输出发现的OpenAPI/Swagger文档及展开的请求，未开启文档探测时不输出
+*/
+func reportOpenAPI(catalog *openapi.Catalog) {
+	if catalog == nil {
+		return
+	}
+	entries := catalog.Entries()
+	var requests int
+	for _, entry := range entries {
+		requests += len(entry.Requests)
+	}
+	if err := catalog.WriteJSON("openapi-specs.json"); err != nil {
+		log.Println(chalk.Red.Color("error: OpenAPI文档结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共发现OpenAPI文档%d个, 展开请求%d个, 详见openapi-specs.json", len(entries), requests)))
+}
+
+/*
+*
+输出识别到的GraphQL操作，未开启GraphQL识别时不输出
+*/
+func reportGraphQL(recorder *graphql.Recorder) {
+	if recorder == nil {
+		return
+	}
+	operations := recorder.Operations()
+	endpoints := map[string]struct{}{}
+	for _, operation := range operations {
+		endpoints[operation.Endpoint] = struct{}{}
+	}
+	if err := recorder.WriteJSON("graphql-operations.json"); err != nil {
+		log.Println(chalk.Red.Color("error: GraphQL操作结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共识别GraphQL端点%d个, 操作%d个, 详见graphql-operations.json", len(endpoints), len(operations))))
+}
+
+/*
+*
+输出枚举到的WSDL/WADL服务描述及其操作，未开启服务枚举时不输出
+*/
+func reportServices(catalog *wsdl.Catalog) {
+	if catalog == nil {
+		return
+	}
+	entries := catalog.Entries()
+	var operations int
+	for _, entry := range entries {
+		operations += len(entry.Operations)
+	}
+	if err := catalog.WriteJSON("wsdl-services.json"); err != nil {
+		log.Println(chalk.Red.Color("error: WSDL/WADL服务结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共发现WSDL/WADL服务描述%d个, 操作%d个, 详见wsdl-services.json", len(entries), operations)))
+}
+
+/*
+*
+输出获取到的常见文件及其中的主机名，未开启常见文件获取时不输出
+*/
+func reportWellKnown(recorder *wellknown.Recorder) {
+	if recorder == nil {
+		return
+	}
+	results := recorder.Results()
+	hosts := recorder.Hosts()
+	if err := recorder.WriteJSON("well-known.json"); err != nil {
+		log.Println(chalk.Red.Color("error: 常见文件结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共获取常见文件%d个, 主机名%d个, 详见well-known.json", len(results), len(hosts))))
+}
+
+/*
+*
+输出检测到的软404页面，未开启软404检测时不输出
+*/
+func reportSoft404(detector *soft404.Detector) {
+	if detector == nil {
+		return
+	}
+	matches := detector.Matches()
+	if err := detector.WriteJSON("soft404-report.json"); err != nil {
+		log.Println(chalk.Red.Color("error: 软404检测结果写入失败, " + err.Error()))
+		return
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共检测到软404页面%d个, 详见soft404-report.json", len(matches))))
+}
+
+/*
+*
+解析用,分割的整数列表
+*/
+func parseIntList(value string) ([]int, error) {
+	var list []int
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		number, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, number)
+	}
+	return list, nil
+}
+
 func main() {
 	cmd()
 }
@@ -4,9 +4,11 @@ import (
 	"Venom-Crawler/internal/utils"
 	"Venom-Crawler/pkg/crawlergo"
 	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/engine"
 	"Venom-Crawler/pkg/crawlergo/model"
 	"Venom-Crawler/pkg/crawlergo/tools"
 	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/session"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,11 +37,29 @@ func dealUrl(_url string) model.Request {
 }
 
 func crawlergoRun() {
-	signalChan = make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT)
+	result := runCrawlergoTask()
+	if result == nil {
+		return
+	}
+
+	// 内置请求代理
+	if pushAddress != "" {
+		Push2Proxy(result.ReqList)
+	}
+
+	// 输出结果
+	outputResult(result)
+
+}
+
+/*
+*
+根据当前的taskConfig执行一次crawlergo爬行任务，返回爬行结果
+*/
+func runCrawlergoTask() *crawlergo.Result {
 	if taskConfig.URL == "" && len(taskConfig.URLList) == 0 {
 		fmt.Println("error: URL和URL集合文件必须得有一个")
-		return
+		return nil
 	}
 	var targets []*model.Request
 	if taskConfig.URL != "" {
@@ -59,7 +79,7 @@ func crawlergoRun() {
 	}
 
 	if len(targets) == 0 {
-		return
+		return nil
 	}
 
 	var err error
@@ -93,18 +113,15 @@ func crawlergoRun() {
 	if _, ok := taskConfig.CustomFormValues["default"]; !ok {
 		taskConfig.CustomFormValues["default"] = config.DefaultInputText
 	}
-	go handleExit(task)
+	// 多身份和虚拟主机模式会执行多次任务，任务结束后注销信号处理
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT)
+	defer signal.Stop(signalChan)
+	done := make(chan struct{})
+	defer close(done)
+	go handleExit(task, signalChan, done)
 	task.Run()
-	result := task.Result
-
-	// 内置请求代理
-	if pushAddress != "" {
-		Push2Proxy(result.ReqList)
-	}
-
-	// 输出结果
-	outputResult(result)
-
+	return task.Result
 }
 
 func getOption() model.Options {
@@ -194,8 +211,73 @@ func (p *ProxyTask) doRequest() {
 		&requests.ReqOptions{Timeout: 1, AllowRedirect: false, Proxy: p.pushProxy})
 }
 
-func handleExit(t *crawlergo.CrawlerTask) {
-	<-signalChan
+/*
+*
+爬行前执行登录脚本，获取登录后的会话状态
+*/
+func runLogin(scriptPath string, chromiumPath string, headersString string, proxy string, noHeadless bool, hostResolverRules string) *session.State {
+	if _, err := session.LoadScript(scriptPath); err != nil {
+		log.Println(chalk.Red.Color("error: 登录脚本解析失败, " + err.Error()))
+		os.Exit(-1)
+	}
+	state, err := doLogin(scriptPath, chromiumPath, headersString, proxy, noHeadless, hostResolverRules)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 登录脚本执行失败, " + err.Error()))
+		return nil
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("登录成功, 获取到%d个Cookie", len(state.GetCookies()))))
+	return state
+}
+
+/*
+*
+启动独立的浏览器执行一次登录脚本
+*/
+func doLogin(scriptPath string, chromiumPath string, headersString string, proxy string, noHeadless bool, hostResolverRules string) (*session.State, error) {
+	script, err := session.LoadScript(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	var headers map[string]interface{}
+	if headersString != "" {
+		if err := json.Unmarshal([]byte(headersString), &headers); err != nil {
+			log.Println(chalk.Red.Color("error: 自定义参数头不能被序列化"))
+		}
+	}
+	browser := engine.InitBrowser(chromiumPath, headers, proxy, noHeadless, hostResolverRules)
+	defer browser.Close()
+	return browser.Login(script, config.LoginTimeout)
+}
+
+/*
+*
+导入Cookie和Storage文件到会话状态
+*/
+func importSessionFiles(state *session.State, cookieFile string, storageFile string) {
+	if cookieFile != "" {
+		cookies, err := session.LoadCookieFile(cookieFile)
+		if err != nil {
+			log.Println(chalk.Red.Color("error: Cookie文件解析失败, " + err.Error()))
+			os.Exit(0)
+		}
+		state.AddCookies(cookies)
+		log.Println(chalk.Green.Color(fmt.Sprintf("导入%d个Cookie", len(cookies))))
+	}
+	if storageFile != "" {
+		if err := session.LoadStorageFile(storageFile, state); err != nil {
+			log.Println(chalk.Red.Color("error: Storage文件解析失败, " + err.Error()))
+			os.Exit(0)
+		}
+		log.Println(chalk.Green.Color(fmt.Sprintf("导入%d个origin的localStorage, %d个origin的sessionStorage", len(state.GetLocalStorage()), len(state.GetSessionStorage()))))
+	}
+}
+
+func handleExit(t *crawlergo.CrawlerTask, signalChan chan os.Signal, done chan struct{}) {
+	select {
+	case <-signalChan:
+	case <-done:
+		return
+	}
 	t.Pool.Tune(1)
 	t.Pool.Release()
 	t.Browser.Close()
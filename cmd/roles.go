@@ -0,0 +1,129 @@
+package main
+
+import (
+	"Venom-Crawler/internal/utils"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/katana/output"
+	"Venom-Crawler/pkg/katana/types"
+	"Venom-Crawler/pkg/roles"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ttacon/chalk"
+	"log"
+	"os"
+	"sort"
+)
+
+/*
+*
+多身份爬行：每个身份分别执行katana和crawlergo，比较各身份发现的端点，
+并以低权限身份重放高权限独有的端点，输出越权差异报告。
+默认只重放GET/HEAD端点，replayUnsafe为true时重放所有端点
+*/
+func runRoles(path string, options *types.Options, replayUnsafe bool) {
+	identities, err := roles.LoadIdentities(path)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 多身份配置解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	baseHeaders := map[string]string{}
+	if taskConfig.ExtraHeadersString != "" {
+		if err := json.Unmarshal([]byte(taskConfig.ExtraHeadersString), &baseHeaders); err != nil {
+			log.Println(chalk.Red.Color("error: 自定义参数头不能被序列化"))
+		}
+	}
+
+	collector := roles.NewCollector()
+	baseTaskConfig := taskConfig
+	for _, identity := range identities {
+		log.Println(chalk.Green.Color("开始以身份[" + identity.Name + "]爬行"))
+		headers := mergeHeaders(baseHeaders, identity.Headers)
+		name := identity.Name
+
+		// katana
+		katanaFile := "katana-result-" + identity.FileName() + ".txt"
+		crawlergoFile := "crawlergo-result-" + identity.FileName() + ".txt"
+		existCheck(katanaFile)
+		existCheck(crawlergoFile)
+		katanaOptions := *options
+		katanaOptions.OutputFile = katanaFile
+		katanaOptions.CustomHeaders = nil
+		for key, value := range identity.Headers {
+			katanaOptions.CustomHeaders = append(katanaOptions.CustomHeaders, key+": "+value)
+		}
+		katanaOptions.OnResult = func(result output.Result) {
+			if result.Error != "" || result.Request == nil {
+				return
+			}
+			collector.Add(name, roles.Endpoint{Method: result.Request.Method, URL: result.Request.URL, Body: result.Request.Body})
+		}
+		katanaRun(&katanaOptions)
+
+		// crawlergo
+		taskConfig = baseTaskConfig
+		taskConfig.ExtraHeaders = nil
+		headersString, _ := json.Marshal(headers)
+		taskConfig.ExtraHeadersString = string(headersString)
+		taskConfig.URLList = nil
+		if urls := utils.GetUrlListFromTxt(katanaFile); len(urls) > 0 {
+			taskConfig.URLList = dealUrlScope(urls)
+		} else {
+			taskConfig.URLList = options.Scope
+		}
+		result := runCrawlergoTask()
+		if result == nil {
+			continue
+		}
+		for _, req := range result.ReqList {
+			collector.Add(name, roles.Endpoint{Method: req.Method, URL: req.URL.String(), Body: req.PostData})
+			utils.AppendToFile(crawlergoFile, req.URL.String())
+		}
+	}
+	taskConfig = baseTaskConfig
+
+	// 以低权限身份重放高权限独有的端点
+	replay := func(identity roles.Identity, endpoint roles.Endpoint) (int, error) {
+		// 非GET/HEAD请求会修改目标数据，默认不重放
+		if !replayUnsafe && !endpoint.Safe() {
+			return 0, errors.New("skip replaying " + endpoint.Method + " " + endpoint.URL)
+		}
+		resp, err := requests.Request(endpoint.Method, endpoint.URL, mergeHeaders(baseHeaders, identity.Headers), []byte(endpoint.Body),
+			&requests.ReqOptions{Timeout: 5, AllowRedirect: false, Proxy: options.Proxy})
+		if err != nil {
+			return 0, err
+		}
+		return resp.StatusCode, nil
+	}
+	report := roles.BuildReport(identities, collector, replay)
+	if err := report.WriteJSON("roles-report.json"); err != nil {
+		log.Println(chalk.Red.Color("error: 越权差异报告写入失败, " + err.Error()))
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("高权限独有端点%d个, 低权限可访问%d个, 详见roles-report.json", len(report.Exclusive), len(report.Exposures))))
+	for _, exposure := range report.Exposures {
+		log.Println(chalk.Yellow.Color(fmt.Sprintf("疑似越权: [%s] %s %s (属于%s, 状态码%d)", exposure.Identity, exposure.Method, exposure.URL, exposure.Role, exposure.StatusCode)))
+	}
+
+	var finalResult []string
+	for _, endpoints := range report.Endpoints {
+		for _, endpoint := range endpoints {
+			finalResult = append(finalResult, endpoint.URL)
+		}
+	}
+	finalResult = utils.UniqueUrls(finalResult)
+	sort.Strings(finalResult)
+	for _, _url := range finalResult {
+		utils.AppendToFile("result-all.txt", _url)
+	}
+}
+
+func mergeHeaders(base map[string]string, extra map[string]string) map[string]string {
+	headers := make(map[string]string, len(base)+len(extra))
+	for key, value := range base {
+		headers[key] = value
+	}
+	for key, value := range extra {
+		headers[key] = value
+	}
+	return headers
+}
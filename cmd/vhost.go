@@ -0,0 +1,185 @@
+package main
+
+import (
+	"Venom-Crawler/internal/utils"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/katana/output"
+	"Venom-Crawler/pkg/katana/types"
+	"Venom-Crawler/pkg/resolve"
+	"Venom-Crawler/pkg/vhost"
+	"encoding/json"
+	"fmt"
+	"github.com/ttacon/chalk"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+*
+虚拟主机探测：以不同的主机名请求同一个IP，与基准响应比较状态码、大小和DOM相似度，
+对每个内容不同的虚拟主机分别执行katana和crawlergo，结果按虚拟主机分组输出
+主机名通过自定义解析指向目标IP，HTTPS请求的SNI和Host头都使用该主机名
+*/
+func runVhosts(target string, candidates []string, options *types.Options) {
+	targetUrl, err := url.Parse(target)
+	if err != nil || targetUrl.Hostname() == "" {
+		log.Println(chalk.Red.Color("error: " + target + "不能被正常解析"))
+		os.Exit(0)
+	}
+	port := targetUrl.Port()
+	if port == "" {
+		port = "80"
+		if targetUrl.Scheme == "https" {
+			port = "443"
+		}
+	}
+	ip, err := lookupTarget(targetUrl.Hostname(), port, options.Resolve)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 目标解析失败, " + err.Error()))
+		os.Exit(0)
+	}
+	baseHeaders := map[string]string{}
+	if taskConfig.ExtraHeadersString != "" {
+		if err := json.Unmarshal([]byte(taskConfig.ExtraHeadersString), &baseHeaders); err != nil {
+			log.Println(chalk.Red.Color("error: 自定义参数头不能被序列化"))
+		}
+	}
+	// 非默认端口时Host头需要带上端口
+	hostHeader := func(host string) string {
+		if targetUrl.Port() != "" {
+			return net.JoinHostPort(host, port)
+		}
+		return host
+	}
+	ipUrl := *targetUrl
+	ipUrl.Host = net.JoinHostPort(ip, port)
+	// 以主机名访问，通过自定义解析指向目标IP，探测是逐个进行的
+	baseDial := requests.DialContext
+	probe := func(host string) (vhost.Fingerprint, error) {
+		requests.DialContext = options.Resolve.With(resolve.Rule{Host: host, Port: port, IP: ip}).Dial(nil)
+		defer func() { requests.DialContext = baseDial }()
+		probeUrl := *targetUrl
+		probeUrl.Host = hostHeader(host)
+		headers := mergeHeaders(baseHeaders, map[string]string{"Range": "bytes=0-"})
+		resp, err := requests.Request("GET", probeUrl.String(), headers, nil,
+			&requests.ReqOptions{Timeout: 10, AllowRedirect: false, Proxy: options.Proxy})
+		if err != nil {
+			return vhost.Fingerprint{}, err
+		}
+		// 跳转地址中的主机名会随Host头变化，比较前去掉
+		location := strings.Replace(resp.Header.Get("Location"), hostHeader(host), "", -1)
+		return vhost.NewFingerprint(resp.StatusCode, location, resp.Text), nil
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("开始探测虚拟主机, 目标%s, 候选主机名%d个", ipUrl.String(), len(candidates))))
+	report, err := vhost.Sweep(ip, candidates, probe, vhost.DefaultThreshold)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 基准响应获取失败, " + err.Error()))
+		os.Exit(0)
+	}
+	report.Target = ipUrl.String()
+	for _, host := range report.VHosts {
+		log.Println(chalk.Green.Color(fmt.Sprintf("发现虚拟主机: %s (状态码%d, 大小%d, 别名%s)", host.Host, host.Fingerprint.StatusCode, host.Fingerprint.Size, strings.Join(host.Aliases, ","))))
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("内容与默认站点相同%d个, 探测失败%d个", len(report.Default), len(report.Failed))))
+
+	baseTaskConfig := taskConfig
+	var finalResult []string
+	for _, host := range report.VHosts {
+		name := host.Host
+		log.Println(chalk.Green.Color("开始爬行虚拟主机[" + name + "]"))
+		katanaFile := "katana-result-" + name + ".txt"
+		crawlergoFile := "crawlergo-result-" + name + ".txt"
+		existCheck(katanaFile)
+		existCheck(crawlergoFile)
+
+		// katana和crawlergo都以虚拟主机名访问，通过自定义解析指向目标IP
+		vhostUrl := *targetUrl
+		vhostUrl.Host = hostHeader(name)
+		vhostResolve := options.Resolve.With(resolve.Rule{Host: name, Port: port, IP: ip})
+		katanaOptions := *options
+		katanaOptions.URLs = []string{vhostUrl.String()}
+		katanaOptions.Scope = []string{vhostUrl.Scheme + "://" + vhostUrl.Host}
+		katanaOptions.OutputFile = katanaFile
+		katanaOptions.Resolve = vhostResolve
+		var urls []string
+		katanaOptions.OnResult = func(result output.Result) {
+			if result.Error != "" || result.Request == nil {
+				return
+			}
+			urls = append(urls, result.Request.URL)
+		}
+		katanaRun(&katanaOptions)
+
+		taskConfig = baseTaskConfig
+		taskConfig.Resolve = vhostResolve
+		taskConfig.URL = ""
+		taskConfig.URLList = append([]string{vhostUrl.String()}, utils.UniqueUrls(urls)...)
+		requests.DialContext = vhostResolve.Dial(nil)
+		result := runCrawlergoTask()
+		requests.DialContext = baseDial
+		if result != nil {
+			for _, req := range result.ReqList {
+				urls = append(urls, req.URL.String())
+				utils.AppendToFile(crawlergoFile, req.URL.String())
+			}
+		}
+		host.URLs = utils.UniqueUrls(urls)
+		sort.Strings(host.URLs)
+		finalResult = append(finalResult, host.URLs...)
+	}
+	taskConfig = baseTaskConfig
+
+	if err := report.WriteJSON("vhost-report.json"); err != nil {
+		log.Println(chalk.Red.Color("error: 虚拟主机报告写入失败, " + err.Error()))
+	}
+	log.Println(chalk.Green.Color(fmt.Sprintf("共发现虚拟主机%d个, 详见vhost-report.json", len(report.VHosts))))
+	for _, _url := range utils.UniqueUrls(finalResult) {
+		utils.AppendToFile("result-all.txt", _url)
+	}
+}
+
+/*
+*
+获取目标的IP，优先使用自定义解析
+*/
+func lookupTarget(host string, port string, resolveMap *resolve.Map) (string, error) {
+	if ip, ok := resolveMap.Lookup(host, port); ok {
+		return ip, nil
+	}
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return "", err
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("%s没有解析记录", host)
+	}
+	return ips[0].String(), nil
+}
+
+/*
+*
+读取候选主机名，参数为txt文件路径或用,分割的主机名
+*/
+func loadVhostCandidates(value string) []string {
+	var candidates []string
+	if _, err := os.Stat(value); err == nil {
+		candidates = utils.GetUrlListFromTxt(value)
+	} else {
+		candidates = strings.Split(value, ",")
+	}
+	var hosts []string
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate != "" {
+			hosts = append(hosts, candidate)
+		}
+	}
+	return hosts
+}
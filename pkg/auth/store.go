@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ProxyPattern is the reserved pattern used for proxy (407) credentials
+const ProxyPattern = "proxy"
+
+// Credential is a username/password pair used to answer an auth challenge
+type Credential struct {
+	Username string
+	Password string
+	// Cancel cancels the challenge instead of answering it
+	Cancel bool
+}
+
+type entry struct {
+	pattern    string
+	credential Credential
+}
+
+// Store maps host patterns to credentials and records which
+// hosts have asked for authentication during the crawl.
+type Store struct {
+	entries    []entry
+	proxy      *Credential
+	challenged map[string]string
+	lock       sync.RWMutex
+}
+
+// NewStore returns a new empty credential store
+func NewStore() *Store {
+	return &Store{challenged: make(map[string]string)}
+}
+
+// ParseStore parses a comma separated list of credential rules.
+//
+// Each rule is `pattern=username:password` or `pattern=cancel`, where
+// pattern is a host glob such as `*.example.com` or `proxy` for the proxy.
+func ParseStore(value string) (*Store, error) {
+	store := NewStore()
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if err := store.AddRule(item); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// AddRule adds a single `pattern=username:password` or `pattern=cancel` rule
+func (s *Store) AddRule(rule string) error {
+	parts := strings.SplitN(rule, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return errors.New("invalid auth rule: " + rule)
+	}
+	pattern := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+	if strings.EqualFold(value, "cancel") {
+		s.Add(pattern, Credential{Cancel: true})
+		return nil
+	}
+	userPass := strings.SplitN(value, ":", 2)
+	if len(userPass) != 2 {
+		return errors.New("invalid auth credential: " + rule)
+	}
+	s.Add(pattern, Credential{Username: userPass[0], Password: userPass[1]})
+	return nil
+}
+
+// Add adds a credential for a host pattern
+func (s *Store) Add(pattern string, credential Credential) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if strings.EqualFold(pattern, ProxyPattern) {
+		s.proxy = &credential
+		return
+	}
+	s.entries = append(s.entries, entry{pattern: strings.ToLower(pattern), credential: credential})
+}
+
+// Lookup returns the credential configured for a host (with or without port)
+func (s *Store) Lookup(host string) (Credential, bool) {
+	if s == nil {
+		return Credential{}, false
+	}
+	host = strings.ToLower(host)
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, e := range s.entries {
+		if matchHost(e.pattern, host) || matchHost(e.pattern, hostname) {
+			return e.credential, true
+		}
+	}
+	return Credential{}, false
+}
+
+// Proxy returns the credential configured for proxy authentication
+func (s *Store) Proxy() (Credential, bool) {
+	if s == nil {
+		return Credential{}, false
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.proxy == nil {
+		return Credential{}, false
+	}
+	return *s.proxy, true
+}
+
+// Record records an auth challenge from a host with its scheme (Basic, Digest...)
+func (s *Store) Record(host, scheme string) {
+	if s == nil || host == "" {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.challenged[strings.ToLower(host)] = scheme
+}
+
+// Challenged returns the sorted list of hosts that asked for authentication
+func (s *Store) Challenged() []string {
+	if s == nil {
+		return nil
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	hosts := make([]string, 0, len(s.challenged))
+	for host, scheme := range s.challenged {
+		if scheme != "" {
+			host = host + " (" + scheme + ")"
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func matchHost(pattern, host string) bool {
+	if pattern == "*" || pattern == host {
+		return true
+	}
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Lookup(t *testing.T) {
+	store, err := ParseStore("*.example.com=admin:123456,intranet.local=cancel,proxy=user:pass")
+	assert.Nil(t, err)
+
+	credential, ok := store.Lookup("www.example.com:8443")
+	assert.True(t, ok)
+	assert.Equal(t, "admin", credential.Username)
+	assert.Equal(t, "123456", credential.Password)
+
+	credential, ok = store.Lookup("intranet.local")
+	assert.True(t, ok)
+	assert.True(t, credential.Cancel)
+
+	_, ok = store.Lookup("example.org")
+	assert.False(t, ok)
+
+	credential, ok = store.Proxy()
+	assert.True(t, ok)
+	assert.Equal(t, "user", credential.Username)
+
+	_, err = ParseStore("example.com=admin")
+	assert.NotNil(t, err)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := ParseChallenge(`Digest realm="test, realm", qop="auth", nonce="abc"`)
+	assert.Equal(t, "Digest", scheme)
+	assert.Equal(t, "test, realm", params["realm"])
+	assert.Equal(t, "abc", params["nonce"])
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transport answers 401 challenges with credentials from the store and 407
+// challenges with the proxy credential. Basic and Digest (MD5, qop=auth)
+// schemes are supported.
+type Transport struct {
+	Base  http.RoundTripper
+	Store *Store
+}
+
+// Challenge is an auth scheme with its parameters
+type Challenge struct {
+	Scheme string
+	Params map[string]string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	var challengeHeader, authorizationHeader, uri string
+	var credential Credential
+	var ok bool
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		challengeHeader, authorizationHeader, uri = "WWW-Authenticate", "Authorization", req.URL.RequestURI()
+	case http.StatusProxyAuthRequired:
+		// a proxied plain HTTP request line holds the absolute URL
+		challengeHeader, authorizationHeader, uri = "Proxy-Authenticate", "Proxy-Authorization", req.URL.String()
+	default:
+		return resp, nil
+	}
+	challenges := ParseChallenges(strings.Join(resp.Header.Values(challengeHeader), ", "))
+	challenge, supported := selectChallenge(challenges)
+	if !supported && len(challenges) > 0 {
+		challenge = challenges[0]
+	}
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		t.Store.Record(ProxyPattern, challenge.Scheme)
+		credential, ok = t.Store.Proxy()
+	} else {
+		t.Store.Record(req.URL.Host, challenge.Scheme)
+		credential, ok = t.Store.Lookup(req.URL.Host)
+	}
+	if !supported || !ok || credential.Cancel || req.Header.Get(authorizationHeader) != "" {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	if strings.EqualFold(challenge.Scheme, "digest") {
+		retry.Header.Set(authorizationHeader, DigestAuthorization(challenge.Params, req.Method, uri, credential))
+	} else {
+		retry.Header.Set(authorizationHeader, "Basic "+base64.StdEncoding.EncodeToString([]byte(credential.Username+":"+credential.Password)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.Base.RoundTrip(retry)
+}
+
+// selectChallenge returns the strongest supported challenge, Digest is
+// preferred to Basic
+func selectChallenge(challenges []Challenge) (Challenge, bool) {
+	var basic *Challenge
+	for i, challenge := range challenges {
+		switch strings.ToLower(challenge.Scheme) {
+		case "digest":
+			return challenge, true
+		case "basic":
+			if basic == nil {
+				basic = &challenges[i]
+			}
+		}
+	}
+	if basic != nil {
+		return *basic, true
+	}
+	return Challenge{}, false
+}
+
+// ParseChallenge parses a WWW-Authenticate / Proxy-Authenticate header
+// returning the scheme and the parameters of its first challenge.
+func ParseChallenge(header string) (string, map[string]string) {
+	challenges := ParseChallenges(header)
+	if len(challenges) == 0 {
+		return "", make(map[string]string)
+	}
+	return challenges[0].Scheme, challenges[0].Params
+}
+
+// ParseChallenges parses a WWW-Authenticate / Proxy-Authenticate header
+// holding one or more comma separated challenges such as
+// `Negotiate, Basic realm="x"`.
+func ParseChallenges(header string) []Challenge {
+	var challenges []Challenge
+	for _, item := range splitParams(header) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		// a challenge starts with its scheme, which is followed by a space
+		// or ends the item, the other items are parameters of the last one
+		parts := strings.SplitN(item, " ", 2)
+		rest := ""
+		if len(parts) == 2 {
+			rest = strings.TrimSpace(parts[1])
+		}
+		if !strings.Contains(parts[0], "=") && !strings.HasPrefix(rest, "=") {
+			challenges = append(challenges, Challenge{Scheme: parts[0], Params: make(map[string]string)})
+			item = rest
+		}
+		if len(challenges) == 0 {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			continue
+		}
+		challenges[len(challenges)-1].Params[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+	}
+	return challenges
+}
+
+// splitParams splits challenge params on commas outside quotes
+func splitParams(value string) []string {
+	var items []string
+	var builder strings.Builder
+	quoted := false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			builder.WriteRune(r)
+		case r == ',' && !quoted:
+			items = append(items, builder.String())
+			builder.Reset()
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	if builder.Len() > 0 {
+		items = append(items, builder.String())
+	}
+	return items
+}
+
+// DigestAuthorization builds a Digest Authorization header for a challenge
+func DigestAuthorization(params map[string]string, method, uri string, credential Credential) string {
+	realm := params["realm"]
+	nonce := params["nonce"]
+	ha1 := md5Hex(credential.Username + ":" + realm + ":" + credential.Password)
+	ha2 := md5Hex(method + ":" + uri)
+
+	header := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, credential.Username, realm, nonce, uri)
+	if qop := params["qop"]; qop != "" {
+		cnonce := randomHex(8)
+		nc := "00000001"
+		response := md5Hex(strings.Join([]string{ha1, nonce, nc, cnonce, "auth", ha2}, ":"))
+		header += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, cnonce, response)
+	} else {
+		header += fmt.Sprintf(`, response="%s"`, md5Hex(ha1+":"+nonce+":"+ha2))
+	}
+	if opaque := params["opaque"]; opaque != "" {
+		header += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+	return header + ", algorithm=MD5"
+}
+
+func md5Hex(value string) string {
+	sum := md5.Sum([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
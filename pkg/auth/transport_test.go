@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenges(t *testing.T) {
+	challenges := ParseChallenges(`Negotiate, Basic realm="x", Digest realm="a, b", qop="auth", nonce="abc"`)
+	assert.Equal(t, []Challenge{
+		{Scheme: "Negotiate", Params: map[string]string{}},
+		{Scheme: "Basic", Params: map[string]string{"realm": "x"}},
+		{Scheme: "Digest", Params: map[string]string{"realm": "a, b", "qop": "auth", "nonce": "abc"}},
+	}, challenges)
+
+	scheme, params := ParseChallenge(`Negotiate, Basic realm="x"`)
+	assert.Equal(t, "Negotiate", scheme)
+	assert.Empty(t, params)
+	assert.Empty(t, ParseChallenges(""))
+}
+
+// roundTripFunc answers the requests of a Transport
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// challenger answers the requests without headerName with status and the
+// challenges, and with 200 otherwise
+func challenger(status int, challengeHeader, headerName string, challenges []string, received *[]string) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) *http.Response {
+		value := req.Header.Get(headerName)
+		*received = append(*received, value)
+		resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(""))}
+		if value == "" {
+			resp.StatusCode = status
+			for _, challenge := range challenges {
+				resp.Header.Add(challengeHeader, challenge)
+			}
+		}
+		return resp
+	})
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	store, err := ParseStore("example.com=admin:secret,proxy=user:pass")
+	assert.Nil(t, err)
+
+	var received []string
+	transport := &Transport{Base: challenger(http.StatusUnauthorized, "WWW-Authenticate", "Authorization", []string{`Negotiate, Basic realm="x"`}, &received), Store: store}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/admin", nil)
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"", "Basic YWRtaW46c2VjcmV0"}, received, "should answer the Basic challenge after Negotiate")
+
+	received = nil
+	transport = &Transport{Base: challenger(http.StatusUnauthorized, "WWW-Authenticate", "Authorization", []string{`Basic realm="x"`, `Digest realm="x", nonce="abc"`}, &received), Store: store}
+	resp, err = transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(received[1], `Digest username="admin"`), "should prefer Digest")
+
+	received = nil
+	transport = &Transport{Base: challenger(http.StatusProxyAuthRequired, "Proxy-Authenticate", "Proxy-Authorization", []string{`Basic realm="proxy"`}, &received), Store: store}
+	resp, err = transport.RoundTrip(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"", "Basic dXNlcjpwYXNz"}, received, "should answer the proxy with the proxy credential")
+
+	received = nil
+	other, _ := http.NewRequest(http.MethodGet, "http://other.com/", nil)
+	transport = &Transport{Base: challenger(http.StatusUnauthorized, "WWW-Authenticate", "Authorization", []string{`Basic realm="x"`}, &received), Store: store}
+	resp, err = transport.RoundTrip(other)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Len(t, received, 1)
+	assert.Equal(t, []string{"example.com (Digest)", "other.com (Basic)", "proxy (Basic)"}, store.Challenged())
+}
@@ -0,0 +1,146 @@
+package block
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultTrackerDomains is the bundled list of analytics, ads, fonts,
+// map tiles and social widget domains
+var DefaultTrackerDomains = []string{
+	// analytics
+	"google-analytics.com", "googletagmanager.com", "analytics.google.com", "hotjar.com", "mixpanel.com",
+	"segment.io", "segment.com", "amplitude.com", "newrelic.com", "nr-data.net", "clarity.ms", "fullstory.com",
+	"hm.baidu.com", "cnzz.com", "umeng.com", "tajs.qq.com", "growingio.com", "sensorsdata.cn", "51.la",
+	// ads
+	"doubleclick.net", "googlesyndication.com", "googleadservices.com", "adservice.google.com",
+	"amazon-adsystem.com", "criteo.com", "taboola.com", "outbrain.com", "pos.baidu.com",
+	// fonts
+	"fonts.googleapis.com", "fonts.gstatic.com", "use.typekit.net", "fonts.loli.net",
+	// map tiles
+	"tile.openstreetmap.org", "api.mapbox.com", "maps.googleapis.com", "maps.gstatic.com", "api.map.baidu.com", "webapi.amap.com",
+	// social widgets
+	"connect.facebook.net", "platform.twitter.com", "platform.linkedin.com", "addthis.com", "sharethis.com", "disqus.com",
+}
+
+// BlockableTypes are the CDP resource types a policy can block
+var BlockableTypes = []string{"Image", "Media", "Font", "Stylesheet"}
+
+// Policy decides which browser requests are blocked and counts them
+type Policy struct {
+	domains []string
+	types   map[string]string
+	regexes []*regexp.Regexp
+	counts  map[string]int64
+	lock    sync.Mutex
+}
+
+// NewPolicy returns a blocking policy. domains are blocked with their
+// subdomains, types are CDP resource types and regexes match the full URL.
+func NewPolicy(domains []string, types []string, regexes []string) (*Policy, error) {
+	policy := &Policy{types: make(map[string]string), counts: make(map[string]int64)}
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain != "" {
+			policy.domains = append(policy.domains, domain)
+		}
+	}
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		valid := false
+		for _, blockable := range BlockableTypes {
+			if strings.EqualFold(t, blockable) {
+				policy.types[strings.ToLower(t)] = blockable
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, errors.New("resource type can not be blocked: " + t)
+		}
+	}
+	for _, expr := range regexes {
+		if expr == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		policy.regexes = append(policy.regexes, compiled)
+	}
+	return policy, nil
+}
+
+// Empty returns true if the policy blocks nothing
+func (p *Policy) Empty() bool {
+	return p == nil || (len(p.domains) == 0 && len(p.types) == 0 && len(p.regexes) == 0)
+}
+
+// Match returns the reason a request would be blocked, empty if it is allowed
+func (p *Policy) Match(rawURL, resourceType string) string {
+	if p.Empty() {
+		return ""
+	}
+	if blockable, ok := p.types[strings.ToLower(resourceType)]; ok {
+		return "type:" + blockable
+	}
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host := strings.ToLower(parsed.Hostname())
+		for _, domain := range p.domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return "domain:" + domain
+			}
+		}
+	}
+	for _, regex := range p.regexes {
+		if regex.MatchString(rawURL) {
+			return "regex:" + regex.String()
+		}
+	}
+	return ""
+}
+
+// Block returns true if the request must be blocked and counts it
+func (p *Policy) Block(rawURL, resourceType string) bool {
+	reason := p.Match(rawURL, resourceType)
+	if reason == "" {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.counts[reason]++
+	return true
+}
+
+// Count is the number of requests blocked for a reason
+type Count struct {
+	Reason string
+	Count  int64
+}
+
+// Counts returns the blocked request counts, most blocked first
+func (p *Policy) Counts() []Count {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	counts := make([]Count, 0, len(p.counts))
+	for reason, count := range p.counts {
+		counts = append(counts, Count{Reason: reason, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Reason < counts[j].Reason
+	})
+	return counts
+}
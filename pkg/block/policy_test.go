@@ -0,0 +1,64 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy([]string{" .Example-Ads.com. ", ""}, []string{"image", " "}, []string{"", `\.mp4$`})
+	assert.Nil(t, err)
+	assert.False(t, policy.Empty())
+	assert.Equal(t, []string{"example-ads.com"}, policy.domains)
+	assert.Len(t, policy.regexes, 1, "should skip the empty regex")
+
+	empty, err := NewPolicy(nil, nil, []string{""})
+	assert.Nil(t, err)
+	assert.True(t, empty.Empty(), "an empty regex should not block every request")
+	assert.Equal(t, "", empty.Match("https://example.com/", "Document"))
+
+	_, err = NewPolicy(nil, []string{"Script"}, nil)
+	assert.NotNil(t, err, "scripts can not be blocked")
+	_, err = NewPolicy(nil, nil, []string{"("})
+	assert.NotNil(t, err)
+
+	var disabled *Policy
+	assert.True(t, disabled.Empty())
+	assert.False(t, disabled.Block("https://www.google-analytics.com/collect", "XHR"))
+	assert.Nil(t, disabled.Counts())
+}
+
+func TestMatch(t *testing.T) {
+	policy, err := NewPolicy(DefaultTrackerDomains, []string{"Font", "image"}, []string{`/static/video/`})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		url          string
+		resourceType string
+		reason       string
+	}{
+		{"https://www.google-analytics.com/collect?v=1", "XHR", "domain:google-analytics.com"},
+		{"https://GOOGLE-ANALYTICS.COM/analytics.js", "Script", "domain:google-analytics.com"},
+		{"https://fonts.gstatic.com/s/roboto.woff2", "Font", "type:Font"},
+		{"https://example.com/logo.png", "Image", "type:Image"},
+		{"https://example.com/static/video/intro.mp4", "Media", "regex:/static/video/"},
+		{"https://notgoogle-analytics.com/collect", "XHR", ""},
+		{"https://example.com/api/users", "XHR", ""},
+		{"https://example.com/app.css", "Stylesheet", ""},
+		{"://bad url", "Other", ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.reason, policy.Match(test.url, test.resourceType), test.url)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	policy, err := NewPolicy([]string{"doubleclick.net"}, []string{"Image"}, nil)
+	assert.Nil(t, err)
+	assert.True(t, policy.Block("https://ad.doubleclick.net/pixel", "XHR"))
+	assert.True(t, policy.Block("https://stats.doubleclick.net/pixel", "Script"))
+	assert.True(t, policy.Block("https://example.com/a.png", "Image"))
+	assert.False(t, policy.Block("https://example.com/", "Document"))
+	assert.Equal(t, []Count{{"domain:doubleclick.net", 2}, {"type:Image", 1}}, policy.Counts())
+}
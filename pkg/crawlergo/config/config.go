@@ -18,9 +18,13 @@ const (
 	DomContentLoadedTimeout = 5 * time.Second
 	EventTriggerInterval    = 100 * time.Millisecond // 单位毫秒
 	BeforeExitDelay         = 1 * time.Second
+	NetworkQuietTime        = 500 * time.Millisecond
+	NetworkIdleMaxWait      = 5 * time.Second
+	MaxScrollTimes          = 10
 	DefaultEventTriggerMode = EventTriggerAsync
 	MaxCrawlCount           = 200
 	MaxRunTime              = 60 * 60
+	LoginTimeout            = 60 * time.Second
 )
 
 // 请求方法
@@ -28,6 +32,7 @@ const (
 	GET     = "GET"
 	POST    = "POST"
 	PUT     = "PUT"
+	PATCH   = "PATCH"
 	DELETE  = "DELETE"
 	HEAD    = "HEAD"
 	OPTIONS = "OPTIONS"
@@ -53,8 +58,14 @@ const (
 	FromXHR         = "XHR"        //ajax异步请求
 	FromDOM         = "DOM"        //dom解析出来的请求
 	FromJSFile      = "JavaScript" //JS脚本中解析
+	FromSourceMap   = "SourceMap"  //JS源码映射的原始源码中解析
+	FromJSChunk     = "JSChunk"    //按需加载的JS分块中解析
 	FromFuzz        = "PathFuzz"   //初始path fuzz
 	FromRobots      = "robots.txt" //robots.txt
+	FromWellKnown   = "WellKnown"  //站点地图、security.txt等常见文件
+	FromOpenAPI     = "OpenAPI"    //OpenAPI/Swagger文档
+	FromGraphQL     = "GraphQL"    //GraphQL操作
+	FromWSDL        = "WSDL"       //WSDL/WADL服务描述
 	FromComment     = "Comment"    //页面中的注释
 	FromWebSocket   = "WebSocket"
 	FromEventSource = "EventSource"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 )
 
@@ -34,6 +35,8 @@ func (tab *Tab) AfterLoadedRun() {
 
 	go tab.formSubmit()
 	tab.formSubmitWG.Wait()
+	// 等待表单提交发出的请求完成
+	tab.WaitNetworkIdle(0)
 
 	if tab.config.EventTriggerMode == config.EventTriggerAsync {
 		go tab.triggerJavascriptProtocol()
@@ -42,14 +45,17 @@ func (tab *Tab) AfterLoadedRun() {
 		tab.loadedWG.Wait()
 	} else if tab.config.EventTriggerMode == config.EventTriggerSync {
 		tab.triggerInlineEvents()
-		time.Sleep(tab.config.EventTriggerInterval)
+		tab.WaitNetworkIdle(tab.config.EventTriggerInterval)
 		tab.triggerDom2Events()
-		time.Sleep(tab.config.EventTriggerInterval)
+		tab.WaitNetworkIdle(tab.config.EventTriggerInterval)
 		tab.triggerJavascriptProtocol()
 	}
 
-	// 事件触发之后 需要等待一点时间让浏览器成功发出ajax请求 更新DOM
-	time.Sleep(tab.config.BeforeExitDelay)
+	// 事件触发之后 需要等待浏览器发出的ajax请求完成 更新DOM
+	tab.WaitNetworkIdle(tab.config.BeforeExitDelay)
+
+	// 滚动页面 加载无限滚动和懒加载的内容
+	tab.scrollPage()
 
 	go tab.RemoveDOMListener()
 	tab.removeLis.Wait()
@@ -189,6 +195,46 @@ func (tab *Tab) triggerJavascriptProtocol() {
 		tab.config.EventTriggerInterval.Seconds()*1000))
 }
 
+/*
+*
+分轮滚动页面和页面中可滚动的容器，触发懒加载的图片和iframe，
+直到DOM节点数不再增长或达到最大轮数，每轮之后重新收集链接
+*/
+func (tab *Tab) scrollPage() {
+	if tab.config.MaxScrollTimes <= 0 {
+		return
+	}
+	ctx := tab.GetExecutor()
+	lastCount := 0
+	for i := 0; i < tab.config.MaxScrollTimes; i++ {
+		var count int
+		tCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+		err := chromedp.Evaluate(fmt.Sprintf(js.ScrollStepJS, tab.config.EventTriggerInterval.Seconds()*1000), &count,
+			func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(tCtx)
+		cancel()
+		if err != nil {
+			return
+		}
+		tab.WaitNetworkIdle(tab.config.EventTriggerInterval)
+
+		// 虚拟列表会回收滚出视野的节点，每轮都收集一次链接
+		tab.collectLinkWG.Add(3)
+		tab.collectLinks()
+		tab.collectLinkWG.Wait()
+
+		// 重新获取节点数，包括等待期间异步加载的内容
+		tCtx, cancel = context.WithTimeout(ctx, time.Second*2)
+		_ = chromedp.Evaluate(`document.getElementsByTagName("*").length`, &count).Do(tCtx)
+		cancel()
+		if count <= lastCount {
+			return
+		}
+		lastCount = count
+	}
+}
+
 /*
 *
 移除DOM节点变化监听
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"Venom-Crawler/pkg/session"
 	"context"
 	"github.com/ttacon/chalk"
 	"log"
@@ -17,10 +18,12 @@ type Browser struct {
 	tabs         []*context.Context
 	tabCancels   []context.CancelFunc
 	ExtraHeaders map[string]interface{}
+	Session      *session.State // 登录后的会话状态，应用到每个标签页
+	chunks       chunkSet       // 已获取的JS分块，所有标签页共用
 	lock         sync.Mutex
 }
 
-func InitBrowser(chromiumPath string, extraHeaders map[string]interface{}, proxy string, noHeadless bool) *Browser {
+func InitBrowser(chromiumPath string, extraHeaders map[string]interface{}, proxy string, noHeadless bool, hostResolverRules string) *Browser {
 	var bro Browser
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 
@@ -56,6 +59,11 @@ func InitBrowser(chromiumPath string, extraHeaders map[string]interface{}, proxy
 		opts = append(opts, chromedp.ProxyServer(proxy))
 	}
 
+	// 自定义域名解析，流量发往指定IP，SNI和Cookie仍使用原域名
+	if hostResolverRules != "" {
+		opts = append(opts, chromedp.Flag("host-resolver-rules", hostResolverRules))
+	}
+
 	if len(chromiumPath) > 0 {
 
 		// 指定执行路径
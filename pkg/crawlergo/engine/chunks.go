@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/jsextract"
+	"github.com/ttacon/chalk"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// 同时获取JS分块的个数
+const chunkFetchConcurrency = 5
+
+// 从一个JS文件出发递归获取分块的最大层数
+const chunkMaxDepth = 3
+
+// 从一个JS文件出发获取分块的最大个数
+const chunkMaxCount = 50
+
+/*
+*
+已获取的JS分块，避免重复请求
+*/
+type chunkSet struct {
+	lock sync.Mutex
+	seen map[string]struct{}
+}
+
+func (c *chunkSet) claim(url string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[string]struct{})
+	}
+	if _, ok := c.seen[url]; ok {
+		return false
+	}
+	c.seen[url] = struct{}{}
+	return true
+}
+
+/*
+*
+分析webpack/vite打包的JS，计算按需加载的分块URL并直接获取，
+从分块中提取端点。分块中引用的分块同样处理，最多chunkMaxDepth层、
+chunkMaxCount个。只处理和页面同一主机的JS和分块
+*/
+func (tab *Tab) HandleChunks(scriptURL string, body string) {
+	if !tab.config.ScrapeJSChunks || !tab.inScope(scriptURL) {
+		return
+	}
+	chunks, err := jsextract.Chunks(scriptURL, body)
+	if err != nil {
+		return
+	}
+	count := 0
+	for depth := 0; depth < chunkMaxDepth && len(chunks) > 0; depth++ {
+		var next []string
+		var lock sync.Mutex
+		var wg sync.WaitGroup
+		limit := make(chan struct{}, chunkFetchConcurrency)
+		for _, chunkURL := range chunks {
+			if count >= chunkMaxCount {
+				break
+			}
+			if !tab.inScope(chunkURL) || !tab.chunks.claim(chunkURL) {
+				continue
+			}
+			count++
+			wg.Add(1)
+			limit <- struct{}{}
+			go func(chunkURL string) {
+				defer func() {
+					<-limit
+					wg.Done()
+				}()
+				found := tab.parseChunk(chunkURL)
+				lock.Lock()
+				next = append(next, found...)
+				lock.Unlock()
+			}(chunkURL)
+		}
+		wg.Wait()
+		chunks = next
+	}
+}
+
+/*
+*
+判断URL是否和页面在同一主机
+*/
+func (tab *Tab) inScope(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Hostname(), tab.NavigateReq.URL.Hostname())
+}
+
+/*
+*
+获取一个分块并提取端点，返回分块中引用的分块
+*/
+func (tab *Tab) parseChunk(chunkURL string) []string {
+	resp, err := requests.Get(chunkURL, tools.ConvertHeaders(tab.ExtraHeaders),
+		&requests.ReqOptions{Timeout: 10, AllowRedirect: true, Proxy: tab.config.Proxy})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	if err := tab.config.JSArchive.Add(tab.NavigateReq.URL.String(), chunkURL, []byte(resp.Text)); err != nil {
+		log.Println(chalk.Red.Color("error: JS文件保存失败, " + err.Error()))
+	}
+	endpoints, err := jsextract.Extract(resp.Text)
+	if err != nil {
+		return nil
+	}
+	for _, endpoint := range endpoints {
+		method, url, contentType, postData := endpoint.Request()
+		tab.AddResultEndpoint(method, url, config.FromJSChunk, postData, contentType)
+	}
+	tab.HandleSourceMap(chunkURL, resp.Header, resp.Text)
+	chunks, _ := jsextract.Chunks(chunkURL, resp.Text)
+	return chunks
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+/*
+*
+记录XHR请求发送的GraphQL操作，批量请求中的每个操作作为单独的结果
+*/
+func (tab *Tab) HandleGraphQL(req model.Request) {
+	recorder := tab.config.GraphQL
+	if recorder == nil {
+		return
+	}
+	operations := graphql.ParseRequest(req.Method, req.URL.String(), req.PostData)
+	if len(operations) == 0 {
+		return
+	}
+	for _, operation := range operations {
+		if recorder.Add(operation) && len(operations) > 1 {
+			tab.AddResultEndpoint(config.POST, operation.Endpoint, config.FromGraphQL, operation.Body(), config.JSON)
+		}
+	}
+	tab.IntrospectGraphQL(operations[0].Endpoint)
+}
+
+/*
+*
+从JS文件或页面内联脚本中提取GraphQL操作和端点，每个操作作为单独的结果
+脚本中未指定端点时发送到/graphql
+*/
+func (tab *Tab) HandleGraphQLScript(v *network.EventResponseReceived, body string) {
+	if tab.config.GraphQL == nil {
+		return
+	}
+	switch {
+	case v.Response.MimeType == "application/javascript" || v.Response.MimeType == "text/javascript":
+		tab.handleGraphQLScript(body)
+	case v.Response.MimeType == "text/html" && v.Type == network.ResourceTypeDocument:
+		for _, script := range jsarchive.InlineScripts(body) {
+			tab.handleGraphQLScript(script)
+		}
+	}
+}
+
+func (tab *Tab) handleGraphQLScript(script string) {
+	operations, endpoints := graphql.ScanScript(script)
+	if len(operations) == 0 {
+		return
+	}
+	if len(endpoints) == 0 {
+		endpoints = append(endpoints, graphql.DefaultPath)
+	}
+	for _, endpoint := range endpoints {
+		// 相对路径和运行时一样以页面URL为基准
+		url, err := model.GetUrl(endpoint, *tab.NavigateReq.URL)
+		if err != nil {
+			continue
+		}
+		for _, operation := range operations {
+			operation.Endpoint = url.String()
+			if tab.config.GraphQL.Add(operation) {
+				tab.AddResultEndpoint(config.POST, operation.Endpoint, config.FromGraphQL, operation.Body(), config.JSON)
+			}
+		}
+		tab.IntrospectGraphQL(url.String())
+	}
+}
+
+/*
+*
+对GraphQL端点执行内省查询，将Schema中的每个查询作为单独的结果
+变更只记录，开启变更重放时才作为结果，疑似破坏性的变更(删除、注销、重置等)始终不发送
+每个端点在两个爬虫中只内省一次
+*/
+func (tab *Tab) IntrospectGraphQL(endpoint string) {
+	recorder := tab.config.GraphQL
+	if !recorder.ClaimIntrospection(endpoint) {
+		return
+	}
+	headers := tools.ConvertHeaders(tab.ExtraHeaders)
+	headers["Content-Type"] = config.JSON
+	operations, err := graphql.Introspect(endpoint, func(url string, body []byte) (int, []byte, error) {
+		resp, err := requests.Request(config.POST, url, headers, body,
+			&requests.ReqOptions{Timeout: 10, AllowRedirect: false, Proxy: tab.config.Proxy})
+		if err != nil {
+			return 0, nil, err
+		}
+		return resp.StatusCode, []byte(resp.Text), nil
+	})
+	if err != nil {
+		return
+	}
+	for _, operation := range operations {
+		if recorder.Add(operation) && recorder.Replay(operation) {
+			tab.AddResultEndpoint(config.POST, operation.Endpoint, config.FromGraphQL, operation.Body(), config.JSON)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"Venom-Crawler/pkg/auth"
 	"Venom-Crawler/pkg/crawlergo/config"
 	"Venom-Crawler/pkg/crawlergo/model"
 	"Venom-Crawler/pkg/crawlergo/tools"
 	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/jsextract"
 	"bufio"
 	"context"
 	"encoding/base64"
@@ -49,6 +51,17 @@ func (tab *Tab) InterceptRequest(v *fetch.EventRequestPaused) {
 	}
 
 	tab.HandleHostBinding(&req)
+	rewritten := tab.RewriteRequest(&req, v.ResourceType)
+
+	// 命中拦截策略的请求(统计、广告、字体等) 全部阻断，静态资源与未拦截时一样计入结果
+	if !tab.IsNavigatorRequest(v.NetworkID.String()) && tab.config.Block.Block(req.URL.String(), string(v.ResourceType)) {
+		_ = fetch.FailRequest(v.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+		if config.StaticSuffixSet.Contains(url.FileExt()) {
+			req.Source = config.FromStaticRes
+			tab.AddResultRequest(req)
+		}
+		return
+	}
 
 	// 静态资源 全部阻断
 	// https://Venom-Crawler/issues/106
@@ -70,7 +83,12 @@ func (tab *Tab) InterceptRequest(v *fetch.EventRequestPaused) {
 
 	req.Source = config.FromXHR
 	tab.AddResultRequest(req)
-	_ = fetch.ContinueRequest(v.RequestID).Do(ctx)
+	continueReq := fetch.ContinueRequest(v.RequestID)
+	if rewritten {
+		continueReq = continueReq.WithURL(req.URL.String()).WithHeaders(RequestHeaderEntries(req.Headers))
+	}
+	_ = continueReq.Do(ctx)
+	tab.HandleGraphQL(req)
 }
 
 /*
@@ -88,13 +106,39 @@ func (tab *Tab) IsNavigatorRequest(networkID string) bool {
 func (tab *Tab) HandleAuthRequired(req *fetch.EventAuthRequired) {
 	defer tab.WG.Done()
 	ctx := tab.GetExecutor()
-	authRes := fetch.AuthChallengeResponse{
+	authRes := authChallengeResponse(tab.config.Credentials, req.AuthChallenge)
+	_ = fetch.ContinueWithAuth(req.RequestID, authRes).Do(ctx)
+}
+
+/*
+*
+从凭据库中查找认证质询对应的账号密码，代理认证和站点认证分开处理，
+没有匹配的凭据时取消认证
+*/
+func authChallengeResponse(credentials *auth.Store, challenge *fetch.AuthChallenge) *fetch.AuthChallengeResponse {
+	var credential auth.Credential
+	var found bool
+	if challenge != nil {
+		host := challenge.Origin
+		if u, err := model.GetUrl(challenge.Origin); err == nil {
+			host = u.Host
+		}
+		if challenge.Source == fetch.AuthChallengeSourceProxy {
+			credentials.Record(auth.ProxyPattern, challenge.Scheme)
+			credential, found = credentials.Proxy()
+		} else {
+			credentials.Record(host, challenge.Scheme)
+			credential, found = credentials.Lookup(host)
+		}
+	}
+	if !found || credential.Cancel {
+		return &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+	}
+	return &fetch.AuthChallengeResponse{
 		Response: fetch.AuthChallengeResponseResponseProvideCredentials,
-		Username: "Crawlergo",
-		Password: "Crawlergo",
+		Username: credential.Username,
+		Password: credential.Password,
 	}
-	// 取消认证
-	_ = fetch.ContinueWithAuth(req.RequestID, &authRes).Do(ctx)
 }
 
 /*
@@ -145,10 +189,13 @@ func (tab *Tab) HandleNavigationReq(req *model.Request, v *fetch.EventRequestPau
 			overrideReq = overrideReq.WithPostData(navReq.PostData)
 		}
 		overrideReq = overrideReq.WithMethod(navReq.Method)
-		overrideReq = overrideReq.WithHeaders(MergeHeaders(navReq.Headers, req.Headers))
+		overrideReq = overrideReq.WithHeaders(tab.RewriteHeaderEntries(v.Request.URL, v.ResourceType, MergeHeaders(navReq.Headers, req.Headers)))
 		_ = overrideReq.Do(tCtx)
 		// 子frame的导航
 	} else if !tab.IsTopFrame(v.FrameID.String()) {
+		if tab.config.Rewrite.HasRequestRules() {
+			overrideReq = overrideReq.WithHeaders(RequestHeaderEntries(req.Headers))
+		}
 		_ = overrideReq.Do(tCtx)
 		// 前端跳转 返回204
 	} else {
@@ -202,6 +249,22 @@ func (tab *Tab) ParseResponseURL(v *network.EventResponseReceived) {
 		return
 	}
 	resStr := string(res)
+	tab.ArchiveScripts(v, res)
+	tab.HandleGraphQLScript(v, resStr)
+
+	// JS文件通过语法树提取端点，解析失败时使用正则
+	if strings.HasSuffix(v.Response.MimeType, "/javascript") {
+		tab.HandleSourceMap(v.Response.URL, responseHeader(v.Response.Headers), resStr)
+		tab.HandleChunks(v.Response.URL, resStr)
+		if endpoints, err := jsextract.Extract(resStr); err == nil {
+			for _, endpoint := range endpoints {
+				// 相对路径和运行时一样以页面URL为基准
+				method, url, contentType, postData := endpoint.Request()
+				tab.AddResultEndpoint(method, url, config.FromJSFile, postData, contentType)
+			}
+			return
+		}
+	}
 
 	urlRegex := regexp.MustCompile(config.SuspectURLRegex)
 	urlList := urlRegex.FindAllString(resStr, -1)
@@ -224,6 +287,32 @@ func (tab *Tab) HandleRedirectionResp(v *network.EventResponseReceivedExtraInfo)
 	if 300 <= statusCode && statusCode < 400 {
 		tab.FoundRedirection = true
 	}
+	// 重定向到登录页 说明会话已失效
+	var location string
+	for key, value := range v.Headers {
+		if strings.EqualFold(key, "Location") {
+			location, _ = value.(string)
+		}
+	}
+	if tab.config.SessionMonitor.Check(statusCode, "", location, "") {
+		tab.SessionLost = true
+	}
+}
+
+/*
+*
+检查导航响应是否命中登出特征
+*/
+func (tab *Tab) CheckSessionLoss(v *network.EventResponseReceived) {
+	defer tab.WG.Done()
+	var body string
+	ctx := tab.GetExecutor()
+	if res, err := network.GetResponseBody(v.RequestID).Do(ctx); err == nil {
+		body = string(res)
+	}
+	if tab.config.SessionMonitor.Check(int(v.Response.Status), v.Response.URL, "", body) {
+		tab.SessionLost = true
+	}
 }
 
 func (tab *Tab) GetContentCharset(v *network.EventResponseReceived) {
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"testing"
+
+	"Venom-Crawler/pkg/auth"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthChallengeResponse(t *testing.T) {
+	credentials, err := auth.ParseStore("*.example.com=admin:secret,cancel.example.org=cancel,proxy=user:pass")
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		challenge *fetch.AuthChallenge
+		want      *fetch.AuthChallengeResponse
+	}{
+		{
+			name:      "matching credential",
+			challenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceServer, Origin: "https://www.example.com", Scheme: "basic"},
+			want:      &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseProvideCredentials, Username: "admin", Password: "secret"},
+		},
+		{
+			name:      "no credential",
+			challenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceServer, Origin: "https://other.com", Scheme: "basic"},
+			want:      &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth},
+		},
+		{
+			name:      "cancel rule",
+			challenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceServer, Origin: "https://cancel.example.org", Scheme: "digest"},
+			want:      &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth},
+		},
+		{
+			name:      "proxy credential",
+			challenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceProxy, Origin: "http://127.0.0.1:8080", Scheme: "basic"},
+			want:      &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseProvideCredentials, Username: "user", Password: "pass"},
+		},
+		{
+			name: "no challenge",
+			want: &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, authChallengeResponse(credentials, test.challenge), test.name)
+	}
+	assert.Equal(t, []string{"cancel.example.org (digest)", "other.com (basic)", "proxy (basic)", "www.example.com (basic)"}, credentials.Challenged())
+
+	// without a credential store every challenge is cancelled
+	assert.Equal(t, &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth},
+		authChallengeResponse(nil, &fetch.AuthChallenge{Origin: "https://www.example.com"}))
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"log"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/ttacon/chalk"
+)
+
+/*
+*
+保存JS响应，或页面文档中的内联脚本到JS归档目录
+*/
+func (tab *Tab) ArchiveScripts(v *network.EventResponseReceived, body []byte) {
+	archive := tab.config.JSArchive
+	if archive == nil {
+		return
+	}
+	var err error
+	switch {
+	case v.Response.MimeType == "application/javascript" || v.Response.MimeType == "text/javascript":
+		err = archive.Add(tab.NavigateReq.URL.String(), v.Response.URL, body)
+	case v.Response.MimeType == "text/html" && v.Type == network.ResourceTypeDocument:
+		err = archive.AddInline(v.Response.URL, string(body))
+	}
+	if err != nil {
+		log.Println(chalk.Red.Color("error: JS文件保存失败, " + err.Error()))
+	}
+}
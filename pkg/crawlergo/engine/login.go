@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/session"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+const defaultLoginWaitTimeout = 10 * time.Second
+
+/*
+*
+在独立的标签页中执行登录脚本，返回登录后的Cookie和localStorage
+*/
+func (bro *Browser) Login(script *session.Script, timeout time.Duration) (*session.State, error) {
+	ctx, cancel := bro.NewTab(timeout)
+	defer cancel()
+
+	var tasks chromedp.Tasks
+	if len(bro.ExtraHeaders) > 0 {
+		headers := network.Headers{}
+		for key, value := range bro.ExtraHeaders {
+			if key != "Host" {
+				headers[key] = value
+			}
+		}
+		tasks = append(tasks, network.Enable(), network.SetExtraHTTPHeaders(headers))
+	}
+	for _, step := range script.Steps {
+		tasks = append(tasks, loginStepAction(step))
+	}
+	if err := chromedp.Run(*ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	state := session.NewState()
+	var origin string
+	var items map[string]string
+	if err := chromedp.Run(*ctx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			cookies, err := storage.GetCookies().Do(ctx)
+			if err != nil {
+				return err
+			}
+			state.SetCookies(FromNetworkCookies(cookies))
+			return nil
+		}),
+		chromedp.Evaluate(`window.location.origin`, &origin),
+		chromedp.Evaluate(`Object.assign({}, window.localStorage)`, &items),
+	); err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		state.SetLocalStorage(origin, items)
+	}
+	return state, nil
+}
+
+func loginStepAction(step session.Step) chromedp.Action {
+	switch step.Action {
+	case session.ActionNavigate:
+		return chromedp.Navigate(step.URL)
+	case session.ActionType:
+		return chromedp.Tasks{
+			chromedp.WaitVisible(step.Selector, chromedp.ByQuery),
+			chromedp.SetValue(step.Selector, "", chromedp.ByQuery),
+			chromedp.SendKeys(step.Selector, step.Value, chromedp.ByQuery),
+		}
+	case session.ActionClick:
+		return chromedp.Tasks{
+			chromedp.WaitVisible(step.Selector, chromedp.ByQuery),
+			chromedp.Click(step.Selector, chromedp.ByQuery),
+		}
+	case session.ActionWait:
+		timeout := defaultLoginWaitTimeout
+		if step.Timeout > 0 {
+			timeout = time.Duration(step.Timeout) * time.Second
+		}
+		return chromedp.ActionFunc(func(ctx context.Context) error {
+			tCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if step.Selector != "" {
+				return chromedp.WaitVisible(step.Selector, chromedp.ByQuery).Do(tCtx)
+			}
+			return waitURLContains(tCtx, step.URL)
+		})
+	}
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		return errors.New("unknown login step action: " + step.Action)
+	})
+}
+
+func waitURLContains(ctx context.Context, substr string) error {
+	for {
+		var location string
+		if err := chromedp.Location(&location).Do(ctx); err != nil {
+			return err
+		}
+		if strings.Contains(location, substr) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+/*
+*
+将会话中的Cookie和localStorage/sessionStorage应用到标签页
+*/
+func ApplySessionState(state *session.State) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if state == nil {
+			return nil
+		}
+		if cookies := ToCookieParams(state.GetCookies()); len(cookies) > 0 {
+			if err := network.SetCookies(cookies).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if script := state.StorageInjectJS(); script != "" {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(script).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func FromNetworkCookies(cookies []*network.Cookie) []session.Cookie {
+	var result []session.Cookie
+	for _, c := range cookies {
+		cookie := session.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite.String(),
+		}
+		if !c.Session {
+			cookie.Expires = c.Expires
+		}
+		result = append(result, cookie)
+	}
+	return result
+}
+
+func ToCookieParams(cookies []session.Cookie) []*network.CookieParam {
+	var params []*network.CookieParam
+	for _, c := range cookies {
+		param := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+		}
+		if param.Path == "" {
+			param.Path = "/"
+		}
+		if c.SameSite != "" {
+			param.SameSite = network.CookieSameSite(c.SameSite)
+		}
+		if c.Expires > 0 {
+			expires := cdp.TimeSinceEpoch(time.Unix(int64(c.Expires), 0))
+			param.Expires = &expires
+		}
+		params = append(params, param)
+	}
+	return params
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// 检查网络空闲的轮询间隔
+const networkIdlePollInterval = 50 * time.Millisecond
+
+/*
+*
+记录标签页中未完成的网络请求
+*/
+type networkTracker struct {
+	lock         sync.Mutex
+	pending      map[network.RequestID]struct{}
+	lastActivity time.Time
+}
+
+func newNetworkTracker() *networkTracker {
+	return &networkTracker{
+		pending:      make(map[network.RequestID]struct{}),
+		lastActivity: time.Now(),
+	}
+}
+
+/*
+*
+请求发出，重定向沿用同一个RequestID
+EventSource是长连接，不会结束，不计入
+*/
+func (n *networkTracker) started(v *network.EventRequestWillBeSent) {
+	if v.Type == network.ResourceTypeEventSource {
+		return
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.pending[v.RequestID] = struct{}{}
+	n.lastActivity = time.Now()
+}
+
+/*
+*
+请求完成或失败
+*/
+func (n *networkTracker) finished(requestID network.RequestID) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if _, ok := n.pending[requestID]; !ok {
+		return
+	}
+	delete(n.pending, requestID)
+	n.lastActivity = time.Now()
+}
+
+/*
+*
+没有未完成的请求，且已经安静了quiet时长
+*/
+func (n *networkTracker) idle(quiet time.Duration) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return len(n.pending) == 0 && time.Since(n.lastActivity) >= quiet
+}
+
+/*
+*
+等待网络空闲：没有未完成的请求并持续NetworkQuietTime，最多等待NetworkIdleMaxWait
+未开启网络空闲检测时，按原来的方式固定等待fallback
+*/
+func (tab *Tab) WaitNetworkIdle(fallback time.Duration) {
+	quiet := tab.config.NetworkQuietTime
+	if quiet <= 0 {
+		time.Sleep(fallback)
+		return
+	}
+	deadline := time.Now().Add(tab.config.NetworkIdleMaxWait)
+	ctx := tab.GetExecutor()
+	for !tab.network.idle(quiet) && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(networkIdlePollInterval):
+		}
+	}
+}
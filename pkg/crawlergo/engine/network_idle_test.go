@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newIdleTab returns a tab waiting for the network with quiet and maxWait,
+// the browser is never started
+func newIdleTab(t *testing.T, quiet, maxWait time.Duration) *Tab {
+	ctx, cancel := chromedp.NewContext(context.Background())
+	t.Cleanup(cancel)
+	return &Tab{
+		Ctx:     &ctx,
+		config:  TabConfig{NetworkQuietTime: quiet, NetworkIdleMaxWait: maxWait},
+		network: newNetworkTracker(),
+	}
+}
+
+func TestNetworkTracker(t *testing.T) {
+	tracker := newNetworkTracker()
+	tracker.started(&network.EventRequestWillBeSent{RequestID: "1", Type: network.ResourceTypeXHR})
+	tracker.started(&network.EventRequestWillBeSent{RequestID: "2", Type: network.ResourceTypeEventSource})
+	assert.False(t, tracker.idle(0), "should wait for the pending request")
+
+	tracker.finished("2")
+	tracker.finished("1")
+	assert.True(t, tracker.idle(0), "should not count the event streams")
+	assert.False(t, tracker.idle(time.Hour), "should wait for the quiet time")
+}
+
+func TestWaitNetworkIdleQuiet(t *testing.T) {
+	tab := newIdleTab(t, 200*time.Millisecond, 5*time.Second)
+	tab.network.started(&network.EventRequestWillBeSent{RequestID: "1", Type: network.ResourceTypeFetch})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		tab.network.finished("1")
+	}()
+
+	start := time.Now()
+	tab.WaitNetworkIdle(0)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond, "should wait for the request and the quiet time")
+	assert.Less(t, elapsed, 2*time.Second, "should return once the network is quiet")
+}
+
+func TestWaitNetworkIdleMaxWait(t *testing.T) {
+	tab := newIdleTab(t, 100*time.Millisecond, 300*time.Millisecond)
+	tab.network.started(&network.EventRequestWillBeSent{RequestID: "1", Type: network.ResourceTypeXHR})
+
+	start := time.Now()
+	tab.WaitNetworkIdle(0)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second, "should give up at the maximum wait")
+}
+
+func TestWaitNetworkIdleDisabled(t *testing.T) {
+	tab := newIdleTab(t, -1, 5*time.Second)
+	tab.network.started(&network.EventRequestWillBeSent{RequestID: "1", Type: network.ResourceTypeXHR})
+
+	start := time.Now()
+	tab.WaitNetworkIdle(100 * time.Millisecond)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond, "should sleep for the fallback")
+	assert.Less(t, elapsed, time.Second, "should not wait for the pending request")
+}
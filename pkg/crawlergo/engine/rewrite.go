@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/rewrite"
+	"encoding/base64"
+	"fmt"
+	"github.com/ttacon/chalk"
+	"log"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+)
+
+/*
+*
+开启请求拦截，有响应重写规则时同时拦截响应阶段
+*/
+func (tab *Tab) EnableFetch() *fetch.EnableParams {
+	enable := fetch.Enable().WithHandleAuthRequests(true)
+	if tab.config.Rewrite.HasResponseRules() {
+		enable = enable.WithPatterns([]*fetch.RequestPattern{
+			{URLPattern: "*", RequestStage: fetch.RequestStageRequest},
+			{URLPattern: "*", RequestStage: fetch.RequestStageResponse},
+		})
+	}
+	return enable
+}
+
+/*
+*
+按重写规则修改请求的URL和请求头，返回是否修改
+*/
+func (tab *Tab) RewriteRequest(req *model.Request, resourceType network.ResourceType) bool {
+	rules := tab.config.Rewrite
+	if !rules.HasRequestRules() {
+		return false
+	}
+	var headers []rewrite.Header
+	for key, value := range req.Headers {
+		headers = append(headers, rewrite.Header{Name: key, Value: fmt.Sprint(value)})
+	}
+	url, headers, changed := rules.RewriteRequest(req.URL.String(), string(resourceType), headers)
+	if !changed {
+		return false
+	}
+	if urlObj, err := model.GetUrl(url); err == nil {
+		req.URL = urlObj
+	} else {
+		log.Println(chalk.Red.Color("error: 重写后的URL不能被解析, " + url))
+	}
+	req.Headers = map[string]interface{}{}
+	for _, header := range headers {
+		req.Headers[header.Name] = header.Value
+	}
+	return true
+}
+
+/*
+*
+按重写规则修改合并后的请求头，URL使用重写前的原始URL匹配规则
+*/
+func (tab *Tab) RewriteHeaderEntries(url string, resourceType network.ResourceType, entries []*fetch.HeaderEntry) []*fetch.HeaderEntry {
+	rules := tab.config.Rewrite
+	if !rules.HasRequestRules() {
+		return entries
+	}
+	var headers []rewrite.Header
+	for _, entry := range entries {
+		headers = append(headers, rewrite.Header{Name: entry.Name, Value: entry.Value})
+	}
+	_, headers, changed := rules.RewriteRequest(url, string(resourceType), headers)
+	if !changed {
+		return entries
+	}
+	return ToHeaderEntries(headers)
+}
+
+/*
+*
+处理响应阶段的拦截，按重写规则修改响应头和响应体
+*/
+func (tab *Tab) InterceptResponse(v *fetch.EventRequestPaused) {
+	defer tab.WG.Done()
+	ctx := tab.GetExecutor()
+	rules := tab.config.Rewrite
+	// 重定向响应没有响应体，直接放行
+	if !rules.MatchResponse(v.Request.URL, string(v.ResourceType)) || v.ResponseErrorReason != "" ||
+		(v.ResponseStatusCode >= 300 && v.ResponseStatusCode < 400) {
+		_ = fetch.ContinueRequest(v.RequestID).Do(ctx)
+		return
+	}
+	body, err := fetch.GetResponseBody(v.RequestID).Do(ctx)
+	if err != nil {
+		_ = fetch.ContinueRequest(v.RequestID).Do(ctx)
+		return
+	}
+	var headers []rewrite.Header
+	for _, header := range v.ResponseHeaders {
+		headers = append(headers, rewrite.Header{Name: header.Name, Value: header.Value})
+	}
+	headers, body, changed := rules.RewriteResponse(v.Request.URL, string(v.ResourceType), headers, body)
+	if !changed {
+		_ = fetch.ContinueRequest(v.RequestID).Do(ctx)
+		return
+	}
+	err = fetch.FulfillRequest(v.RequestID, v.ResponseStatusCode).
+		WithResponseHeaders(ToHeaderEntries(headers)).
+		WithBody(base64.StdEncoding.EncodeToString(body)).Do(ctx)
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 重写响应失败, " + err.Error()))
+	}
+}
+
+func ToHeaderEntries(headers []rewrite.Header) []*fetch.HeaderEntry {
+	var entries []*fetch.HeaderEntry
+	for _, header := range headers {
+		entries = append(entries, &fetch.HeaderEntry{Name: header.Name, Value: header.Value})
+	}
+	return entries
+}
+
+func RequestHeaderEntries(headers map[string]interface{}) []*fetch.HeaderEntry {
+	var entries []*fetch.HeaderEntry
+	for key, value := range headers {
+		entries = append(entries, &fetch.HeaderEntry{Name: key, Value: fmt.Sprint(value)})
+	}
+	return entries
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/soft404"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+/*
+*
+将导航响应与所在目录下不存在路径的响应比较，判断是否为软404
+重定向后的响应不比较，基准响应不跟随重定向
+*/
+func (tab *Tab) CheckSoft404(v *network.EventResponseReceived) {
+	defer tab.WG.Done()
+	if tab.NavigateReq.Method != config.GET || v.Response.URL != tab.NavigateReq.URL.String() {
+		return
+	}
+	ctx := tab.GetExecutor()
+	body, err := network.GetResponseBody(v.RequestID).Do(ctx)
+	if err != nil {
+		return
+	}
+	var location string
+	for key, value := range v.Response.Headers {
+		if strings.EqualFold(key, "Location") {
+			location = fmt.Sprint(value)
+		}
+	}
+	resp := soft404.Response{StatusCode: int(v.Response.Status), Location: location, Body: string(body)}
+	probe := Soft404Probe(tab.ExtraHeaders, tab.config.Proxy)
+	if tab.config.Soft404.Check(v.Response.URL, resp, probe) {
+		tab.Soft404 = true
+	}
+}
+
+/*
+*
+返回请求软404基准路径的函数，不跟随重定向
+*/
+func Soft404Probe(headers map[string]interface{}, proxy string) soft404.ProbeFunc {
+	return func(url string) (soft404.Response, error) {
+		resp, err := requests.Get(url, tools.ConvertHeaders(headers),
+			&requests.ReqOptions{Timeout: 10, AllowRedirect: false, Proxy: proxy})
+		if err != nil {
+			return soft404.Response{}, err
+		}
+		return soft404.Response{StatusCode: resp.StatusCode, Location: resp.Header.Get("Location"), Body: resp.Text}, nil
+	}
+}
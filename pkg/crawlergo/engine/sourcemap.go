@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+	"Venom-Crawler/pkg/sourcemap"
+	"fmt"
+	"net/http"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+/*
+*
+获取JS文件的源码映射，记录其中的原始源码文件，并从原始源码中提取端点
+同一个JS文件和映射文件在两个爬虫中只处理一次
+*/
+func (tab *Tab) HandleSourceMap(scriptURL string, header http.Header, body string) {
+	recorder := tab.config.SourceMaps
+	if !recorder.Claim(scriptURL) {
+		return
+	}
+	for _, mapURL := range sourcemap.Locate(scriptURL, header, body) {
+		if !recorder.Claim(mapURL) {
+			continue
+		}
+		data, ok := sourcemap.DecodeDataURL(mapURL)
+		if !ok {
+			resp, err := requests.Get(mapURL, tools.ConvertHeaders(tab.ExtraHeaders),
+				&requests.ReqOptions{Timeout: 10, AllowRedirect: true, Proxy: tab.config.Proxy})
+			if err != nil || resp.StatusCode != http.StatusOK {
+				continue
+			}
+			data = []byte(resp.Text)
+		}
+		m, err := sourcemap.Parse(data)
+		if err != nil {
+			continue
+		}
+		endpoints := m.Endpoints()
+		recorder.Record(scriptURL, mapURL, m, endpoints)
+		for _, endpoint := range endpoints {
+			method, url, contentType, postData := endpoint.Request()
+			tab.AddResultEndpoint(method, url, config.FromSourceMap, postData, contentType)
+		}
+		return
+	}
+}
+
+/*
+*
+响应头转换为http.Header
+*/
+func responseHeader(headers network.Headers) http.Header {
+	header := http.Header{}
+	for key, value := range headers {
+		header.Set(key, fmt.Sprint(value))
+	}
+	return header
+}
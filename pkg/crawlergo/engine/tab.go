@@ -1,9 +1,18 @@
 package engine
 
 import (
+	"Venom-Crawler/pkg/auth"
+	"Venom-Crawler/pkg/block"
 	"Venom-Crawler/pkg/crawlergo/config"
 	"Venom-Crawler/pkg/crawlergo/js"
 	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
+	"Venom-Crawler/pkg/rewrite"
+	"Venom-Crawler/pkg/session"
+	"Venom-Crawler/pkg/soft404"
+	"Venom-Crawler/pkg/sourcemap"
+	"Venom-Crawler/pkg/wsdl"
 	"context"
 	"encoding/json"
 	"errors"
@@ -37,8 +46,13 @@ type Tab struct {
 	PageCharset      string
 	PageBindings     map[string]interface{}
 	FoundRedirection bool
+	SessionLost      bool // 导航响应命中了登出特征
+	Soft404          bool // 导航响应与不存在路径的响应相同
 	DocBodyNodeId    cdp.NodeID
+	Session          *session.State
 	config           TabConfig
+	network          *networkTracker // 未完成的网络请求，用于判断网络空闲
+	chunks           *chunkSet       // 已获取的JS分块
 
 	lock sync.Mutex
 
@@ -57,11 +71,24 @@ type TabConfig struct {
 	EventTriggerMode        string        // 事件触发的调用方式： 异步 或 顺序
 	EventTriggerInterval    time.Duration // 事件触发的间隔 单位毫秒
 	BeforeExitDelay         time.Duration // 退出前的等待时间，等待DOM渲染，等待XHR发出捕获
+	NetworkQuietTime        time.Duration // 没有未完成请求持续该时长视为网络空闲，不大于0时使用固定等待
+	NetworkIdleMaxWait      time.Duration // 等待网络空闲的最长时间
+	MaxScrollTimes          int           // 滚动页面的最大轮数，不大于0时不滚动
+	ScrapeJSChunks          bool          // 获取并解析同站点JS按需加载的分块
 	EncodeURLWithCharset    bool
 	IgnoreKeywords          []string //
 	Proxy                   string
 	CustomFormValues        map[string]string
 	CustomFormKeywordValues map[string]string
+	Credentials             *auth.Store         // 401/407 认证凭据
+	SessionMonitor          *session.Monitor    // 会话失效检测
+	Rewrite                 *rewrite.RuleSet    // 请求/响应重写规则
+	Block                   *block.Policy       // 请求拦截策略
+	SourceMaps              *sourcemap.Recorder // JS源码映射记录，为空时不获取源码映射
+	JSArchive               *jsarchive.Archive  // JS文件归档，为空时不保存
+	GraphQL                 *graphql.Recorder   // GraphQL操作记录，为空时不识别GraphQL
+	Services                *wsdl.Catalog       // WSDL/WADL服务描述记录，为空时不枚举服务
+	Soft404                 *soft404.Detector   // 软404检测，为空时不检测
 }
 
 type bindingCallPayload struct {
@@ -82,35 +109,62 @@ func NewTab(browser *Browser, navigateReq model.Request, config TabConfig) *Tab
 		}
 	}
 	tab.NavigateReq = navigateReq
+	tab.Session = browser.Session
 	tab.config = config
 	tab.DocBodyNodeId = 0
+	tab.network = newNetworkTracker()
+	tab.chunks = &browser.chunks
 
 	// 设置请求拦截监听
 	chromedp.ListenTarget(*tab.Ctx, func(v interface{}) {
 		switch v := v.(type) {
 		// 根据不同的事件 选择执行对应的动作
 		case *network.EventRequestWillBeSent:
+			tab.network.started(v)
 			if string(v.RequestID) == string(v.LoaderID) && v.Type == "Document" && tab.TopFrameId == "" {
 				tab.LoaderID = string(v.LoaderID)
 				tab.TopFrameId = string(v.FrameID)
 			}
 
+		// 请求完成或失败 用于判断网络空闲
+		case *network.EventLoadingFinished:
+			tab.network.finished(v.RequestID)
+		case *network.EventLoadingFailed:
+			tab.network.finished(v.RequestID)
+
 		// 请求发出时暂停 即 请求拦截
 		case *fetch.EventRequestPaused:
 			tab.WG.Add(1)
-			go tab.InterceptRequest(v)
+			// 响应阶段的拦截只用于重写响应
+			if v.ResponseStatusCode != 0 || v.ResponseErrorReason != "" {
+				go tab.InterceptResponse(v)
+			} else {
+				go tab.InterceptRequest(v)
+			}
 
 		// 解析所有JS文件中的URL并添加到结果中
 		// 解析HTML文档中的URL
 		// 查找当前页面的编码
 		case *network.EventResponseReceived:
-			if v.Response.MimeType == "application/javascript" || v.Response.MimeType == "text/html" || v.Response.MimeType == "application/json" {
+			if v.Response.MimeType == "application/javascript" || v.Response.MimeType == "text/javascript" || v.Response.MimeType == "text/html" || v.Response.MimeType == "application/json" {
 				tab.WG.Add(1)
 				go tab.ParseResponseURL(v)
 			}
 			if v.RequestID.String() == tab.NavNetworkID {
 				tab.WG.Add(1)
 				go tab.GetContentCharset(v)
+				if tab.config.SessionMonitor != nil {
+					tab.WG.Add(1)
+					go tab.CheckSessionLoss(v)
+				}
+				if tab.config.Soft404 != nil {
+					tab.WG.Add(1)
+					go tab.CheckSoft404(v)
+				}
+			}
+			if tab.config.Services != nil && (v.RequestID.String() == tab.NavNetworkID || wsdl.IsServiceURL(v.Response.URL)) {
+				tab.WG.Add(1)
+				go tab.HandleServiceDescription(v)
 			}
 		// 处理后端重定向 3XX
 		case *network.EventResponseReceivedExtraInfo:
@@ -167,7 +221,7 @@ func (tab *Tab) Start() {
 			// 开启网络层API
 			network.Enable(),
 			// 开启请求拦截API
-			fetch.Enable().WithHandleAuthRequests(true),
+			tab.EnableFetch(),
 			// 添加回调函数绑定
 			// XSS-Scan 使用的回调
 			runtime.AddBinding("addLink"),
@@ -182,6 +236,8 @@ func (tab *Tab) Start() {
 				return nil
 			}),
 			network.SetExtraHTTPHeaders(tab.ExtraHeaders),
+			// 应用登录后的Cookie和localStorage
+			ApplySessionState(tab.Session),
 			// 执行导航
 			chromedp.Navigate(tab.NavigateReq.URL.String()),
 		}),
@@ -190,6 +246,8 @@ func (tab *Tab) Start() {
 			return
 		}
 	}
+	// 导航完成后等待页面的首批异步请求
+	tab.WaitNetworkIdle(0)
 
 	waitDone := func() <-chan struct{} {
 		tab.WG.Wait()
@@ -236,6 +294,14 @@ func RunWithTimeOut(ctx *context.Context, timeout time.Duration, tasks chromedp.
 添加收集到的URL到结果列表，需要处理Host绑定
 */
 func (tab *Tab) AddResultUrl(method string, _url string, source string) {
+	tab.AddResultEndpoint(method, _url, source, "", "")
+}
+
+/*
+*
+添加收集到的带请求体的请求到结果列表，需要处理Host绑定
+*/
+func (tab *Tab) AddResultEndpoint(method string, _url string, source string, postData string, contentType string) {
 	navUrl := tab.NavigateReq.URL
 	url, err := model.GetUrl(_url, *navUrl)
 	if err != nil {
@@ -243,7 +309,10 @@ func (tab *Tab) AddResultUrl(method string, _url string, source string) {
 	}
 	option := model.Options{
 		Headers:  map[string]interface{}{},
-		PostData: "",
+		PostData: postData,
+	}
+	if contentType != "" {
+		option.Headers["Content-Type"] = contentType
 	}
 	referer := navUrl.String()
 
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"Venom-Crawler/pkg/crawlergo/config"
+	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/crawlergo/tools/requests"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+/*
+*
+识别导航到的WSDL/WADL服务描述，以及SOAP端点和服务列表页面链接的服务描述
+为每个操作生成示例请求作为单独的结果，每个服务描述在两个爬虫中只解析一次
+*/
+func (tab *Tab) HandleServiceDescription(v *network.EventResponseReceived) {
+	defer tab.WG.Done()
+	ctx := tab.GetExecutor()
+	body, err := network.GetResponseBody(v.RequestID).Do(ctx)
+	if err != nil {
+		return
+	}
+	headers := tools.ConvertHeaders(tab.ExtraHeaders)
+	fetch := func(url string) (int, []byte, error) {
+		resp, err := requests.Get(url, headers,
+			&requests.ReqOptions{Timeout: 10, AllowRedirect: true, Proxy: tab.config.Proxy})
+		if err != nil {
+			return 0, nil, err
+		}
+		return resp.StatusCode, []byte(resp.Text), nil
+	}
+
+	for _, operation := range tab.config.Services.Discover(v.Response.URL, body, fetch) {
+		url, err := model.GetUrl(operation.Request.URL, *tab.NavigateReq.URL)
+		if err != nil {
+			continue
+		}
+		reqHeaders := map[string]interface{}{"Referer": v.Response.URL}
+		for key, value := range operation.Request.Headers {
+			reqHeaders[key] = value
+		}
+		req := model.GetRequest(operation.Request.Method, url, model.Options{Headers: reqHeaders, PostData: operation.Request.Body})
+		req.Source = config.FromWSDL
+		tab.AddResultRequest(req)
+	}
+}
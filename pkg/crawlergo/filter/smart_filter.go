@@ -4,6 +4,8 @@ import (
 	"Venom-Crawler/pkg/crawlergo/config"
 	model2 "Venom-Crawler/pkg/crawlergo/model"
 	"Venom-Crawler/pkg/crawlergo/tools"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/wsdl"
 	"github.com/ttacon/chalk"
 	"go/types"
 	"log"
@@ -103,12 +105,16 @@ func (s *SmartFilter) DoFilter(req *model2.Request) bool {
 	}
 
 	req.Filter.FragmentID = s.calcFragmentID(req.URL.Fragment)
+	req.Filter.OperationId = graphql.RequestKey(req.Method, req.URL.String(), req.PostData)
+	if req.Filter.OperationId == "" {
+		req.Filter.OperationId = wsdl.RequestKey(headerValue(req.Headers, "Content-Type"), headerValue(req.Headers, "SOAPAction"), req.PostData)
+	}
 
 	// 标记
 	if req.Method == config.GET || req.Method == config.DELETE || req.Method == config.HEAD || req.Method == config.OPTIONS {
 		s.getMark(req)
 		s.repeatCountStatistic(req)
-	} else if req.Method == config.POST || req.Method == config.PUT {
+	} else if req.Method == config.POST || req.Method == config.PUT || req.Method == config.PATCH {
 		s.postMark(req)
 	} else {
 		log.Println(chalk.Red.Color("error: 不支持该请求方式: " + req.Method))
@@ -594,7 +600,7 @@ func getMarkedUniqueID(req *model2.Request) string {
 		paramId = req.Filter.PostDataId
 	}
 
-	uniqueStr := req.Method + paramId + req.Filter.PathId + req.URL.Host + req.Filter.FragmentID
+	uniqueStr := req.Method + paramId + req.Filter.PathId + req.URL.Host + req.Filter.FragmentID + req.Filter.OperationId
 	if req.RedirectionFlag {
 		uniqueStr += "Redirection"
 	}
@@ -669,3 +675,18 @@ func hasSpecialSymbol(str string) bool {
 func inCommonScriptSuffix(suffix string) bool {
 	return config.ScriptSuffixSet.Contains(suffix)
 }
+
+/*
+*
+获取请求头的值，不区分大小写
+*/
+func headerValue(headers map[string]interface{}, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			if s, ok := value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
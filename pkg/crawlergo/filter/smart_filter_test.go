@@ -52,3 +52,39 @@ func TestDoFilter_countFragment(t *testing.T) {
 		assert.Equal(t, smart.DoFilter(&rq), true)
 	}
 }
+
+func TestDoFilter_graphQLOperations(t *testing.T) {
+	filter := NewSmartFilter(NewSimpleFilter(""), true)
+	url, err := model2.GetUrl("http://test.local.com/graphql")
+	assert.Nil(t, err)
+	newReq := func(postData string) *model2.Request {
+		req := model2.GetRequest(config.POST, url, model2.Options{
+			Headers:  map[string]interface{}{"Content-Type": config.JSON},
+			PostData: postData,
+		})
+		return &req
+	}
+	// 同一端点的不同操作都不应该过滤
+	assert.Equal(t, false, filter.DoFilter(newReq(`{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}`)))
+	assert.Equal(t, false, filter.DoFilter(newReq(`{"query":"query GetPosts { posts { title } }"}`)))
+	// 同一操作只是变量不同，应该被过滤
+	assert.Equal(t, true, filter.DoFilter(newReq(`{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"2"}}`)))
+}
+
+func TestDoFilter_soapOperations(t *testing.T) {
+	filter := NewSmartFilter(NewSimpleFilter(""), true)
+	url, err := model2.GetUrl("http://test.local.com/calculator.asmx")
+	assert.Nil(t, err)
+	newReq := func(action string, body string) *model2.Request {
+		req := model2.GetRequest(config.POST, url, model2.Options{
+			Headers:  map[string]interface{}{"Content-Type": "text/xml; charset=utf-8", "SOAPAction": `"http://tempuri.org/` + action + `"`},
+			PostData: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` + body + `</soap:Body></soap:Envelope>`,
+		})
+		return &req
+	}
+	// 同一端点的不同SOAP操作都不应该过滤
+	assert.Equal(t, false, filter.DoFilter(newReq("Add", `<Add xmlns="http://tempuri.org/"><intA>1</intA></Add>`)))
+	assert.Equal(t, false, filter.DoFilter(newReq("Subtract", `<Subtract xmlns="http://tempuri.org/"><intA>1</intA></Subtract>`)))
+	// 同一操作只是参数不同，应该被过滤
+	assert.Equal(t, true, filter.DoFilter(newReq("Add", `<Add xmlns="http://tempuri.org/"><intA>2</intA></Add>`)))
+}
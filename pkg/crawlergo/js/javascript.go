@@ -450,6 +450,60 @@ const TriggerJavascriptProtocol = `
 })()
 `
 
+const ScrollStepJS = `
+(async function scroll_page_step() {
+	function lazy_load() {
+		for (let node of document.querySelectorAll("img[loading=lazy], iframe[loading=lazy]")) {
+			node.setAttribute("loading", "eager");
+		}
+		let attrNames = ["data-src", "data-original", "data-lazy", "data-lazy-src", "data-url"];
+		for (let node of document.querySelectorAll("img, iframe")) {
+			for (let attrName of attrNames) {
+				let value = node.getAttribute(attrName);
+				if (value && node.getAttribute("src") !== value) {
+					node.setAttribute("src", value);
+					break;
+				}
+			}
+			let srcset = node.getAttribute("data-srcset");
+			if (srcset) {
+				node.setAttribute("srcset", srcset);
+			}
+		}
+	}
+	async function scroll_to_end(target, element) {
+		for (let step = 0; step < 50; step++) {
+			let before = element.scrollTop;
+			element.scrollTop = before + Math.max(element.clientHeight, window.innerHeight / 2);
+			target.dispatchEvent(new Event("scroll"));
+			await window.sleep(%f);
+			if (element.scrollTop === before) {
+				break;
+			}
+		}
+	}
+	lazy_load();
+	await scroll_to_end(window, document.scrollingElement || document.documentElement);
+	let containers = [];
+	for (let node of document.querySelectorAll("body *")) {
+		if (containers.length >= 10) {
+			break;
+		}
+		if (node.scrollHeight > node.clientHeight + 50 && node.clientHeight > 0) {
+			let overflowY = window.getComputedStyle(node).overflowY;
+			if (overflowY === "auto" || overflowY === "scroll") {
+				containers.push(node);
+			}
+		}
+	}
+	for (let node of containers) {
+		await scroll_to_end(node, node);
+	}
+	lazy_load();
+	return document.getElementsByTagName("*").length;
+})()
+`
+
 const FormNodeClickJS = `
 (function(a) {
 	try {
@@ -19,6 +19,7 @@ type Filter struct {
 	MarkedPath        string
 	FragmentID        string
 	PathId            string
+	OperationId       string // GraphQL或SOAP操作的唯一ID，同一端点的不同操作不去重
 	UniqueId          string
 }
 
@@ -36,6 +37,7 @@ type Request struct {
 	Source          string
 	RedirectionFlag bool
 	Proxy           string
+	ReauthCount     int // 会话失效后重新认证并重新入队的次数
 }
 
 var supportContentType = []string{config.JSON, config.URLENCODED}
@@ -5,13 +5,11 @@ import (
 	"Venom-Crawler/pkg/crawlergo/model"
 	"Venom-Crawler/pkg/crawlergo/tools"
 	"Venom-Crawler/pkg/crawlergo/tools/requests"
-	"fmt"
-	"regexp"
+	"Venom-Crawler/pkg/openapi"
+	"Venom-Crawler/pkg/pathfuzz"
+	"Venom-Crawler/pkg/wellknown"
+	"Venom-Crawler/pkg/wsdl"
 	"strings"
-	"sync"
-
-	mapset "github.com/deckarep/golang-set"
-	"github.com/panjf2000/ants/v2"
 )
 
 const pathStr = "11/123/2017/2018/message/mis/model/abstract/account/act/action" +
@@ -34,42 +32,125 @@ const pathStr = "11/123/2017/2018/message/mis/model/abstract/account/act/action"
 	"/util/v1/v2/vendor/view/views/web/weixin/widgets/wm/wordpress/workspace/ws/www/www2/wwwroot/zone" +
 	"/admin/admin_bak/mobile/m/js"
 
-var pathFuzzWG sync.WaitGroup
-var validateUrl mapset.Set
-
 /*
 *
-从robots.txt文件中获取路径信息
+从robots.txt文件中获取路径信息，并解析其中声明的站点地图
 */
 func GetPathsFromRobots(navReq model.Request) []*model.Request {
+	return wellKnownRequests(navReq, wellknown.Robots(navReq.URL.String(), wellKnownFetch(navReq)))
+}
+
+/*
+*
+获取站点的robots.txt、站点地图、security.txt、crossdomain.xml等常见文件，从中提取路径
+每个站点在两个爬虫中只获取一次，之后的调用返回已获取的结果
+*/
+func GetPathsFromWellKnown(navReq model.Request, recorder *wellknown.Recorder) []*model.Request {
+	return wellKnownRequests(navReq, recorder.Discover(navReq.URL.String(), wellKnownFetch(navReq)))
+}
+
+func wellKnownFetch(navReq model.Request) wellknown.FetchFunc {
+	return func(url string) (int, []byte, error) {
+		resp, err := requests.Get(url, tools.ConvertHeaders(navReq.Headers),
+			&requests.ReqOptions{AllowRedirect: false,
+				Timeout: 5,
+				Proxy:   navReq.Proxy})
+		if err != nil {
+			return 0, nil, err
+		}
+		return resp.StatusCode, []byte(resp.Text), nil
+	}
+}
+
+func wellKnownRequests(navReq model.Request, results []wellknown.Result) []*model.Request {
 	var result []*model.Request
-	var urlFindRegex = regexp.MustCompile(`(?:Disallow|Allow):.*?(/.+)`)
-	var urlRegex = regexp.MustCompile(`(/.+)`)
+	for _, file := range results {
+		source := config.FromWellKnown
+		if file.Source == wellknown.SourceRobots {
+			source = config.FromRobots
+		}
+		for _, _url := range file.URLs {
+			url, err := model.GetUrl(_url, *navReq.URL)
+			if err != nil {
+				continue
+			}
+			req := model.GetRequest(config.GET, url)
+			req.Source = source
+			result = append(result, &req)
+		}
+	}
+	return result
+}
 
-	navReq.URL.Path = "/"
-	url := navReq.URL.NoQueryUrl() + "robots.txt"
+/*
+*
+探测站点的OpenAPI/Swagger文档，将其中的接口展开为请求
+*/
+func GetPathsFromOpenAPI(navReq model.Request, catalog *openapi.Catalog) []*model.Request {
+	var result []*model.Request
+	fetch := func(url string) (int, []byte, error) {
+		resp, err := requests.Get(url, tools.ConvertHeaders(navReq.Headers),
+			&requests.ReqOptions{AllowRedirect: true,
+				Timeout: 5,
+				Proxy:   navReq.Proxy})
+		if err != nil {
+			return 0, nil, err
+		}
+		return resp.StatusCode, []byte(resp.Text), nil
+	}
 
-	resp, err := requests.Get(url, tools.ConvertHeaders(navReq.Headers),
-		&requests.ReqOptions{AllowRedirect: false,
-			Timeout: 5,
-			Proxy:   navReq.Proxy})
-	if err != nil {
-		return result
+	for _, document := range openapi.Discover(navReq.URL.String(), fetch) {
+		for _, request := range catalog.Add(document) {
+			url, err := model.GetUrl(request.URL, *navReq.URL)
+			if err != nil {
+				continue
+			}
+			headers := map[string]interface{}{}
+			for key, value := range navReq.Headers {
+				headers[key] = value
+			}
+			for key, value := range request.Headers {
+				headers[key] = value
+			}
+			req := model.GetRequest(request.Method, url, model.Options{Headers: headers, PostData: request.Body})
+			req.Source = config.FromOpenAPI
+			result = append(result, &req)
+		}
 	}
+	return result
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return result
+/*
+*
+探测常见位置的WSDL/WADL服务描述，为每个操作生成示例请求
+*/
+func GetPathsFromWSDL(navReq model.Request, catalog *wsdl.Catalog) []*model.Request {
+	var result []*model.Request
+	fetch := func(url string) (int, []byte, error) {
+		resp, err := requests.Get(url, tools.ConvertHeaders(navReq.Headers),
+			&requests.ReqOptions{AllowRedirect: true,
+				Timeout: 5,
+				Proxy:   navReq.Proxy})
+		if err != nil {
+			return 0, nil, err
+		}
+		return resp.StatusCode, []byte(resp.Text), nil
 	}
-	urlList := urlFindRegex.FindAllString(resp.Text, -1)
-	for _, _url := range urlList {
-		_url = strings.TrimSpace(_url)
-		_url = urlRegex.FindString(_url)
-		url, err := model.GetUrl(_url, *navReq.URL)
+
+	for _, operation := range catalog.Probe(navReq.URL.String(), fetch) {
+		url, err := model.GetUrl(operation.Request.URL, *navReq.URL)
 		if err != nil {
 			continue
 		}
-		req := model.GetRequest(config.GET, url)
-		req.Source = config.FromRobots
+		headers := map[string]interface{}{}
+		for key, value := range navReq.Headers {
+			headers[key] = value
+		}
+		for key, value := range operation.Request.Headers {
+			headers[key] = value
+		}
+		req := model.GetRequest(operation.Request.Method, url, model.Options{Headers: headers, PostData: operation.Request.Body})
+		req.Source = config.FromWSDL
 		result = append(result, &req)
 	}
 	return result
@@ -77,52 +158,40 @@ func GetPathsFromRobots(navReq model.Request) []*model.Request {
 
 /*
 *
-使用常见路径列表进行fuzz
+使用常见路径列表进行fuzz，与不存在路径的响应相同的软404结果会被丢弃
 */
-func GetPathsByFuzz(navReq model.Request) []*model.Request {
+func GetPathsByFuzz(navReq model.Request, options pathfuzz.Options) []*model.Request {
 	pathList := strings.Split(pathStr, "/")
-	return doFuzz(navReq, pathList)
+	return doFuzz(navReq, pathList, options)
 }
 
 /*
 *
-使用字典列表进行fuzz
+使用字典列表进行fuzz，与不存在路径的响应相同的软404结果会被丢弃
 */
-func GetPathsByFuzzDict(navReq model.Request, dictPath string) []*model.Request {
+func GetPathsByFuzzDict(navReq model.Request, dictPath string, options pathfuzz.Options) []*model.Request {
 	pathList := tools.ReadFile(dictPath)
-	return doFuzz(navReq, pathList)
-}
-
-type singleFuzz struct {
-	navReq model.Request
-	path   string
+	return doFuzz(navReq, pathList, options)
 }
 
-func doFuzz(navReq model.Request, pathList []string) []*model.Request {
-	validateUrl = mapset.NewSet()
-	var result []*model.Request
-	pool, _ := ants.NewPool(20)
-	defer pool.Release()
-	for _, path := range pathList {
-		path = strings.TrimPrefix(path, "/")
-		path = strings.TrimSuffix(path, "\n")
-		task := singleFuzz{
-			navReq: navReq,
-			path:   path,
+/*
+*
+按配置递归fuzz发现的目录，并请求发现文件的备份文件，不跟随重定向
+*/
+func doFuzz(navReq model.Request, pathList []string, options pathfuzz.Options) []*model.Request {
+	headers := tools.ConvertHeaders(navReq.Headers)
+	fetch := func(url string) (pathfuzz.Response, error) {
+		resp, err := requests.Get(url, headers,
+			&requests.ReqOptions{Timeout: 2, AllowRedirect: false, Proxy: navReq.Proxy})
+		if err != nil {
+			return pathfuzz.Response{}, err
 		}
-		pathFuzzWG.Add(1)
-		go func() {
-			err := pool.Submit(task.doRequest)
-			if err != nil {
-				pathFuzzWG.Done()
-			}
-		}()
+		return pathfuzz.Response{StatusCode: resp.StatusCode, Location: resp.Header.Get("Location"), Body: resp.Text}, nil
 	}
 
-	pathFuzzWG.Wait()
-	for _, _url := range validateUrl.ToSlice() {
-		_url := _url.(string)
-		url, err := model.GetUrl(_url)
+	var result []*model.Request
+	for _, hit := range pathfuzz.Run(navReq.URL.String(), pathList, options, fetch) {
+		url, err := model.GetUrl(hit.URL)
 		if err != nil {
 			continue
 		}
@@ -132,33 +201,3 @@ func doFuzz(navReq model.Request, pathList []string) []*model.Request {
 	}
 	return result
 }
-
-/*
-*
- */
-func (s singleFuzz) doRequest() {
-	defer pathFuzzWG.Done()
-
-	url := fmt.Sprintf(`%s://%s/%s`, s.navReq.URL.Scheme, s.navReq.URL.Host, s.path)
-	resp, errs := requests.Get(url, tools.ConvertHeaders(s.navReq.Headers),
-		&requests.ReqOptions{Timeout: 2, AllowRedirect: false, Proxy: s.navReq.Proxy})
-	if errs != nil {
-		return
-	}
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		validateUrl.Add(url)
-	} else if resp.StatusCode == 301 {
-		locations := resp.Header["Location"]
-		if len(locations) == 0 {
-			return
-		}
-		location := locations[0]
-		redirectUrl, err := model.GetUrl(location)
-		if err != nil {
-			return
-		}
-		if redirectUrl.Host == s.navReq.URL.Host {
-			validateUrl.Add(url)
-		}
-	}
-}
@@ -5,6 +5,9 @@ import (
 	"Venom-Crawler/pkg/crawlergo/engine"
 	filter3 "Venom-Crawler/pkg/crawlergo/filter"
 	"Venom-Crawler/pkg/crawlergo/model"
+	"Venom-Crawler/pkg/jsextract"
+	"Venom-Crawler/pkg/session"
+	"Venom-Crawler/pkg/soft404"
 	"encoding/json"
 	"github.com/ttacon/chalk"
 	"log"
@@ -92,6 +95,9 @@ func NewCrawlerTask(targets []*model.Request, taskConf TaskConfig) (*CrawlerTask
 		WithDomContentLoadedTimeout(config.DomContentLoadedTimeout),
 		WithEventTriggerInterval(config.EventTriggerInterval),
 		WithBeforeExitDelay(config.BeforeExitDelay),
+		WithNetworkQuietTime(config.NetworkQuietTime),
+		WithNetworkIdleMaxWait(config.NetworkIdleMaxWait),
+		WithMaxScrollTimes(config.MaxScrollTimes),
 		WithEventTriggerMode(config.DefaultEventTriggerMode),
 		WithIgnoreKeywords(config.DefaultIgnoreKeywords),
 	} {
@@ -109,8 +115,9 @@ func NewCrawlerTask(targets []*model.Request, taskConf TaskConfig) (*CrawlerTask
 	if len(taskConf.ChromiumWSUrl) > 0 {
 		crawlerTask.Browser = engine.ConnectBrowser(taskConf.ChromiumWSUrl, taskConf.ExtraHeaders)
 	} else {
-		crawlerTask.Browser = engine.InitBrowser(taskConf.ChromiumPath, taskConf.ExtraHeaders, taskConf.Proxy, taskConf.NoHeadless)
+		crawlerTask.Browser = engine.InitBrowser(taskConf.ChromiumPath, taskConf.ExtraHeaders, taskConf.Proxy, taskConf.NoHeadless, taskConf.Resolve.HostResolverRules())
 	}
+	crawlerTask.Browser.Session = taskConf.Session
 	crawlerTask.RootDomain = targets[0].URL.RootDomain()
 
 	// 创建协程池
@@ -146,14 +153,26 @@ func (t *CrawlerTask) Run() {
 		reqsFromRobots := GetPathsFromRobots(*t.Targets[0])
 		t.Targets = append(t.Targets, reqsFromRobots...)
 	}
+	if t.Config.WellKnown != nil {
+		reqsFromWellKnown := GetPathsFromWellKnown(*t.Targets[0], t.Config.WellKnown)
+		t.Targets = append(t.Targets, reqsFromWellKnown...)
+	}
+	if t.Config.OpenAPI != nil {
+		reqsFromOpenAPI := GetPathsFromOpenAPI(*t.Targets[0], t.Config.OpenAPI)
+		t.Targets = append(t.Targets, reqsFromOpenAPI...)
+	}
+	if t.Config.Services != nil {
+		reqsFromWSDL := GetPathsFromWSDL(*t.Targets[0], t.Config.Services)
+		t.Targets = append(t.Targets, reqsFromWSDL...)
+	}
 
+	fuzzOptions := t.Config.PathFuzz
+	fuzzOptions.Soft404 = t.Config.Soft404
 	if t.Config.FuzzDictPath != "" {
-		if t.Config.PathByFuzz {
-		}
-		reqsByFuzz := GetPathsByFuzzDict(*t.Targets[0], t.Config.FuzzDictPath)
+		reqsByFuzz := GetPathsByFuzzDict(*t.Targets[0], t.Config.FuzzDictPath, fuzzOptions)
 		t.Targets = append(t.Targets, reqsByFuzz...)
 	} else if t.Config.PathByFuzz {
-		reqsByFuzz := GetPathsByFuzz(*t.Targets[0])
+		reqsByFuzz := GetPathsByFuzz(*t.Targets[0], fuzzOptions)
 		t.Targets = append(t.Targets, reqsByFuzz...)
 	}
 
@@ -176,6 +195,12 @@ func (t *CrawlerTask) Run() {
 
 	t.taskWG.Wait()
 
+	// 丢弃软404页面
+	if t.Config.Soft404.Dropping() {
+		t.Result.ReqList = dropSoft404(t.Result.ReqList, t.Config.Soft404)
+		t.Result.AllReqList = dropSoft404(t.Result.AllReqList, t.Config.Soft404)
+	}
+
 	// 对全部请求进行唯一去重
 	todoFilterAll := make([]*model.Request, len(t.Result.AllReqList))
 	copy(todoFilterAll, t.Result.AllReqList)
@@ -194,6 +219,34 @@ func (t *CrawlerTask) Run() {
 	t.Result.SubDomainList = SubDomainCollect(t.Result.AllReqList, t.RootDomain)
 }
 
+/*
+*
+移除被判定为软404的请求
+*/
+func dropSoft404(reqList []*model.Request, detector *soft404.Detector) []*model.Request {
+	var result []*model.Request
+	for _, req := range reqList {
+		if req.Method == config.GET && detector.Matched(req.URL.String()) {
+			continue
+		}
+		result = append(result, req)
+	}
+	return result
+}
+
+/*
+*
+JS及源码映射中解析出的接口按推断的方法记录到结果中，
+会修改目标数据的非GET接口只有开启JSReplay时才爬行
+*/
+func (t *CrawlerTask) replayable(req *model.Request) bool {
+	switch req.Source {
+	case config.FromJSFile, config.FromJSChunk, config.FromSourceMap:
+		return t.Config.JSReplay || jsextract.Safe(req.Method)
+	}
+	return true
+}
+
 /*
 *
 添加任务到协程池
@@ -214,6 +267,14 @@ func (t *CrawlerTask) addTask2Pool(req *model.Request) {
 	}
 	t.taskCountLock.Unlock()
 
+	t.submitTask(req)
+}
+
+/*
+*
+提交任务到协程池，不计入爬取数量
+*/
+func (t *CrawlerTask) submitTask(req *model.Request) {
 	t.taskWG.Add(1)
 	task := t.generateTabTask(req)
 	go func() {
@@ -243,19 +304,49 @@ func (t *tabTask) Task() {
 		return
 	}
 
+	generation := t.crawlerTask.Config.SessionMonitor.Wait()
 	tab := engine.NewTab(t.browser, *t.req, engine.TabConfig{
 		TabRunTimeout:           tabTime,
 		DomContentLoadedTimeout: t.crawlerTask.Config.DomContentLoadedTimeout,
 		EventTriggerMode:        t.crawlerTask.Config.EventTriggerMode,
 		EventTriggerInterval:    t.crawlerTask.Config.EventTriggerInterval,
 		BeforeExitDelay:         t.crawlerTask.Config.BeforeExitDelay,
+		NetworkQuietTime:        t.crawlerTask.Config.NetworkQuietTime,
+		NetworkIdleMaxWait:      t.crawlerTask.Config.NetworkIdleMaxWait,
+		MaxScrollTimes:          t.crawlerTask.Config.MaxScrollTimes,
+		ScrapeJSChunks:          t.crawlerTask.Config.ScrapeJSChunks,
 		EncodeURLWithCharset:    t.crawlerTask.Config.EncodeURLWithCharset,
 		IgnoreKeywords:          t.crawlerTask.Config.IgnoreKeywords,
 		CustomFormValues:        t.crawlerTask.Config.CustomFormValues,
 		CustomFormKeywordValues: t.crawlerTask.Config.CustomFormKeywordValues,
+		Credentials:             t.crawlerTask.Config.Credentials,
+		SessionMonitor:          t.crawlerTask.Config.SessionMonitor,
+		Rewrite:                 t.crawlerTask.Config.Rewrite,
+		Block:                   t.crawlerTask.Config.Block,
+		SourceMaps:              t.crawlerTask.Config.SourceMaps,
+		JSArchive:               t.crawlerTask.Config.JSArchive,
+		GraphQL:                 t.crawlerTask.Config.GraphQL,
+		Services:                t.crawlerTask.Config.Services,
+		Soft404:                 t.crawlerTask.Config.Soft404,
 	})
 	tab.Start()
 
+	// 会话失效 重新认证后重新入队，丢弃登录页的结果
+	if tab.SessionLost && t.req.ReauthCount < session.MaxReauthRetries {
+		log.Println(chalk.Yellow.Color("检测到会话失效, 重新认证: " + t.req.URL.String()))
+		if err := t.crawlerTask.Config.SessionMonitor.Reauthenticate(generation); err != nil {
+			log.Println(chalk.Red.Color("error: 重新认证失败, " + err.Error()))
+		}
+		t.req.ReauthCount++
+		t.crawlerTask.submitTask(t.req)
+		return
+	}
+
+	// 软404页面的结果需要丢弃时，不收集页面中的链接
+	if tab.Soft404 && t.crawlerTask.Config.Soft404.Dropping() {
+		return
+	}
+
 	// 收集结果
 	t.crawlerTask.Result.resultLock.Lock()
 	t.crawlerTask.Result.AllReqList = append(t.crawlerTask.Result.AllReqList, tab.ResultList...)
@@ -266,7 +357,7 @@ func (t *tabTask) Task() {
 			t.crawlerTask.Result.resultLock.Lock()
 			t.crawlerTask.Result.ReqList = append(t.crawlerTask.Result.ReqList, req)
 			t.crawlerTask.Result.resultLock.Unlock()
-			if !engine.IsIgnoredByKeywordMatch(*req, t.crawlerTask.Config.IgnoreKeywords) {
+			if !engine.IsIgnoredByKeywordMatch(*req, t.crawlerTask.Config.IgnoreKeywords) && t.crawlerTask.replayable(req) {
 				t.crawlerTask.addTask2Pool(req)
 			}
 		}
@@ -1,6 +1,21 @@
 package crawlergo
 
-import "time"
+import (
+	"Venom-Crawler/pkg/auth"
+	"Venom-Crawler/pkg/block"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
+	"Venom-Crawler/pkg/openapi"
+	"Venom-Crawler/pkg/pathfuzz"
+	"Venom-Crawler/pkg/resolve"
+	"Venom-Crawler/pkg/rewrite"
+	"Venom-Crawler/pkg/session"
+	"Venom-Crawler/pkg/soft404"
+	"Venom-Crawler/pkg/sourcemap"
+	"Venom-Crawler/pkg/wellknown"
+	"Venom-Crawler/pkg/wsdl"
+	"time"
+)
 
 type TaskConfig struct {
 	MaxCrawlCount           int    // 最大爬取的数量
@@ -14,6 +29,7 @@ type TaskConfig struct {
 	TabRunTimeout           time.Duration     // 单个标签页超时
 	PathByFuzz              bool              // 通过字典进行Path Fuzz
 	FuzzDictPath            string            //Fuzz目录字典
+	PathFuzz                pathfuzz.Options  // Path Fuzz的递归深度、扩展名、备份文件、速率和过滤配置
 	PathFromRobots          bool              // 解析Robots文件找出路径
 	MaxTabsCount            int               // 允许开启的最大标签页数量 即同时爬取的数量
 	ChromiumPath            string            // Chromium的程序路径  `/home/zhusiyu1/chrome-linux/chrome`
@@ -21,6 +37,11 @@ type TaskConfig struct {
 	EventTriggerMode        string            // 事件触发的调用方式： 异步 或 顺序
 	EventTriggerInterval    time.Duration     // 事件触发的间隔
 	BeforeExitDelay         time.Duration     // 退出前的等待时间，等待DOM渲染，等待XHR发出捕获
+	NetworkQuietTime        time.Duration     // 网络空闲判定时长，小于0时关闭网络空闲检测，使用固定等待
+	NetworkIdleMaxWait      time.Duration     // 等待网络空闲的最长时间
+	MaxScrollTimes          int               // 滚动页面加载更多内容的最大轮数，小于0时不滚动
+	ScrapeJSChunks          bool              // 获取并解析JS按需加载的分块
+	JSReplay                bool              // 爬行JS中解析出的POST、PUT、DELETE等非GET接口，否则只记录
 	EncodeURLWithCharset    bool              // 使用检测到的字符集自动编码URL
 	IgnoreKeywords          []string          // 忽略的关键字，匹配上之后将不再扫描且不发送请求
 	Proxy                   string            // 请求代理
@@ -29,6 +50,19 @@ type TaskConfig struct {
 	MaxRunTime              int64             // 最大爬取时间(单位秒），超时则结束任务，平滑结束（比如某个url还未处理完不能结束，需要一次req完成后才可以结束整个任务）
 	URL                     string
 	URLList                 []string
+	Credentials             *auth.Store         // 401/407 认证凭据
+	Session                 *session.State      // 登录后的会话状态
+	SessionMonitor          *session.Monitor    // 会话失效检测与重新认证
+	Rewrite                 *rewrite.RuleSet    // 请求/响应重写规则
+	Block                   *block.Policy       // 请求拦截策略
+	Resolve                 *resolve.Map        // 自定义域名解析 host:port:ip
+	SourceMaps              *sourcemap.Recorder // JS源码映射记录，为空时不获取源码映射
+	JSArchive               *jsarchive.Archive  // JS文件归档，为空时不保存
+	OpenAPI                 *openapi.Catalog    // OpenAPI/Swagger文档发现，为空时不探测
+	GraphQL                 *graphql.Recorder   // GraphQL操作记录，为空时不识别GraphQL
+	Services                *wsdl.Catalog       // WSDL/WADL服务描述记录，为空时不枚举服务
+	WellKnown               *wellknown.Recorder // 常见文件(robots.txt、站点地图、security.txt等)记录，为空时不获取
+	Soft404                 *soft404.Detector   // 软404检测，为空时不检测
 }
 
 type TaskConfigOptFunc func(*TaskConfig)
@@ -167,6 +201,27 @@ func WithBeforeExitDelay(gen time.Duration) TaskConfigOptFunc {
 		}
 	}
 }
+func WithNetworkQuietTime(gen time.Duration) TaskConfigOptFunc {
+	return func(tc *TaskConfig) {
+		if tc.NetworkQuietTime == 0 {
+			tc.NetworkQuietTime = gen
+		}
+	}
+}
+func WithNetworkIdleMaxWait(gen time.Duration) TaskConfigOptFunc {
+	return func(tc *TaskConfig) {
+		if tc.NetworkIdleMaxWait == 0 {
+			tc.NetworkIdleMaxWait = gen
+		}
+	}
+}
+func WithMaxScrollTimes(gen int) TaskConfigOptFunc {
+	return func(tc *TaskConfig) {
+		if tc.MaxScrollTimes == 0 {
+			tc.MaxScrollTimes = gen
+		}
+	}
+}
 func WithEncodeURLWithCharset(gen bool) TaskConfigOptFunc {
 	return func(tc *TaskConfig) {
 		if !tc.EncodeURLWithCharset {
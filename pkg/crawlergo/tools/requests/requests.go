@@ -2,8 +2,10 @@ package requests
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -37,6 +39,9 @@ type ReqInfo struct {
 	Body    []byte
 }
 
+// DialContext 自定义拨号函数，为空时使用默认拨号，用于自定义域名解析
+var DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
 type ReqOptions struct {
 	Timeout       int    // in seconds
 	Retry         int    // 0为默认值，-1 代表关闭不retry
@@ -62,6 +67,7 @@ func getSessionByOptions(options *ReqOptions) *session {
 	}
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{InsecureSkipVerify: !options.VerifySSL},
+		DialContext:     DialContext,
 	}
 	if options.Proxy != "" {
 		proxyUrl, err := url.Parse(options.Proxy)
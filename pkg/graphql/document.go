@@ -0,0 +1,421 @@
+package graphql
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// token kinds of the lexer
+const (
+	punctuator = iota + 1
+	name
+	stringValue
+	number
+)
+
+type token struct {
+	kind  int
+	text  string
+	start int
+	end   int
+}
+
+// operationTypes are the keywords starting an operation definition
+var operationTypes = map[string]struct{}{"query": {}, "mutation": {}, "subscription": {}}
+
+// lex splits a GraphQL document into tokens, commas and comments are
+// insignificant and dropped
+func lex(document string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(document) && document[i] != '\n' && document[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(document[i:], "\xef\xbb\xbf"):
+			i += 3
+		case strings.HasPrefix(document[i:], "..."):
+			tokens = append(tokens, token{kind: punctuator, text: "...", start: i, end: i + 3})
+			i += 3
+		case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+			tokens = append(tokens, token{kind: punctuator, text: string(c), start: i, end: i + 1})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(document) && (document[i] == '_' || isLetter(document[i]) || isDigit(document[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: name, text: document[start:i], start: start, end: i})
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+			for i < len(document) && (isDigit(document[i]) || strings.IndexByte(".eE+-", document[i]) >= 0) {
+				i++
+			}
+			tokens = append(tokens, token{kind: number, text: document[start:i], start: start, end: i})
+		case strings.HasPrefix(document[i:], `"""`):
+			end := strings.Index(document[i+3:], `"""`)
+			if end < 0 {
+				return nil, errors.New("unterminated block string")
+			}
+			end += i + 6
+			tokens = append(tokens, token{kind: stringValue, text: document[i:end], start: i, end: end})
+			i = end
+		case c == '"':
+			start := i
+			for i++; i < len(document) && document[i] != '"'; i++ {
+				if document[i] == '\\' {
+					i++
+				} else if document[i] == '\n' {
+					return nil, errors.New("unterminated string")
+				}
+			}
+			if i >= len(document) {
+				return nil, errors.New("unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{kind: stringValue, text: document[start:i], start: start, end: i})
+		default:
+			return nil, errors.New("unexpected character " + string(c))
+		}
+	}
+	return tokens, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// definition is an operation or fragment definition of a document
+type definition struct {
+	kind      string // query, mutation, subscription or fragment
+	name      string
+	text      string
+	variables []Variable
+	spreads   []string
+}
+
+// parser walks the tokens of a document
+type parser struct {
+	document string
+	tokens   []token
+	pos      int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) is(kind int, text string) bool {
+	t := p.peek()
+	return t != nil && t.kind == kind && t.text == text
+}
+
+func (p *parser) expect(kind int, text string) (*token, error) {
+	t := p.peek()
+	if t == nil || t.kind != kind || (text != "" && t.text != text) {
+		return nil, errors.New("unexpected token, expected " + text)
+	}
+	p.pos++
+	return t, nil
+}
+
+// ParseDocument returns the operations of an executable GraphQL document.
+// The query of each operation is self-contained, the fragments it spreads
+// are appended to it. Documents without operations are invalid.
+func ParseDocument(document string) ([]Operation, error) {
+	definitions, err := parseDefinitions(document)
+	if err != nil {
+		return nil, err
+	}
+	fragments := make(map[string]*definition)
+	for _, d := range definitions {
+		if d.kind == "fragment" {
+			fragments[d.name] = d
+		}
+	}
+	var operations []Operation
+	for _, d := range definitions {
+		if d.kind == "fragment" {
+			continue
+		}
+		operations = append(operations, Operation{
+			Type:      d.kind,
+			Name:      d.name,
+			Query:     withFragments(d, fragments),
+			Variables: d.variables,
+		})
+	}
+	if len(operations) == 0 {
+		return nil, errors.New("no operation in document")
+	}
+	return operations, nil
+}
+
+// parseDefinitions returns the operation and fragment definitions of a document
+func parseDefinitions(document string) ([]*definition, error) {
+	tokens, err := lex(document)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{document: document, tokens: tokens}
+	var definitions []*definition
+	for p.peek() != nil {
+		d, err := p.definition()
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, d)
+	}
+	if len(definitions) == 0 {
+		return nil, errors.New("empty document")
+	}
+	return definitions, nil
+}
+
+func (p *parser) definition() (*definition, error) {
+	start := p.peek()
+	d := &definition{kind: "query"}
+	if start.kind == name {
+		switch _, ok := operationTypes[start.text]; {
+		case ok:
+			d.kind = start.text
+			p.pos++
+			if t := p.peek(); t != nil && t.kind == name {
+				d.name = t.text
+				p.pos++
+			}
+			if p.is(punctuator, "(") {
+				variables, err := p.variableDefinitions()
+				if err != nil {
+					return nil, err
+				}
+				d.variables = variables
+			}
+		case start.text == "fragment":
+			d.kind = "fragment"
+			p.pos++
+			fragment, err := p.expect(name, "")
+			if err != nil {
+				return nil, err
+			}
+			d.name = fragment.text
+			if _, err := p.expect(name, "on"); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(name, ""); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("not an executable definition: " + start.text)
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+	}
+	spreads, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	d.spreads = spreads
+	d.text = p.document[start.start:p.tokens[p.pos-1].end]
+	return d, nil
+}
+
+// variableDefinitions parses ($name: Type = default @directive ...)
+func (p *parser) variableDefinitions() ([]Variable, error) {
+	p.pos++
+	var variables []Variable
+	for !p.is(punctuator, ")") {
+		if _, err := p.expect(punctuator, "$"); err != nil {
+			return nil, err
+		}
+		variable, err := p.expect(name, "")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(punctuator, ":"); err != nil {
+			return nil, err
+		}
+		typeName, err := p.typeReference()
+		if err != nil {
+			return nil, err
+		}
+		if p.is(punctuator, "=") {
+			p.pos++
+			if err := p.value(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+		variables = append(variables, Variable{Name: variable.text, Type: typeName, Example: exampleValue(typeName, nil)})
+	}
+	p.pos++
+	return variables, nil
+}
+
+// typeReference parses a named, list or non null type and returns its text
+func (p *parser) typeReference() (string, error) {
+	var typeName string
+	if p.is(punctuator, "[") {
+		p.pos++
+		item, err := p.typeReference()
+		if err != nil {
+			return "", err
+		}
+		if _, err := p.expect(punctuator, "]"); err != nil {
+			return "", err
+		}
+		typeName = "[" + item + "]"
+	} else {
+		t, err := p.expect(name, "")
+		if err != nil {
+			return "", err
+		}
+		typeName = t.text
+	}
+	if p.is(punctuator, "!") {
+		p.pos++
+		typeName += "!"
+	}
+	return typeName, nil
+}
+
+// value skips a constant or variable value
+func (p *parser) value() error {
+	t := p.peek()
+	if t == nil {
+		return errors.New("unexpected end of document")
+	}
+	switch {
+	case t.kind == punctuator && t.text == "$":
+		p.pos++
+		_, err := p.expect(name, "")
+		return err
+	case t.kind == punctuator && (t.text == "[" || t.text == "{"):
+		return p.skipBalanced()
+	case t.kind == punctuator:
+		return errors.New("unexpected " + t.text)
+	}
+	p.pos++
+	return nil
+}
+
+// directives skips the directives @name(arguments)
+func (p *parser) directives() error {
+	for p.is(punctuator, "@") {
+		p.pos++
+		if _, err := p.expect(name, ""); err != nil {
+			return err
+		}
+		if p.is(punctuator, "(") {
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectionSet skips a selection set and returns the fragments it spreads
+func (p *parser) selectionSet() ([]string, error) {
+	if !p.is(punctuator, "{") {
+		return nil, errors.New("expected selection set")
+	}
+	start := p.pos
+	if err := p.skipBalanced(); err != nil {
+		return nil, err
+	}
+	if p.pos-start < 3 {
+		return nil, errors.New("empty selection set")
+	}
+	var spreads []string
+	for i := start; i < p.pos-1; i++ {
+		if p.tokens[i].text == "..." && p.tokens[i+1].kind == name && p.tokens[i+1].text != "on" {
+			spreads = append(spreads, p.tokens[i+1].text)
+		}
+	}
+	return spreads, nil
+}
+
+// skipBalanced skips from an opening bracket to its closing bracket
+func (p *parser) skipBalanced() error {
+	var stack []string
+	closing := map[string]string{"{": "}", "(": ")", "[": "]"}
+	for t := p.peek(); t != nil; t = p.peek() {
+		p.pos++
+		if t.kind != punctuator {
+			continue
+		}
+		if close, ok := closing[t.text]; ok {
+			stack = append(stack, close)
+			continue
+		}
+		if t.text == "}" || t.text == ")" || t.text == "]" {
+			if len(stack) == 0 || stack[len(stack)-1] != t.text {
+				return errors.New("unbalanced " + t.text)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return nil
+			}
+		}
+	}
+	return errors.New("unexpected end of document")
+}
+
+// withFragments appends the fragments an operation spreads, directly or
+// through other fragments, to its text
+func withFragments(operation *definition, fragments map[string]*definition) string {
+	used := make(map[string]struct{})
+	queue := append([]string{}, operation.spreads...)
+	for len(queue) > 0 {
+		fragment := queue[0]
+		queue = queue[1:]
+		if _, ok := used[fragment]; ok {
+			continue
+		}
+		if d, ok := fragments[fragment]; ok {
+			used[fragment] = struct{}{}
+			queue = append(queue, d.spreads...)
+		}
+	}
+	names := make([]string, 0, len(used))
+	for fragment := range used {
+		names = append(names, fragment)
+	}
+	sort.Strings(names)
+	text := operation.text
+	for _, fragment := range names {
+		text += "\n\n" + fragments[fragment].text
+	}
+	return text
+}
+
+// normalize returns the tokens of a document separated by single spaces,
+// so queries differing only by formatting compare equal
+func normalize(document string) string {
+	tokens, err := lex(document)
+	if err != nil {
+		return strings.Join(strings.Fields(document), " ")
+	}
+	texts := make([]string, len(tokens))
+	for i, t := range tokens {
+		texts[i] = t.text
+	}
+	return strings.Join(texts, " ")
+}
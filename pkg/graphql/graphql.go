@@ -0,0 +1,192 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Sources of the operations recorded
+const (
+	SourceTraffic       = "traffic"
+	SourceScript        = "script"
+	SourceIntrospection = "introspection"
+)
+
+// dangerRegex matches the names of the operations which look like
+// destructive actions, the words of the elements the hybrid engine does not
+// click and the camel case names of the schemas
+var dangerRegex = regexp.MustCompile(`(?i)log_?out|sign_?out|delete|remove|destroy|drop|purge|reset|revoke|disable|unsubscribe|cancel|注销|退出|删除`)
+
+// Dangerous returns true if the name of an operation looks like a
+// destructive action
+func Dangerous(name string) bool {
+	return dangerRegex.MatchString(name)
+}
+
+// Operation is a GraphQL operation sent to an endpoint
+type Operation struct {
+	Endpoint string `json:"endpoint"`
+	// Type is query, mutation or subscription
+	Type      string     `json:"type"`
+	Name      string     `json:"name,omitempty"`
+	Query     string     `json:"query,omitempty"`
+	Variables []Variable `json:"variables,omitempty"`
+	// Hash is the sha256 of a persisted query sent without its document
+	Hash   string `json:"sha256,omitempty"`
+	Source string `json:"source"`
+}
+
+// Variable is a variable of an operation with its GraphQL type
+type Variable struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Example is the value sent for the variable, observed or generated
+	Example interface{} `json:"-"`
+}
+
+// Key identifies an operation regardless of its formatting and variables
+func (o Operation) Key() string {
+	document := o.Hash
+	if o.Query != "" {
+		sum := sha256.Sum256([]byte(normalize(o.Query)))
+		document = hex.EncodeToString(sum[:8])
+	}
+	return o.Type + " " + o.Name + " " + document
+}
+
+// Body returns the JSON body of a POST request sending the operation
+func (o Operation) Body() string {
+	body := map[string]interface{}{}
+	if o.Name != "" {
+		body["operationName"] = o.Name
+	}
+	if o.Query != "" {
+		body["query"] = o.Query
+	} else if o.Hash != "" {
+		body["extensions"] = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": o.Hash},
+		}
+	}
+	variables := map[string]interface{}{}
+	for _, variable := range o.Variables {
+		variables[variable.Name] = variable.Example
+	}
+	body["variables"] = variables
+	data, _ := json.Marshal(body)
+	return string(data)
+}
+
+// payload is the body of a GraphQL request over HTTP
+type payload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery struct {
+			Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// ParseRequest returns the operations sent by an HTTP request, nil if it
+// is not a GraphQL request. JSON bodies, batched bodies, GET requests with
+// a query parameter and application/graphql bodies are recognized.
+func ParseRequest(method string, rawURL string, body string) []Operation {
+	endpoint := rawURL
+	var payloads []payload
+	body = strings.TrimSpace(body)
+	switch {
+	case strings.EqualFold(method, "GET"):
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil
+		}
+		query := parsed.Query()
+		p := payload{Query: query.Get("query"), OperationName: query.Get("operationName")}
+		_ = json.Unmarshal([]byte(query.Get("variables")), &p.Variables)
+		_ = json.Unmarshal([]byte(query.Get("extensions")), &p.Extensions)
+		payloads = append(payloads, p)
+		parsed.RawQuery = ""
+		endpoint = parsed.String()
+	case strings.HasPrefix(body, "["):
+		if json.Unmarshal([]byte(body), &payloads) != nil {
+			return nil
+		}
+	case strings.HasPrefix(body, "{"):
+		p := payload{}
+		if json.Unmarshal([]byte(body), &p) != nil {
+			// an application/graphql body holding an anonymous query
+			p = payload{Query: body}
+		}
+		payloads = append(payloads, p)
+	default:
+		payloads = append(payloads, payload{Query: body})
+	}
+
+	var operations []Operation
+	for _, p := range payloads {
+		operations = append(operations, p.operations(endpoint)...)
+	}
+	return operations
+}
+
+// operations returns the operations of a request payload, only the named
+// operation is sent when a document holds several
+func (p payload) operations(endpoint string) []Operation {
+	if p.Query == "" {
+		if p.Extensions.PersistedQuery.Hash == "" {
+			return nil
+		}
+		operation := Operation{Endpoint: endpoint, Type: "query", Name: p.OperationName, Hash: p.Extensions.PersistedQuery.Hash, Source: SourceTraffic}
+		operation.Variables = observedVariables(nil, p.Variables)
+		return []Operation{operation}
+	}
+	parsed, err := ParseDocument(p.Query)
+	if err != nil {
+		return nil
+	}
+	var operations []Operation
+	for _, operation := range parsed {
+		if len(parsed) > 1 && p.OperationName != "" && operation.Name != p.OperationName {
+			continue
+		}
+		operation.Endpoint = endpoint
+		operation.Source = SourceTraffic
+		operation.Variables = observedVariables(operation.Variables, p.Variables)
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// observedVariables sets the examples of the declared variables to the
+// values sent, the variables of a persisted query are only known by value
+func observedVariables(declared []Variable, values map[string]interface{}) []Variable {
+	if declared == nil {
+		for name, value := range values {
+			declared = append(declared, Variable{Name: name, Example: value})
+		}
+		sortVariables(declared)
+		return declared
+	}
+	for i, variable := range declared {
+		if value, ok := values[variable.Name]; ok {
+			declared[i].Example = value
+		}
+	}
+	return declared
+}
+
+// RequestKey identifies the operations sent by an HTTP request, it is empty
+// if the request is not a GraphQL request
+func RequestKey(method string, rawURL string, body string) string {
+	operations := ParseRequest(method, rawURL, body)
+	keys := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		keys = append(keys, operation.Key())
+	}
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,196 @@
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDocument(t *testing.T) {
+	operations, err := ParseDocument(`
+# user page
+query GetUser($id: ID!, $first: Int = 10, $tags: [String!] @deprecated) {
+  user(id: $id) { ...UserFields posts(first: $first) { edges { node { id } } } }
+}
+mutation { logout }
+fragment UserFields on User { name ...Avatar }
+fragment Avatar on User { avatar(size: 64) }
+fragment Unused on User { id }`)
+	assert.Nil(t, err)
+	assert.Len(t, operations, 2)
+
+	assert.Equal(t, "query", operations[0].Type)
+	assert.Equal(t, "GetUser", operations[0].Name)
+	assert.Equal(t, []Variable{
+		{Name: "id", Type: "ID!", Example: "1"},
+		{Name: "first", Type: "Int", Example: 1},
+		{Name: "tags", Type: "[String!]", Example: []interface{}{"test"}},
+	}, operations[0].Variables)
+	assert.Equal(t, `query GetUser($id: ID!, $first: Int = 10, $tags: [String!] @deprecated) {
+  user(id: $id) { ...UserFields posts(first: $first) { edges { node { id } } } }
+}
+
+fragment Avatar on User { avatar(size: 64) }
+
+fragment UserFields on User { name ...Avatar }`, operations[0].Query, "should append the fragments spread, directly or not")
+
+	assert.Equal(t, Operation{Type: "mutation", Query: "mutation { logout }"}, operations[1])
+
+	_, err = ParseDocument(`type Query { user: User }`)
+	assert.NotNil(t, err, "should reject schema definitions")
+	_, err = ParseDocument(`fragment A on User { id }`)
+	assert.NotNil(t, err, "should reject documents without operations")
+	_, err = ParseDocument(`search term`)
+	assert.NotNil(t, err)
+}
+
+func TestParseRequest(t *testing.T) {
+	operations := ParseRequest("POST", "https://example.com/graphql",
+		`{"operationName":"B","query":"query A { a } query B($x: Int) { b(x: $x) }","variables":{"x":5}}`)
+	assert.Equal(t, []Operation{{
+		Endpoint: "https://example.com/graphql", Type: "query", Name: "B", Query: "query B($x: Int) { b(x: $x) }",
+		Variables: []Variable{{Name: "x", Type: "Int", Example: float64(5)}}, Source: SourceTraffic,
+	}}, operations, "should keep the operation named")
+
+	operations = ParseRequest("POST", "https://example.com/graphql",
+		`[{"query":"{ me { id } }"},{"operationName":"Feed","variables":{"page":2},"extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}}}]`)
+	assert.Len(t, operations, 2, "should split batched operations")
+	assert.Equal(t, "{ me { id } }", operations[0].Query)
+	assert.Equal(t, "abc", operations[1].Hash)
+	assert.Equal(t, []Variable{{Name: "page", Example: float64(2)}}, operations[1].Variables)
+
+	operations = ParseRequest("GET", "https://example.com/api/graphql?query=query%20Me%20%7B%20me%20%7B%20id%20%7D%20%7D&variables=%7B%7D", "")
+	assert.Len(t, operations, 1)
+	assert.Equal(t, "https://example.com/api/graphql", operations[0].Endpoint)
+	assert.Equal(t, "Me", operations[0].Name)
+
+	assert.Len(t, ParseRequest("POST", "https://example.com/graphql", "mutation Ping { ping }"), 1, "should parse application/graphql bodies")
+	assert.Nil(t, ParseRequest("POST", "https://example.com/login", `{"username":"admin","password":"admin"}`))
+	assert.Nil(t, ParseRequest("POST", "https://example.com/login", `username=admin&password=admin`))
+	assert.Nil(t, ParseRequest("GET", "https://example.com/search?query=shoes", ""))
+}
+
+func TestRequestKey(t *testing.T) {
+	a := RequestKey("POST", "https://example.com/graphql", `{"query":"query A { a }","variables":{"x":1}}`)
+	formatted := RequestKey("POST", "https://example.com/graphql", `{"query":"query A {\n  a\n}","variables":{"x":2}}`)
+	b := RequestKey("POST", "https://example.com/graphql", `{"query":"query B { b }"}`)
+	assert.NotEmpty(t, a)
+	assert.Equal(t, a, formatted, "should ignore the formatting and the variables")
+	assert.NotEqual(t, a, b)
+	assert.Empty(t, RequestKey("POST", "https://example.com/login", `{"username":"admin"}`))
+}
+
+func TestOperationBody(t *testing.T) {
+	operation := Operation{Name: "GetUser", Query: "query GetUser($id: ID!) { user(id: $id) { name } }",
+		Variables: []Variable{{Name: "id", Type: "ID!", Example: "1"}}}
+	assert.JSONEq(t, `{"operationName":"GetUser","query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}`, operation.Body())
+
+	persisted := Operation{Name: "Feed", Hash: "abc"}
+	assert.JSONEq(t, `{"operationName":"Feed","extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}},"variables":{}}`, persisted.Body())
+}
+
+func TestScanScript(t *testing.T) {
+	script := `
+import { ApolloClient, InMemoryCache, HttpLink } from "@apollo/client";
+const client = new ApolloClient({ link: new HttpLink({ uri: "/api/v2/graphql" }), cache: new InMemoryCache() });
+const wsLink = new WebSocketLink({ uri: "wss://example.com/subscriptions" });
+const USER_FIELDS = gql` + "`" + `
+  fragment UserFields on User { id name }
+` + "`" + `;
+const GET_USER = gql` + "`" + `
+  query GetUser($id: ID!) { user(id: $id) { ...UserFields } }
+  ${USER_FIELDS}
+` + "`" + `;
+var n={kind:"Document",loc:{source:{body:"mutation Like($post: ID!) {\n  like(post: $post)\n}\n"}}};
+fetch("https://cdn.example.com/gql", {method: "POST"});
+const label = "query string";
+`
+	operations, endpoints := ScanScript(script)
+	assert.Equal(t, []string{"/api/v2/graphql", "https://cdn.example.com/gql"}, endpoints)
+	assert.Len(t, operations, 2)
+	assert.Equal(t, "GetUser", operations[0].Name)
+	assert.Contains(t, operations[0].Query, "fragment UserFields on User { id name }", "should resolve fragments across templates")
+	assert.Equal(t, SourceScript, operations[0].Source)
+	assert.Equal(t, "mutation", operations[1].Type)
+	assert.Equal(t, "Like", operations[1].Name)
+	assert.Equal(t, "ID!", operations[1].Variables[0].Type)
+
+	operations, endpoints = ScanScript(`var uri = {uri: "/api"}; function query(){}`)
+	assert.Empty(t, operations)
+	assert.Empty(t, endpoints, "should only read the uri of Apollo links")
+}
+
+const introspectionResponse = `{"data":{"__schema":{
+  "queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},"subscriptionType":null,
+  "types":[
+    {"kind":"OBJECT","name":"Query","fields":[
+      {"name":"user","args":[{"name":"id","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID","ofType":null}}}],
+       "type":{"kind":"OBJECT","name":"User","ofType":null}},
+      {"name":"version","args":[],"type":{"kind":"SCALAR","name":"String","ofType":null}}]},
+    {"kind":"OBJECT","name":"Mutation","fields":[
+      {"name":"createUser","args":[{"name":"input","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"INPUT_OBJECT","name":"UserInput","ofType":null}}}],
+       "type":{"kind":"OBJECT","name":"User","ofType":null}}]},
+    {"kind":"OBJECT","name":"User","fields":[
+      {"name":"id","args":[],"type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID","ofType":null}}},
+      {"name":"role","args":[],"type":{"kind":"ENUM","name":"Role","ofType":null}},
+      {"name":"friends","args":[],"type":{"kind":"LIST","name":null,"ofType":{"kind":"OBJECT","name":"User","ofType":null}}}]},
+    {"kind":"INPUT_OBJECT","name":"UserInput","inputFields":[
+      {"name":"email","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"String","ofType":null}}},
+      {"name":"role","type":{"kind":"ENUM","name":"Role","ofType":null}},
+      {"name":"born","type":{"kind":"SCALAR","name":"Date","ofType":null}}]},
+    {"kind":"ENUM","name":"Role","enumValues":[{"name":"ADMIN"},{"name":"USER"}]},
+    {"kind":"SCALAR","name":"ID"},{"kind":"SCALAR","name":"String"},{"kind":"SCALAR","name":"Date"}]}}}`
+
+func TestIntrospect(t *testing.T) {
+	var sent map[string]string
+	operations, err := Introspect("https://example.com/graphql", func(url string, body []byte) (int, []byte, error) {
+		assert.Equal(t, "https://example.com/graphql", url)
+		assert.Nil(t, json.Unmarshal(body, &sent))
+		return 200, []byte(introspectionResponse), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, IntrospectionQuery, sent["query"])
+	assert.Len(t, operations, 3)
+
+	assert.Equal(t, "query user($id: ID!) { user(id: $id) { id role } }", operations[0].Query)
+	assert.Equal(t, []Variable{{Name: "id", Type: "ID!", Example: "1"}}, operations[0].Variables)
+	assert.Equal(t, "query version { version }", operations[1].Query)
+	assert.Equal(t, "mutation", operations[2].Type)
+	assert.Equal(t, map[string]interface{}{"email": "test", "role": "ADMIN", "born": "test"}, operations[2].Variables[0].Example)
+	assert.Equal(t, SourceIntrospection, operations[2].Source)
+
+	_, err = Introspect("https://example.com/graphql", func(string, []byte) (int, []byte, error) {
+		return 200, []byte(`{"errors":[{"message":"introspection is not allowed"}]}`), nil
+	})
+	assert.NotNil(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(true, false)
+	operation := Operation{Endpoint: "https://example.com/graphql", Type: "query", Name: "A", Query: "query A { a }"}
+	assert.True(t, recorder.Add(operation))
+	operation.Query = "query A {\n  a\n}"
+	assert.False(t, recorder.Add(operation))
+	operation.Endpoint = "https://example.com/admin/graphql"
+	assert.True(t, recorder.Add(operation))
+	assert.Len(t, recorder.Operations(), 2)
+	assert.Equal(t, "https://example.com/admin/graphql", recorder.Operations()[0].Endpoint)
+
+	assert.True(t, recorder.ClaimIntrospection("https://example.com/graphql"))
+	assert.False(t, recorder.ClaimIntrospection("https://example.com/graphql"))
+	assert.False(t, NewRecorder(false, false).ClaimIntrospection("https://example.com/graphql"))
+
+	mutation := Operation{Endpoint: "https://example.com/graphql", Type: "mutation", Name: "updateProfile", Source: SourceIntrospection}
+	destructive := Operation{Endpoint: "https://example.com/graphql", Type: "mutation", Name: "deleteUser", Source: SourceIntrospection}
+	assert.True(t, recorder.Replay(operation))
+	assert.False(t, recorder.Replay(mutation), "should only record the introspected mutations by default")
+	assert.True(t, NewRecorder(true, true).Replay(mutation))
+	assert.False(t, NewRecorder(true, true).Replay(destructive), "should never send the destructive mutations")
+	assert.True(t, recorder.Replay(Operation{Type: "mutation", Name: "deleteUser", Source: SourceTraffic}), "should split the batches sent by the page")
+
+	var disabled *Recorder
+	assert.False(t, disabled.Add(operation))
+	assert.False(t, disabled.Replay(operation))
+	assert.Nil(t, disabled.Operations())
+}
@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// IntrospectionQuery requests the root types of a schema with the fields,
+// arguments and input types needed to build operations
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: true) {
+        name
+        args { name type { ...TypeRef } }
+        type { ...TypeRef }
+      }
+      inputFields { name type { ...TypeRef } }
+      enumValues(includeDeprecated: true) { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } }
+}`
+
+// maxDepth bounds the nesting of generated input objects
+const maxDepth = 4
+
+// Schema is the result of an introspection query
+type Schema struct {
+	QueryType        *namedType `json:"queryType"`
+	MutationType     *namedType `json:"mutationType"`
+	SubscriptionType *namedType `json:"subscriptionType"`
+	Types            []*Type    `json:"types"`
+	types            map[string]*Type
+}
+
+type namedType struct {
+	Name string `json:"name"`
+}
+
+// Type is a type of an introspected schema
+type Type struct {
+	Kind        string  `json:"kind"`
+	Name        string  `json:"name"`
+	Fields      []Field `json:"fields"`
+	InputFields []Field `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+// Field is a field or an argument of a type
+type Field struct {
+	Name string   `json:"name"`
+	Args []Field  `json:"args"`
+	Type *TypeRef `json:"type"`
+}
+
+// TypeRef is a reference to a type, wrapped in lists and non null types
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// String returns the reference in GraphQL notation, e.g. [ID!]!
+func (t *TypeRef) String() string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// named returns the name of the type wrapped by the reference
+func (t *TypeRef) named() string {
+	for t != nil && t.OfType != nil {
+		t = t.OfType
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name
+}
+
+// ParseIntrospection parses the response of the introspection query
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var response struct {
+		Data struct {
+			Schema *Schema `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	schema := response.Data.Schema
+	if schema == nil || schema.QueryType == nil {
+		return nil, errors.New("introspection is disabled")
+	}
+	schema.types = make(map[string]*Type)
+	for _, t := range schema.Types {
+		schema.types[t.Name] = t
+	}
+	return schema, nil
+}
+
+// Operations builds an operation for every field of the query and mutation
+// types, selecting the scalar fields of the result. Subscriptions are sent
+// over websockets and are left out.
+func (s *Schema) Operations(endpoint string) []Operation {
+	var operations []Operation
+	for _, root := range []struct {
+		kind string
+		typ  *namedType
+	}{{"query", s.QueryType}, {"mutation", s.MutationType}} {
+		if root.typ == nil || s.types[root.typ.Name] == nil {
+			continue
+		}
+		for _, field := range s.types[root.typ.Name].Fields {
+			operations = append(operations, s.operation(endpoint, root.kind, field))
+		}
+	}
+	return operations
+}
+
+func (s *Schema) operation(endpoint string, kind string, field Field) Operation {
+	operation := Operation{Endpoint: endpoint, Type: kind, Name: field.Name, Source: SourceIntrospection}
+	var definitions, arguments []string
+	for _, arg := range field.Args {
+		typeName := arg.Type.String()
+		operation.Variables = append(operation.Variables, Variable{Name: arg.Name, Type: typeName, Example: exampleValue(typeName, s)})
+		definitions = append(definitions, "$"+arg.Name+": "+typeName)
+		arguments = append(arguments, arg.Name+": $"+arg.Name)
+	}
+
+	query := kind + " " + field.Name
+	if len(definitions) > 0 {
+		query += "(" + strings.Join(definitions, ", ") + ")"
+	}
+	query += " { " + field.Name
+	if len(arguments) > 0 {
+		query += "(" + strings.Join(arguments, ", ") + ")"
+	}
+	if selection := s.selection(field.Type.named()); selection != "" {
+		query += " " + selection
+	}
+	operation.Query = query + " }"
+	return operation
+}
+
+// selection returns the selection set of the scalar fields of an object
+// type, empty for a scalar or enum type
+func (s *Schema) selection(typeName string) string {
+	t := s.types[typeName]
+	if t == nil || (t.Kind != "OBJECT" && t.Kind != "INTERFACE" && t.Kind != "UNION") {
+		return ""
+	}
+	var fields []string
+	for _, field := range t.Fields {
+		if len(field.Args) > 0 {
+			continue
+		}
+		if fieldType := s.types[field.Type.named()]; fieldType != nil && (fieldType.Kind == "SCALAR" || fieldType.Kind == "ENUM") {
+			fields = append(fields, field.Name)
+		}
+	}
+	if len(fields) == 0 {
+		fields = append(fields, "__typename")
+	}
+	return "{ " + strings.Join(fields, " ") + " }"
+}
+
+// exampleValue returns an example value of a type in GraphQL notation,
+// input objects and enums are resolved with the schema when known
+func exampleValue(typeName string, schema *Schema) interface{} {
+	return exampleDepth(typeName, schema, 0)
+}
+
+func exampleDepth(typeName string, schema *Schema, depth int) interface{} {
+	typeName = strings.TrimSuffix(typeName, "!")
+	if strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]") {
+		item := exampleDepth(typeName[1:len(typeName)-1], schema, depth)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	}
+	switch typeName {
+	case "Int", "Long":
+		return 1
+	case "Float":
+		return 1.5
+	case "Boolean":
+		return true
+	case "ID":
+		return "1"
+	case "String":
+		return "test"
+	}
+	if schema == nil {
+		return nil
+	}
+	t := schema.types[typeName]
+	if t == nil {
+		return nil
+	}
+	switch t.Kind {
+	case "ENUM":
+		if len(t.EnumValues) > 0 {
+			return t.EnumValues[0].Name
+		}
+		return nil
+	case "INPUT_OBJECT":
+		if depth >= maxDepth {
+			return nil
+		}
+		object := map[string]interface{}{}
+		for _, field := range t.InputFields {
+			// the optional fields of a recursive input are left out
+			if value := exampleDepth(field.Type.String(), schema, depth+1); value != nil {
+				object[field.Name] = value
+			}
+		}
+		return object
+	}
+	// custom scalars like DateTime or JSON
+	return "test"
+}
+
+// PostFunc sends a JSON body to a URL and returns the response status code and body
+type PostFunc func(url string, body []byte) (int, []byte, error)
+
+// Introspect runs the introspection query against an endpoint and returns
+// an operation for every query and mutation of the schema
+func Introspect(endpoint string, post PostFunc) ([]Operation, error) {
+	body, _ := json.Marshal(map[string]string{"query": IntrospectionQuery})
+	status, data, err := post(endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if status < 200 || status >= 300 {
+		return nil, errors.New("introspection query rejected")
+	}
+	schema, err := ParseIntrospection(data)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Operations(endpoint), nil
+}
+
+func sortVariables(variables []Variable) {
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+}
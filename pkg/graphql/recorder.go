@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Recorder collects the distinct operations seen by both engines per
+// endpoint and claims the endpoints to introspect
+type Recorder struct {
+	introspect   bool
+	mutations    bool
+	operations   map[string]*Operation
+	introspected map[string]struct{}
+	lock         sync.Mutex
+}
+
+// NewRecorder returns an empty recorder, the endpoints found are
+// introspected when introspect is true and the mutations of their schemas
+// are sent when mutations is true
+func NewRecorder(introspect bool, mutations bool) *Recorder {
+	return &Recorder{
+		introspect:   introspect,
+		mutations:    mutations,
+		operations:   make(map[string]*Operation),
+		introspected: make(map[string]struct{}),
+	}
+}
+
+// Add records an operation and returns true if it was not seen before on
+// its endpoint
+func (r *Recorder) Add(operation Operation) bool {
+	if r == nil {
+		return false
+	}
+	key := operation.Endpoint + " " + operation.Key()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.operations[key]; ok {
+		return false
+	}
+	r.operations[key] = &operation
+	return true
+}
+
+// Replay returns true if an operation may be sent to its endpoint. The
+// mutations generated from a schema are only recorded unless enabled, and
+// those which look like destructive actions never are sent.
+func (r *Recorder) Replay(operation Operation) bool {
+	if r == nil {
+		return false
+	}
+	if operation.Source != SourceIntrospection || operation.Type != "mutation" {
+		return true
+	}
+	return r.mutations && !Dangerous(operation.Name)
+}
+
+// ClaimIntrospection returns true the first time it is called for an
+// endpoint if introspection is enabled
+func (r *Recorder) ClaimIntrospection(endpoint string) bool {
+	if r == nil || !r.introspect {
+		return false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.introspected[endpoint]; ok {
+		return false
+	}
+	r.introspected[endpoint] = struct{}{}
+	return true
+}
+
+// Operations returns the recorded operations sorted by endpoint, type and name
+func (r *Recorder) Operations() []Operation {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	operations := make([]Operation, 0, len(r.operations))
+	for _, operation := range r.operations {
+		operations = append(operations, *operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		a, b := operations[i], operations[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Query < b.Query
+	})
+	return operations
+}
+
+// WriteJSON writes the recorded operations to path
+func (r *Recorder) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r.Operations(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultPath is the path of the endpoint the operations of a script are
+// sent to when the script does not name one
+const DefaultPath = "/graphql"
+
+var (
+	// taggedTemplateRegex matches the gql`...` and graphql`...` tagged templates
+	taggedTemplateRegex = regexp.MustCompile("\\b(?:gql|graphql)\\s*(?:\\(\\s*)?`((?:[^`\\\\]|\\\\.)*)`")
+	// stringRegex matches the string and template literals of a script
+	stringRegex = regexp.MustCompile("\"((?:[^\"\\\\\\n]|\\\\.)*)\"|'((?:[^'\\\\\\n]|\\\\.)*)'|`((?:[^`\\\\]|\\\\.)*)`")
+	// substitutionRegex matches the substitutions of a template literal,
+	// fragments are interpolated into gql templates
+	substitutionRegex = regexp.MustCompile(`\$\{[^}]*\}`)
+	// documentRegex matches the start of an executable document
+	documentRegex = regexp.MustCompile(`^\s*(?:query|mutation|subscription|fragment)\b[\w\s$:!,()\[\]=@"]*\{`)
+	// endpointRegex matches the paths of GraphQL endpoints
+	endpointRegex = regexp.MustCompile(`(?i)^(?:https?://[^/\s"'` + "`" + `]+)?(?:/[\w.~-]+)*/(?:graphql|gql)(?:/[\w-]*)?/?$`)
+	// linkURIRegex matches the uri option of the Apollo HTTP links
+	linkURIRegex = regexp.MustCompile("\\buri\\s*:\\s*[\"'`]([^\"'`\\s]+)[\"'`]")
+	// apolloRegex matches the Apollo client setup
+	apolloRegex = regexp.MustCompile(`\b(?:ApolloClient|HttpLink|createHttpLink|BatchHttpLink|createUploadLink)\b`)
+)
+
+// literalEscapes unescapes the single quoted and template literals
+var literalEscapes = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "", `\'`, "'", `\"`, `"`, "\\`", "`", `\\`, `\`)
+
+// ScanScript returns the GraphQL operations defined in a script, from gql
+// tagged templates and string literals holding documents, and the endpoints
+// the script sends them to, from Apollo links and GraphQL paths. The
+// fragments defined anywhere in the script are appended to the operations
+// spreading them. The endpoints are returned as written, relative paths
+// resolve against the page loading the script.
+func ScanScript(source string) ([]Operation, []string) {
+	var documents []string
+	for _, match := range taggedTemplateRegex.FindAllStringSubmatch(source, -1) {
+		documents = append(documents, literalEscapes.Replace(substitutionRegex.ReplaceAllString(match[1], "")))
+	}
+
+	var endpoints []string
+	seenEndpoints := make(map[string]struct{})
+	addEndpoint := func(endpoint string) {
+		if _, ok := seenEndpoints[endpoint]; !ok {
+			seenEndpoints[endpoint] = struct{}{}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if apolloRegex.MatchString(source) {
+		for _, match := range linkURIRegex.FindAllStringSubmatch(source, -1) {
+			if !strings.HasPrefix(match[1], "ws") {
+				addEndpoint(match[1])
+			}
+		}
+	}
+
+	scanDocuments := strings.Contains(source, "query") || strings.Contains(source, "mutation")
+	for _, match := range stringRegex.FindAllStringSubmatch(source, -1) {
+		literal := match[1] + match[2] + match[3]
+		if endpointRegex.MatchString(literal) {
+			addEndpoint(literal)
+			continue
+		}
+		if !scanDocuments || !documentRegex.MatchString(literal) {
+			continue
+		}
+		if match[1] != "" {
+			if unquoted, err := strconv.Unquote(`"` + literal + `"`); err == nil {
+				documents = append(documents, unquoted)
+				continue
+			}
+		}
+		documents = append(documents, literalEscapes.Replace(substitutionRegex.ReplaceAllString(literal, "")))
+	}
+	return scriptOperations(documents), endpoints
+}
+
+// scriptOperations parses the documents of a script, the fragments are
+// shared by all the documents
+func scriptOperations(documents []string) []Operation {
+	fragments := make(map[string]*definition)
+	var definitions []*definition
+	for _, document := range documents {
+		parsed, err := parseDefinitions(document)
+		if err != nil {
+			continue
+		}
+		for _, d := range parsed {
+			if d.kind == "fragment" {
+				fragments[d.name] = d
+				continue
+			}
+			definitions = append(definitions, d)
+		}
+	}
+
+	var operations []Operation
+	seen := make(map[string]struct{})
+	for _, d := range definitions {
+		operation := Operation{
+			Type:      d.kind,
+			Name:      d.name,
+			Query:     withFragments(d, fragments),
+			Variables: d.variables,
+			Source:    SourceScript,
+		}
+		if _, ok := seen[operation.Key()]; ok {
+			continue
+		}
+		seen[operation.Key()] = struct{}{}
+		operations = append(operations, operation)
+	}
+	return operations
+}
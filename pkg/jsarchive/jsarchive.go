@@ -0,0 +1,235 @@
+package jsarchive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// ManifestFile is the name of the manifest written to the archive directory
+const ManifestFile = "manifest.json"
+
+// hashLength is the number of hex characters of the content hash kept in file names
+const hashLength = 12
+
+// scriptTypes are the type attributes of the script elements holding javascript
+var scriptTypes = map[string]struct{}{
+	"": {}, "module": {}, "text/javascript": {}, "application/javascript": {},
+	"text/ecmascript": {}, "application/ecmascript": {}, "application/x-javascript": {},
+}
+
+// Source is a place a script was seen at
+type Source struct {
+	// Page is the page the script was loaded or inlined by
+	Page string `json:"page,omitempty"`
+	// URL is the URL of an external script, empty for inline scripts
+	URL string `json:"url,omitempty"`
+}
+
+// Entry is an archived script
+type Entry struct {
+	// File is the path of the script relative to the archive directory
+	File     string   `json:"file"`
+	Hash     string   `json:"sha256"`
+	Size     int      `json:"size"`
+	Inline   bool     `json:"inline"`
+	Minified bool     `json:"minified"`
+	Sources  []Source `json:"sources"`
+}
+
+// Archive saves every distinct script to a directory per host, deduplicated
+// by content hash, and records where each was seen in a manifest
+type Archive struct {
+	dir     string
+	entries map[string]*Entry
+	lock    sync.Mutex
+}
+
+// New returns an archive writing to dir, the directory is created if needed
+func New(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Archive{dir: dir, entries: make(map[string]*Entry)}, nil
+}
+
+// Add archives an external script, page is the page which loaded it
+func (a *Archive) Add(page string, scriptURL string, content []byte) error {
+	return a.add(page, scriptURL, content, false)
+}
+
+// AddInline archives the inline scripts of an html page
+func (a *Archive) AddInline(page string, body string) error {
+	for _, script := range InlineScripts(body) {
+		if err := a.add(page, "", []byte(script), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Archive) add(page string, scriptURL string, content []byte, inline bool) error {
+	if a == nil || len(strings.TrimSpace(string(content))) == 0 {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	source := Source{Page: page, URL: scriptURL}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if entry, ok := a.entries[hash]; ok {
+		for _, seen := range entry.Sources {
+			if seen == source {
+				return nil
+			}
+		}
+		entry.Sources = append(entry.Sources, source)
+		return nil
+	}
+
+	origin := scriptURL
+	if inline {
+		origin = page
+	}
+	file := path.Join(hostDir(origin), fileName(scriptURL, hash, inline))
+	if err := os.MkdirAll(filepath.Join(a.dir, filepath.Dir(file)), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(a.dir, file), content, 0644); err != nil {
+		return err
+	}
+	a.entries[hash] = &Entry{
+		File:     file,
+		Hash:     hash,
+		Size:     len(content),
+		Inline:   inline,
+		Minified: IsMinified(content),
+		Sources:  []Source{source},
+	}
+	return nil
+}
+
+// Entries returns the archived scripts sorted by file
+func (a *Archive) Entries() []Entry {
+	if a == nil {
+		return nil
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	entries := make([]Entry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].File < entries[j].File
+	})
+	return entries
+}
+
+// WriteManifest writes the manifest to the archive directory
+func (a *Archive) WriteManifest() error {
+	data, err := json.MarshalIndent(a.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.dir, ManifestFile), data, 0644)
+}
+
+// InlineScripts returns the content of the inline javascript blocks of an
+// html page, data blocks like application/json and templates are skipped
+func InlineScripts(body string) []string {
+	var scripts []string
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return scripts
+		}
+		if tokenType != html.StartTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		if token.Data != "script" {
+			continue
+		}
+		javascript := true
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "src":
+				javascript = false
+			case "type":
+				if _, ok := scriptTypes[strings.ToLower(strings.TrimSpace(attr.Val))]; !ok {
+					javascript = false
+				}
+			}
+		}
+		// the tokenizer returns the raw text of a script as a single token
+		if tokenizer.Next() == html.TextToken && javascript {
+			scripts = append(scripts, string(tokenizer.Text()))
+		}
+	}
+}
+
+// IsMinified guesses if a script is minified from its line lengths and the
+// share of whitespace, minifiers drop indentation and line breaks
+func IsMinified(content []byte) bool {
+	text := strings.TrimSpace(string(content))
+	if len(text) < 200 {
+		return false
+	}
+	lines := strings.Split(text, "\n")
+	longest := 0
+	for _, line := range lines {
+		if len(line) > longest {
+			longest = len(line)
+		}
+	}
+	whitespace := 0
+	for _, c := range text {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			whitespace++
+		}
+	}
+	return len(text)/len(lines) > 200 || (longest > 1000 && float64(whitespace)/float64(len(text)) < 0.1)
+}
+
+// hostDir returns the directory of the host of a URL, the port is kept
+// with a character allowed in file names
+func hostDir(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(":", "_", "[", "", "]", "").Replace(parsed.Host)
+}
+
+// fileName names an archived script after its URL and content hash
+func fileName(scriptURL string, hash string, inline bool) string {
+	if inline {
+		return "inline-" + hash[:hashLength] + ".js"
+	}
+	name := ""
+	if parsed, err := url.Parse(scriptURL); err == nil {
+		name = strings.TrimSuffix(path.Base(parsed.Path), path.Ext(parsed.Path))
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || name == "." || name == "_" {
+		name = "script"
+	}
+	return name + "-" + hash[:hashLength] + ".js"
+}
@@ -0,0 +1,55 @@
+package jsarchive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := New(dir)
+	assert.Nil(t, err)
+
+	script := []byte("function load() {\n  return fetch('/api/users');\n}\n")
+	assert.Nil(t, archive.Add("https://example.com/", "https://example.com/static/app.js?v=1", script))
+	assert.Nil(t, archive.Add("https://example.com/about", "https://cdn.example.com/app.js", script))
+	assert.Nil(t, archive.Add("https://example.com/about", "https://cdn.example.com/app.js", script))
+	assert.Nil(t, archive.AddInline("https://example.com:8443/login", `<html><script>var token = "abc";</script>
+<script type="application/json">{"a": 1}</script><script src="/app.js"></script><script type="module">import "./x.js";</script></html>`))
+
+	entries := archive.Entries()
+	assert.Len(t, entries, 3)
+	external := entries[0]
+	assert.Equal(t, "example.com/app-"+external.Hash[:hashLength]+".js", external.File)
+	assert.Len(t, external.Sources, 2, "should deduplicate by content and record every source")
+	assert.False(t, external.Inline)
+	for _, entry := range entries[1:] {
+		assert.True(t, entry.Inline)
+		assert.True(t, strings.HasPrefix(entry.File, "example.com_8443/inline-"))
+		assert.Equal(t, []Source{{Page: "https://example.com:8443/login"}}, entry.Sources)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, external.File))
+	assert.Nil(t, err)
+	assert.Equal(t, script, data)
+
+	assert.Nil(t, archive.WriteManifest())
+	manifest, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	assert.Nil(t, err)
+	var written []Entry
+	assert.Nil(t, json.Unmarshal(manifest, &written))
+	assert.Equal(t, entries, written)
+}
+
+func TestIsMinified(t *testing.T) {
+	minified := strings.Repeat(`!function(e){var t={};function n(r){if(t[r])return t[r].exports}}([]);`, 30)
+	assert.True(t, IsMinified([]byte(minified)))
+
+	source := strings.Repeat("function load(id) {\n  return fetch('/api/users/' + id);\n}\n\n", 30)
+	assert.False(t, IsMinified([]byte(source)))
+}
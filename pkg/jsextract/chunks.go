@@ -0,0 +1,334 @@
+package jsextract
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// viteMapDeps is the helper Vite declares to list the dependencies to preload
+const viteMapDeps = "__vite__mapDeps"
+
+// Chunks parses a bundle and returns the URLs of the chunks it loads on
+// demand, usually when a route is visited: the chunk files computed by a
+// webpack runtime, the dependencies preloaded by Vite and the import()
+// specifiers. scriptURL is the URL of the bundle, chunk paths are resolved
+// against it.
+func Chunks(scriptURL string, source string) ([]string, error) {
+	base, err := url.Parse(scriptURL)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := js.Parse(parse.NewInputString(source), js.Options{})
+	if err != nil {
+		return nil, err
+	}
+	finder := &chunkFinder{}
+	js.Walk(finder, ast)
+	return finder.urls(base), nil
+}
+
+// chunkFunc is a function computing the file of a chunk from its id, like
+// __webpack_require__.u or the jsonpScriptSrc of webpack 4
+type chunkFunc struct {
+	param string
+	expr  js.IExpr
+}
+
+type chunkFinder struct {
+	funcs []chunkFunc
+	// ids are the chunk ids loaded with __webpack_require__.e
+	ids []string
+	// publicPath is the value assigned to __webpack_require__.p, auto is
+	// true when it is computed from the URL of the bundle. Without
+	// assignment, webpack 4 defaults to the empty relative path.
+	publicPath string
+	auto       bool
+	found      bool
+	// imports are the import() specifiers, relative to the bundle
+	imports []string
+	// deps are the Vite preload dependencies, relative to the base
+	deps []string
+}
+
+func (c *chunkFinder) Enter(n js.INode) js.IVisitor {
+	switch n := n.(type) {
+	case *js.ArrowFunc:
+		c.function(n.Params, n.Body)
+	case *js.FuncDecl:
+		c.function(n.Params, n.Body)
+	case *js.BinaryExpr:
+		if n.Op != js.EqToken {
+			return c
+		}
+		if dot, ok := unwrap(n.X).(*js.DotExpr); ok && string(dot.Y.Data) == "p" {
+			c.assignPublicPath(n.Y)
+		}
+	case *js.CallExpr:
+		if literal, ok := n.X.(*js.LiteralExpr); ok && literal.TokenType == js.ImportToken {
+			if len(n.Args.List) > 0 {
+				if specifier, ok := literalString(n.Args.List[0].Value); ok {
+					c.imports = append(c.imports, specifier)
+				}
+			}
+			return c
+		}
+		if dot, ok := unwrap(n.X).(*js.DotExpr); ok && string(dot.Y.Data) == "e" && len(n.Args.List) == 1 {
+			if id, ok := chunkID(n.Args.List[0].Value); ok {
+				c.ids = append(c.ids, id)
+			}
+			return c
+		}
+		// __vitePreload(() => import("./Admin.js"), ["assets/Admin.js", "assets/Admin.css"])
+		if len(n.Args.List) >= 2 && importsModule(n.Args.List[0].Value) {
+			if deps, ok := unwrap(n.Args.List[1].Value).(*js.ArrayExpr); ok {
+				for _, item := range deps.List {
+					if dep, ok := literalString(item.Value); ok {
+						c.deps = append(c.deps, dep)
+					}
+				}
+			}
+		}
+	case *js.BindingElement:
+		if v, ok := n.Binding.(*js.Var); ok && string(v.Data) == viteMapDeps && n.Default != nil {
+			collector := &stringCollector{}
+			js.Walk(collector, n.Default)
+			c.deps = append(c.deps, collector.values...)
+			return nil
+		}
+	}
+	return c
+}
+
+func (c *chunkFinder) Exit(js.INode) {}
+
+// function records a function of one parameter returning the path of a
+// script built from the parameter
+func (c *chunkFinder) function(params js.Params, body js.BlockStmt) {
+	if len(params.List) != 1 || params.Rest != nil {
+		return
+	}
+	param, ok := params.List[0].Binding.(*js.Var)
+	if !ok {
+		return
+	}
+	expr := returnValue(body)
+	if binary, ok := unwrap(expr).(*js.BinaryExpr); !ok || binary.Op != js.AddToken {
+		return
+	}
+	parts := flatten(expr)
+	if last, ok := literalString(parts[len(parts)-1]); !ok || !isScriptPath(last) {
+		return
+	}
+	c.funcs = append(c.funcs, chunkFunc{param: string(param.Data), expr: expr})
+}
+
+// assignPublicPath records the public path of the webpack runtime, the
+// automatic public path is the directory of the bundle with a suffix
+func (c *chunkFinder) assignPublicPath(expr js.IExpr) {
+	if c.found {
+		return
+	}
+	if value, ok := literalString(expr); ok {
+		c.publicPath, c.auto, c.found = value, false, true
+		return
+	}
+	parts := flatten(expr)
+	if len(parts) == 2 {
+		if suffix, ok := literalString(parts[1]); ok {
+			c.publicPath, c.auto, c.found = suffix, true, true
+		}
+	}
+}
+
+// urls computes and resolves the chunk URLs
+func (c *chunkFinder) urls(base *url.URL) []string {
+	var urls []string
+	seen := make(map[string]struct{})
+	add := func(resolved *url.URL, err error) {
+		if err != nil || !isScriptPath(resolved.Path) {
+			return
+		}
+		value := resolved.String()
+		if _, ok := seen[value]; ok {
+			return
+		}
+		seen[value] = struct{}{}
+		urls = append(urls, value)
+	}
+
+	for _, f := range c.funcs {
+		for _, id := range append(indexKeys(f.expr, f.param), c.ids...) {
+			if file, ok := evaluate(f.expr, f.param, id); ok {
+				add(resolveChunk(base, c.publicPath, c.auto, file))
+			}
+		}
+	}
+	for _, dep := range c.deps {
+		add(resolveChunk(base, "", false, dep))
+	}
+	for _, specifier := range c.imports {
+		// bare specifiers are resolved by an import map, not fetched as is
+		if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") ||
+			strings.HasPrefix(specifier, "/") || strings.Contains(specifier, "://") {
+			add(base.Parse(specifier))
+		}
+	}
+	return urls
+}
+
+// resolveChunk resolves the file of a chunk loaded from publicPath. An
+// automatic public path is relative to the bundle. Any other relative
+// public path is relative to the page, which is unknown, so the directory
+// of the bundle is used without the directories the file path repeats:
+// static/js/1.js loaded by /static/js/main.js is /static/js/1.js.
+func resolveChunk(base *url.URL, publicPath string, auto bool, file string) (*url.URL, error) {
+	file = publicPath + file
+	if !auto && (strings.HasPrefix(file, "/") || strings.Contains(file, "://")) {
+		return base.Parse(file)
+	}
+	dir := path.Dir(base.Path)
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	if !auto {
+		fileDir := path.Dir(file)
+		if fileDir != "." && strings.HasSuffix(dir, "/"+fileDir+"/") {
+			dir = strings.TrimSuffix(dir, fileDir+"/")
+		}
+	}
+	return base.Parse(dir + file)
+}
+
+// evaluate computes the value of a chunk file expression for a chunk id
+func evaluate(expr js.IExpr, param string, id string) (string, bool) {
+	expr = unwrap(expr)
+	if value, ok := literalString(expr); ok {
+		return value, true
+	}
+	switch n := expr.(type) {
+	case *js.Var:
+		if string(n.Data) == param {
+			return id, true
+		}
+	case *js.DotExpr:
+		// webpack 4 prepends the public path in the function, it is resolved separately
+		if string(n.Y.Data) == "p" {
+			return "", true
+		}
+	case *js.IndexExpr:
+		object, ok := unwrap(n.X).(*js.ObjectExpr)
+		if v, isVar := unwrap(n.Y).(*js.Var); ok && isVar && string(v.Data) == param {
+			for _, item := range object.List {
+				if item.Name != nil && !item.Name.IsComputed() && unquote(item.Name.Literal.Data) == id {
+					return literalString(item.Value)
+				}
+			}
+		}
+	case *js.BinaryExpr:
+		switch n.Op {
+		case js.AddToken:
+			x, ok := evaluate(n.X, param, id)
+			if !ok {
+				return "", false
+			}
+			y, ok := evaluate(n.Y, param, id)
+			return x + y, ok
+		case js.OrToken:
+			// ({12: "admin"}[e] || e)
+			if x, ok := evaluate(n.X, param, id); ok {
+				return x, true
+			}
+			return evaluate(n.Y, param, id)
+		}
+	}
+	return "", false
+}
+
+// indexKeys returns the keys of the object literals indexed by param, the
+// ids of the chunks a webpack chunk map knows
+func indexKeys(expr js.IExpr, param string) []string {
+	var keys []string
+	switch n := unwrap(expr).(type) {
+	case *js.BinaryExpr:
+		keys = append(indexKeys(n.X, param), indexKeys(n.Y, param)...)
+	case *js.IndexExpr:
+		object, ok := unwrap(n.X).(*js.ObjectExpr)
+		if v, isVar := unwrap(n.Y).(*js.Var); ok && isVar && string(v.Data) == param {
+			for _, item := range object.List {
+				if item.Name != nil && !item.Name.IsComputed() {
+					keys = append(keys, unquote(item.Name.Literal.Data))
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// returnValue returns the value a function body returns, arrow functions
+// with an expression body are parsed as a single return statement
+func returnValue(body js.BlockStmt) js.IExpr {
+	if len(body.List) == 0 {
+		return nil
+	}
+	if stmt, ok := body.List[len(body.List)-1].(*js.ReturnStmt); ok {
+		return stmt.Value
+	}
+	return nil
+}
+
+// importsModule returns true if expr is a function returning an import() call
+func importsModule(expr js.IExpr) bool {
+	var body js.BlockStmt
+	switch n := unwrap(expr).(type) {
+	case *js.ArrowFunc:
+		body = n.Body
+	case *js.FuncDecl:
+		body = n.Body
+	default:
+		return false
+	}
+	call, ok := unwrap(returnValue(body)).(*js.CallExpr)
+	if !ok {
+		return false
+	}
+	literal, ok := call.X.(*js.LiteralExpr)
+	return ok && literal.TokenType == js.ImportToken
+}
+
+func chunkID(expr js.IExpr) (string, bool) {
+	literal, ok := unwrap(expr).(*js.LiteralExpr)
+	if !ok {
+		return "", false
+	}
+	switch literal.TokenType {
+	case js.StringToken, js.IntegerToken, js.DecimalToken:
+		return unquote(literal.Data), true
+	}
+	return "", false
+}
+
+// isScriptPath returns true if the path, without query, is a javascript file
+func isScriptPath(value string) bool {
+	if index := strings.IndexAny(value, "?#"); index != -1 {
+		value = value[:index]
+	}
+	return strings.HasSuffix(value, ".js") || strings.HasSuffix(value, ".mjs")
+}
+
+// stringCollector collects the string literals of a subtree
+type stringCollector struct {
+	values []string
+}
+
+func (s *stringCollector) Enter(n js.INode) js.IVisitor {
+	if literal, ok := n.(*js.LiteralExpr); ok && literal.TokenType == js.StringToken {
+		s.values = append(s.values, unquote(literal.Data))
+	}
+	return s
+}
+
+func (s *stringCollector) Exit(js.INode) {}
@@ -0,0 +1,56 @@
+package jsextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunksWebpack5(t *testing.T) {
+	source := `(()=>{var r={};r.u=e=>"static/js/"+e+"."+{105:"9ab5b0e4",386:"1c2d3e4f"}[e]+".chunk.js",r.miniCssF=e=>"static/css/"+e+"."+{105:"aaaa"}[e]+".chunk.css",
+r.p="/",Promise.all([r.e(105),r.e(912)]).then(r.bind(r,105))})();`
+	chunks, err := Chunks("https://example.com/static/js/main.3f4e.js", source)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"https://example.com/static/js/105.9ab5b0e4.chunk.js",
+		"https://example.com/static/js/386.1c2d3e4f.chunk.js",
+	}, chunks, "should compute the chunks of the hash map and skip the css chunks")
+}
+
+func TestChunksWebpackAutoPublicPath(t *testing.T) {
+	source := `var r={};r.u=function(e){return({42:"admin"}[e]||e)+".js"};
+var e;r.g.document&&(e=document.currentScript.src),e=e.replace(/\/[^\/]+$/,"/"),r.p=e+"../";
+r.e(42).then(function(){});r.e("src_pages_report_vue").then(function(){});`
+	chunks, err := Chunks("https://example.com/app/js/main.js", source)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"https://example.com/app/admin.js",
+		"https://example.com/app/src_pages_report_vue.js",
+	}, chunks)
+}
+
+func TestChunksWebpack4(t *testing.T) {
+	source := `function jsonpScriptSrc(chunkId) {
+  return __webpack_require__.p + "js/" + ({"about":"about"}[chunkId]||chunkId) + "." + {"about":"5c8a","chunk-2d0e":"77aa"}[chunkId] + ".js"
+}`
+	chunks, err := Chunks("https://example.com/js/app.js", source)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"https://example.com/js/about.5c8a.js",
+		"https://example.com/js/chunk-2d0e.77aa.js",
+	}, chunks, "should resolve a relative public path against the directory the bundle is served from")
+}
+
+func TestChunksVite(t *testing.T) {
+	source := `const __vite__mapDeps=(i,m=__vite__mapDeps,d=(m.f||(m.f=["assets/Settings-4f1c.js","assets/Settings-9a0b.css"])))=>i.map(i=>d[i]);
+const routes=[{path:"/admin",component:()=>w(()=>import("./Admin-b2c3.js"),["assets/Admin-b2c3.js","assets/vendor-11aa.js","assets/Admin-b2c3.css"])},
+{path:"/settings",component:()=>w(()=>import("./Settings-4f1c.js"),__vite__mapDeps([0,1]))}];
+import("vue");`
+	chunks, err := Chunks("https://example.com/base/assets/index-0d1e.js", source)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		"https://example.com/base/assets/Admin-b2c3.js",
+		"https://example.com/base/assets/vendor-11aa.js",
+		"https://example.com/base/assets/Settings-4f1c.js",
+	}, chunks)
+}
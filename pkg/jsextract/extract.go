@@ -0,0 +1,681 @@
+package jsextract
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// Placeholder is the value used for the dynamic parts of an endpoint
+// when a concrete URL is needed
+const Placeholder = "1"
+
+// Endpoint is an endpoint found in JavaScript source
+type Endpoint struct {
+	// URL is the endpoint as written in the source. Dynamic parts are
+	// replaced by {name} markers, name being the variable they come from.
+	URL string
+	// Method is the inferred HTTP method, empty when unknown
+	Method string
+	// Params are the inferred body or query parameter names
+	Params []string
+	// JSON is true if the body is sent as JSON
+	JSON bool
+	// Source is how the endpoint was found: literal, template, concat,
+	// route, comment or the request API (fetch, axios, ajax, xhr, http...)
+	Source string
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{[^{}/?&=]*\}`)
+	routeParamRegex  = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)\??`)
+	mimeTypeRegex    = regexp.MustCompile(`^(?i)(application|text|image|audio|video|font|multipart|model|message)/[a-z0-9.+\-]+$`)
+	dateRegex        = regexp.MustCompile(`^[0-9{}]+/[0-9{}]+(/[0-9{}]+)?$`)
+	pathRegex        = regexp.MustCompile(`^[A-Za-z0-9\-._~%!$&'()*+,;=:@/{}?#\[\]|]+$`)
+	commentURLRegex  = regexp.MustCompile(`https?://[^\s'"<>()\[\]{}*]+`)
+	fileRegex        = regexp.MustCompile(`^[A-Za-z0-9\-_{}]+\.(aspx?|action|cfm|cgi|do|jsp|json|php5?|html?|xml|txt)([?#].*)?$`)
+)
+
+// httpMethods are the request API methods named after an HTTP method
+var httpMethods = map[string]string{
+	"get": "GET", "post": "POST", "put": "PUT", "delete": "DELETE", "del": "DELETE",
+	"patch": "PATCH", "head": "HEAD", "options": "OPTIONS", "getjson": "GET",
+}
+
+// routeKeys are the object keys whose string value is an endpoint
+var routeKeys = map[string]struct{}{
+	"path": {}, "url": {}, "uri": {}, "href": {}, "api": {}, "endpoint": {}, "action": {}, "redirect": {},
+}
+
+// Extract parses JavaScript source and returns the endpoints it references.
+// An error is returned when the source can not be parsed, callers should
+// fall back to regex scraping in that case.
+func Extract(source string) ([]Endpoint, error) {
+	ast, err := js.Parse(parse.NewInputString(source), js.Options{})
+	if err != nil {
+		return nil, err
+	}
+	e := &extractor{index: make(map[string]int)}
+	js.Walk(e, ast)
+	return e.endpoints, nil
+}
+
+// Scan extracts endpoints from the string literals and comments of source
+// with the lexer only. It is meant for sources the parser rejects, like
+// TypeScript and JSX, and for commented-out code.
+func Scan(source string) []Endpoint {
+	e := &extractor{index: make(map[string]int)}
+	e.scan(source, "literal", true, true)
+	return e.endpoints
+}
+
+// ScanComments extracts the endpoints written in the comments of source
+func ScanComments(source string) []Endpoint {
+	e := &extractor{index: make(map[string]int)}
+	e.scan(source, "comment", false, true)
+	return e.endpoints
+}
+
+// Concrete returns the URL with its dynamic parts replaced by Placeholder
+func (e Endpoint) Concrete() string {
+	return placeholderRegex.ReplaceAllString(e.URL, Placeholder)
+}
+
+// Body returns the content type and a body built from the parameters
+func (e Endpoint) Body() (string, string) {
+	if len(e.Params) == 0 {
+		return "", ""
+	}
+	if e.JSON {
+		parts := make([]string, 0, len(e.Params))
+		for _, param := range e.Params {
+			parts = append(parts, strconv.Quote(param)+":"+strconv.Quote(Placeholder))
+		}
+		return "application/json", "{" + strings.Join(parts, ",") + "}"
+	}
+	return "application/x-www-form-urlencoded", e.query()
+}
+
+// Safe returns true if a request with method does not modify the target,
+// the other endpoints are only recorded unless their replay is enabled
+func Safe(method string) bool {
+	switch method {
+	case "", "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// Request returns the method, URL, content type and body of the endpoint.
+// Parameters of requests without a body are appended to the query string.
+func (e Endpoint) Request() (method, url, contentType, body string) {
+	method = e.Method
+	if method == "" {
+		method = "GET"
+	}
+	url = e.Concrete()
+	switch method {
+	case "GET", "HEAD", "DELETE", "OPTIONS":
+		if query := e.query(); query != "" {
+			if strings.Contains(url, "?") {
+				url += "&" + query
+			} else {
+				url += "?" + query
+			}
+		}
+	default:
+		contentType, body = e.Body()
+	}
+	return
+}
+
+func (e Endpoint) query() string {
+	parts := make([]string, 0, len(e.Params))
+	for _, param := range e.Params {
+		parts = append(parts, param+"="+Placeholder)
+	}
+	return strings.Join(parts, "&")
+}
+
+type extractor struct {
+	endpoints []Endpoint
+	index     map[string]int
+}
+
+func (e *extractor) Enter(n js.INode) js.IVisitor {
+	switch n := n.(type) {
+	case *js.CallExpr:
+		if v, ok := n.X.(*js.Var); ok && (string(v.Data) == "require" || string(v.Data) == "importScripts") {
+			// module specifiers are not endpoints
+			return nil
+		}
+		e.call(n.X, n.Args)
+	case *js.NewExpr:
+		if n.Args != nil {
+			e.newExpr(n.X, *n.Args)
+		}
+	case *js.Property:
+		if n.Name != nil && !n.Name.IsComputed() {
+			if _, ok := routeKeys[strings.ToLower(unquote(n.Name.Literal.Data))]; ok {
+				if value, ok := e.stringValue(n.Value); ok {
+					e.add(Endpoint{URL: value, Source: "route"})
+				}
+			}
+		}
+	case *js.BinaryExpr:
+		if n.Op != js.AddToken {
+			return e
+		}
+		if value, ok := e.stringValue(n); ok {
+			e.add(Endpoint{URL: value, Source: "concat"})
+			// walk the dynamic parts only, the partial concatenations are not endpoints
+			for _, part := range flatten(n) {
+				if _, ok := literalString(part); !ok {
+					js.Walk(e, part)
+				}
+			}
+			return nil
+		}
+	case *js.TemplateExpr:
+		if n.Tag == nil {
+			if value, ok := e.stringValue(n); ok {
+				e.add(Endpoint{URL: value, Source: "template"})
+			}
+		}
+	case *js.LiteralExpr:
+		if value, ok := literalString(n); ok {
+			e.add(Endpoint{URL: value, Source: "literal"})
+		}
+	}
+	return e
+}
+
+func (e *extractor) Exit(js.INode) {}
+
+func (e *extractor) scan(source string, tag string, literals bool, comments bool) {
+	lexer := js.NewLexer(parse.NewInputString(source))
+	// the two previous tokens, to skip module specifiers
+	var prev, prev2 string
+	for {
+		tt, data := lexer.Next()
+		switch tt {
+		case js.ErrorToken:
+			return
+		case js.WhitespaceToken, js.LineTerminatorToken:
+			continue
+		case js.StringToken:
+			if literals && prev != "from" && prev != "import" && !(prev == "(" && (prev2 == "require" || prev2 == "import")) {
+				e.add(Endpoint{URL: unquote(data), Source: tag})
+			}
+		case js.TemplateToken:
+			if literals {
+				e.add(Endpoint{URL: unescape(strings.Trim(string(data), "`")), Source: tag})
+			}
+		case js.CommentToken, js.CommentLineTerminatorToken:
+			if !comments {
+				continue
+			}
+			// commented-out code and URLs written in comments
+			comment := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(string(data), "//"), "/*"), "*/")
+			e.scan(comment, "comment", true, false)
+			for _, url := range commentURLRegex.FindAllString(comment, -1) {
+				e.add(Endpoint{URL: url, Source: "comment"})
+			}
+			continue
+		}
+		prev2, prev = prev, string(data)
+	}
+}
+
+// call infers endpoints from the arguments of request API calls
+func (e *extractor) call(callee js.IExpr, args js.Args) {
+	object, method := calleeName(callee)
+	lowerMethod := strings.ToLower(method)
+	lowerObject := strings.ToLower(object)
+	arg := func(i int) js.IExpr {
+		if i < len(args.List) {
+			return args.List[i].Value
+		}
+		return nil
+	}
+	jquery := object == "$" || lowerObject == "jquery"
+
+	switch {
+	// fetch(url, {method, body})
+	case lowerMethod == "fetch":
+		e.fetch(arg(0), arg(1), "fetch")
+	// xhr.open(method, url)
+	case lowerMethod == "open" && len(args.List) >= 2:
+		if verb, ok := literalString(arg(0)); ok {
+			if httpMethod, ok := httpMethods[strings.ToLower(verb)]; ok {
+				if url, ok := e.stringValue(arg(1)); ok {
+					e.add(Endpoint{URL: url, Method: httpMethod, Source: "xhr"})
+				}
+			}
+		}
+	// navigator.sendBeacon(url, data)
+	case lowerMethod == "sendbeacon":
+		if url, ok := e.stringValue(arg(0)); ok {
+			e.add(Endpoint{URL: url, Method: "POST", Source: "beacon"})
+		}
+	// $.ajax(url, settings) / $.ajax(settings)
+	case lowerMethod == "ajax":
+		if url, ok := e.stringValue(arg(0)); ok {
+			endpoint := Endpoint{URL: url, Source: "ajax"}
+			e.config(&endpoint, arg(1), false)
+			e.add(endpoint)
+		} else {
+			endpoint := Endpoint{Source: "ajax"}
+			e.config(&endpoint, arg(0), false)
+			e.add(endpoint)
+		}
+	// axios(url, config) / axios(config) / axios.request(config)
+	case lowerMethod == "axios" || (lowerObject == "axios" && lowerMethod == "request"):
+		if url, ok := e.stringValue(arg(0)); ok {
+			endpoint := Endpoint{URL: url, Source: "axios"}
+			e.config(&endpoint, arg(1), true)
+			e.add(endpoint)
+		} else {
+			endpoint := Endpoint{Source: "axios"}
+			e.config(&endpoint, arg(0), true)
+			e.add(endpoint)
+		}
+	// axios.get(url, config) / $.post(url, data) / this.$http.put(url, data)
+	case httpMethods[lowerMethod] != "" && object != "":
+		url, ok := e.stringValue(arg(0))
+		if !ok {
+			return
+		}
+		source := "http"
+		if jquery {
+			source = "ajax"
+		} else if strings.Contains(lowerObject, "axios") {
+			source = "axios"
+		}
+		endpoint := Endpoint{URL: url, Method: httpMethods[lowerMethod], Source: source, JSON: !jquery}
+		switch {
+		case jquery:
+			// $.get(url, data) / $.post(url, data)
+			e.params(&endpoint, arg(1))
+		case endpoint.Method == "POST" || endpoint.Method == "PUT" || endpoint.Method == "PATCH":
+			// axios.post(url, data, config)
+			e.params(&endpoint, arg(1))
+			e.config(&endpoint, arg(2), true)
+		default:
+			// axios.get(url, {params})
+			e.config(&endpoint, arg(1), true)
+		}
+		e.add(endpoint)
+	}
+}
+
+// newExpr infers endpoints from constructors taking a URL
+func (e *extractor) newExpr(callee js.IExpr, args js.Args) {
+	_, name := calleeName(callee)
+	if len(args.List) == 0 {
+		return
+	}
+	switch name {
+	case "Request":
+		var options js.IExpr
+		if len(args.List) > 1 {
+			options = args.List[1].Value
+		}
+		e.fetch(args.List[0].Value, options, "fetch")
+	case "WebSocket", "EventSource":
+		if url, ok := e.stringValue(args.List[0].Value); ok {
+			e.add(Endpoint{URL: url, Method: "GET", Source: strings.ToLower(name)})
+		}
+	}
+}
+
+func (e *extractor) fetch(urlExpr, options js.IExpr, source string) {
+	url, ok := e.stringValue(urlExpr)
+	if !ok {
+		return
+	}
+	endpoint := Endpoint{URL: url, Method: "GET", Source: source}
+	if object, ok := unwrap(options).(*js.ObjectExpr); ok {
+		if value, ok := literalString(property(object, "method")); ok {
+			endpoint.Method = strings.ToUpper(value)
+		}
+		e.params(&endpoint, property(object, "body"))
+	}
+	e.add(endpoint)
+}
+
+// config reads url, method, data and params from an axios or jQuery
+// settings object
+func (e *extractor) config(endpoint *Endpoint, expr js.IExpr, json bool) {
+	object, ok := unwrap(expr).(*js.ObjectExpr)
+	if !ok {
+		return
+	}
+	if url, ok := e.stringValue(property(object, "url")); ok {
+		endpoint.URL = url
+	}
+	for _, key := range []string{"method", "type"} {
+		if value, ok := literalString(property(object, key)); ok {
+			endpoint.Method = strings.ToUpper(value)
+		}
+	}
+	if data := property(object, "data"); data != nil {
+		endpoint.JSON = json
+		e.params(endpoint, data)
+	}
+	e.params(endpoint, property(object, "params"))
+	if value, ok := literalString(property(object, "contentType")); ok && strings.Contains(value, "json") {
+		endpoint.JSON = true
+	}
+}
+
+// params adds the parameter names of a request body or query: an object
+// literal, JSON.stringify(object), new URLSearchParams(object), qs.stringify(object)
+// or a literal query string
+func (e *extractor) params(endpoint *Endpoint, expr js.IExpr) {
+	expr = unwrap(expr)
+	switch n := expr.(type) {
+	case *js.ObjectExpr:
+		for _, item := range n.List {
+			if item.Name != nil && !item.Name.IsComputed() {
+				endpoint.Params = appendUnique(endpoint.Params, unquote(item.Name.Literal.Data))
+			} else if v, ok := item.Value.(*js.Var); ok && item.Name == nil && !item.Spread {
+				endpoint.Params = appendUnique(endpoint.Params, string(v.Data))
+			}
+		}
+	case *js.CallExpr:
+		object, method := calleeName(n.X)
+		if len(n.Args.List) == 0 {
+			return
+		}
+		if object == "JSON" && method == "stringify" {
+			endpoint.JSON = true
+		}
+		if method == "stringify" || method == "param" {
+			e.params(endpoint, n.Args.List[0].Value)
+		}
+	case *js.NewExpr:
+		if _, name := calleeName(n.X); name == "URLSearchParams" && n.Args != nil && len(n.Args.List) > 0 {
+			e.params(endpoint, n.Args.List[0].Value)
+		}
+	default:
+		if value, ok := literalString(expr); ok && strings.Contains(value, "=") {
+			for _, pair := range strings.Split(value, "&") {
+				if name := strings.SplitN(pair, "=", 2)[0]; name != "" {
+					endpoint.Params = appendUnique(endpoint.Params, name)
+				}
+			}
+		}
+	}
+}
+
+// add normalizes and records an endpoint, merging the method and
+// parameters of endpoints found more than once
+func (e *extractor) add(endpoint Endpoint) {
+	endpoint.URL = normalize(endpoint.URL)
+	if !looksLikeEndpoint(endpoint.URL) {
+		return
+	}
+	i, ok := e.index[endpoint.URL]
+	if !ok {
+		e.index[endpoint.URL] = len(e.endpoints)
+		e.endpoints = append(e.endpoints, endpoint)
+		return
+	}
+	existing := &e.endpoints[i]
+	if existing.Method == "" {
+		existing.Method = endpoint.Method
+	}
+	for _, param := range endpoint.Params {
+		existing.Params = appendUnique(existing.Params, param)
+	}
+	existing.JSON = existing.JSON || endpoint.JSON
+	if isGeneric(existing.Source) && !isGeneric(endpoint.Source) {
+		existing.Source = endpoint.Source
+	}
+}
+
+// stringValue reconstructs the string an expression evaluates to, with
+// {name} markers for the dynamic parts. ok is false if the expression
+// has no literal part.
+func (e *extractor) stringValue(expr js.IExpr) (string, bool) {
+	expr = unwrap(expr)
+	if value, ok := literalString(expr); ok {
+		return value, true
+	}
+	switch n := expr.(type) {
+	case *js.TemplateExpr:
+		if n.Tag != nil {
+			return "", false
+		}
+		var builder strings.Builder
+		for i, part := range n.List {
+			value := string(part.Value)
+			if i == 0 {
+				value = strings.TrimPrefix(value, "`")
+			} else {
+				value = strings.TrimPrefix(value, "}")
+			}
+			builder.WriteString(unescape(strings.TrimSuffix(value, "${")))
+			builder.WriteString(marker(part.Expr))
+		}
+		tail := string(n.Tail)
+		if len(n.List) > 0 {
+			tail = strings.TrimPrefix(tail, "}")
+		} else {
+			tail = strings.TrimPrefix(tail, "`")
+		}
+		builder.WriteString(unescape(strings.TrimSuffix(tail, "`")))
+		return builder.String(), true
+	case *js.BinaryExpr:
+		if n.Op != js.AddToken {
+			return "", false
+		}
+		literal := false
+		var builder strings.Builder
+		for _, part := range flatten(n) {
+			if value, ok := literalString(part); ok {
+				literal = true
+				builder.WriteString(value)
+			} else if value, ok := e.stringValue(part); ok {
+				literal = true
+				builder.WriteString(value)
+			} else {
+				builder.WriteString(marker(part))
+			}
+		}
+		return builder.String(), literal
+	}
+	return "", false
+}
+
+// flatten returns the operands of a chain of + operations
+func flatten(expr js.IExpr) []js.IExpr {
+	expr = unwrap(expr)
+	if n, ok := expr.(*js.BinaryExpr); ok && n.Op == js.AddToken {
+		return append(flatten(n.X), flatten(n.Y)...)
+	}
+	return []js.IExpr{expr}
+}
+
+// marker returns the {name} marker of a dynamic part
+func marker(expr js.IExpr) string {
+	switch n := unwrap(expr).(type) {
+	case *js.Var:
+		return "{" + string(n.Data) + "}"
+	case *js.DotExpr:
+		return "{" + string(n.Y.Data) + "}"
+	case *js.CallExpr:
+		// encodeURIComponent(id), String(id)
+		if len(n.Args.List) == 1 {
+			return marker(n.Args.List[0].Value)
+		}
+	}
+	return "{param}"
+}
+
+// calleeName returns the object and the function name of a callee,
+// foo.bar.get gives bar and get, fetch gives an empty object and fetch
+func calleeName(expr js.IExpr) (string, string) {
+	switch n := unwrap(expr).(type) {
+	case *js.Var:
+		return "", string(n.Data)
+	case *js.DotExpr:
+		_, object := calleeName(n.X)
+		return object, string(n.Y.Data)
+	case *js.CallExpr:
+		// axios.create().get
+		return calleeName(n.X)
+	}
+	return "", ""
+}
+
+// property returns the value of a non computed property of an object literal
+func property(object *js.ObjectExpr, name string) js.IExpr {
+	for _, item := range object.List {
+		if item.Name == nil || item.Name.IsComputed() {
+			continue
+		}
+		if unquote(item.Name.Literal.Data) == name {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+func unwrap(expr js.IExpr) js.IExpr {
+	for {
+		group, ok := expr.(*js.GroupExpr)
+		if !ok {
+			return expr
+		}
+		expr = group.X
+	}
+}
+
+func literalString(expr js.IExpr) (string, bool) {
+	literal, ok := unwrap(expr).(*js.LiteralExpr)
+	if !ok || literal.TokenType != js.StringToken {
+		return "", false
+	}
+	return unquote(literal.Data), true
+}
+
+func isGeneric(source string) bool {
+	return source == "literal" || source == "concat" || source == "template" || source == "route" || source == "comment"
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, item := range list {
+		if item == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// normalize turns route parameters into markers and drops a leading base
+// URL marker, {baseURL}/api/user becomes /api/user
+func normalize(url string) string {
+	url = strings.TrimSpace(url)
+	url = routeParamRegex.ReplaceAllString(url, "/{$1}")
+	if strings.HasPrefix(url, "{") {
+		if end := strings.Index(url, "}"); end != -1 && strings.HasPrefix(url[end+1:], "/") {
+			url = url[end+1:]
+		}
+	}
+	return url
+}
+
+// looksLikeEndpoint filters out the strings which are not URLs or paths
+func looksLikeEndpoint(url string) bool {
+	if len(url) < 2 || len(url) > 2048 || !utf8.ValidString(url) {
+		return false
+	}
+	lower := strings.ToLower(url)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://") {
+		return !strings.ContainsAny(url, " \t\r\n<>\"'`\\") && len(url) > strings.Index(url, "://")+3
+	}
+	if !pathRegex.MatchString(url) {
+		return false
+	}
+	if strings.HasPrefix(url, "//") {
+		return len(url) > 2 && isAlphaNumeric(url[2])
+	}
+	if strings.HasPrefix(url, "/") || strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+		// a lone slash, a regex or a comment like string
+		return len(strings.Trim(url, "/.")) > 0 && isAlphaNumeric(strings.TrimLeft(url, "/.")[0])
+	}
+	if fileRegex.MatchString(url) {
+		return true
+	}
+	if !strings.Contains(url, "/") || mimeTypeRegex.MatchString(url) || dateRegex.MatchString(url) {
+		return false
+	}
+	return isAlphaNumeric(url[0]) && !strings.Contains(url, "//")
+}
+
+func isAlphaNumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '{' || c == '_'
+}
+
+// unquote returns the value of a string literal, identifiers are returned as is
+func unquote(data []byte) string {
+	value := string(data)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return unescape(value[1 : len(value)-1])
+	}
+	return value
+}
+
+// unescape resolves the escape sequences of a string or template literal
+func unescape(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i+1 >= len(value) {
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'x':
+			if i+2 < len(value) {
+				if code, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+					builder.WriteRune(rune(code))
+					i += 2
+					continue
+				}
+			}
+			builder.WriteByte('x')
+		case 'u':
+			if i+4 < len(value) && value[i+1] != '{' {
+				if code, err := strconv.ParseUint(value[i+1:i+5], 16, 16); err == nil {
+					builder.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			builder.WriteByte('u')
+		case '\n':
+			// line continuation
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
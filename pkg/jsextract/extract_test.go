@@ -0,0 +1,118 @@
+package jsextract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func find(endpoints []Endpoint, url string) *Endpoint {
+	for i := range endpoints {
+		if endpoints[i].URL == url {
+			return &endpoints[i]
+		}
+	}
+	return nil
+}
+
+func TestExtract(t *testing.T) {
+	source := `
+const BASE = "https://example.com";
+var routes = [{path: "/user/:id", component: User}, {path: "/settings"}];
+function load(v, page) {
+	fetch("/api/" + v + "/list?page=" + page, {method: "POST", body: JSON.stringify({name: v, page})});
+	axios.get(` + "`/api/items/${item.id}`" + `, {params: {sort: 1}});
+	axios({url: "/api/login", method: "post", data: {username: u, password: p}});
+	$.ajax({url: BASE + "/ajax/save.php", type: "POST", data: "a=1&b=2"});
+	$.post("/comment/add", {content: c});
+	xhr.open("PUT", "/api/profile");
+	new WebSocket("wss://example.com/ws");
+	require("./components/user");
+	document.title = "Hello world";
+	var mime = "application/json";
+}`
+	endpoints, err := Extract(source)
+	assert.Nil(t, err)
+
+	endpoint := find(endpoints, "/api/{v}/list?page={page}")
+	if assert.NotNil(t, endpoint) {
+		assert.Equal(t, "POST", endpoint.Method)
+		assert.Equal(t, []string{"name", "page"}, endpoint.Params)
+		assert.True(t, endpoint.JSON)
+		assert.Equal(t, "fetch", endpoint.Source)
+	}
+	endpoint = find(endpoints, "/api/items/{id}")
+	if assert.NotNil(t, endpoint) {
+		assert.Equal(t, "GET", endpoint.Method)
+		assert.Equal(t, []string{"sort"}, endpoint.Params)
+		method, url, _, _ := endpoint.Request()
+		assert.Equal(t, "GET", method)
+		assert.Equal(t, "/api/items/1?sort=1", url)
+	}
+	endpoint = find(endpoints, "/api/login")
+	if assert.NotNil(t, endpoint) {
+		assert.Equal(t, "POST", endpoint.Method)
+		assert.Equal(t, []string{"username", "password"}, endpoint.Params)
+		_, _, contentType, body := endpoint.Request()
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, `{"username":"1","password":"1"}`, body)
+	}
+	endpoint = find(endpoints, "/ajax/save.php")
+	if assert.NotNil(t, endpoint) {
+		assert.Equal(t, "POST", endpoint.Method)
+		assert.Equal(t, []string{"a", "b"}, endpoint.Params)
+		assert.False(t, endpoint.JSON)
+	}
+	endpoint = find(endpoints, "/comment/add")
+	if assert.NotNil(t, endpoint) {
+		assert.Equal(t, "POST", endpoint.Method)
+		assert.Equal(t, "ajax", endpoint.Source)
+	}
+	assert.Equal(t, "PUT", find(endpoints, "/api/profile").Method)
+	assert.NotNil(t, find(endpoints, "/user/{id}"))
+	assert.NotNil(t, find(endpoints, "/settings"))
+	assert.NotNil(t, find(endpoints, "wss://example.com/ws"))
+	assert.NotNil(t, find(endpoints, "https://example.com"))
+
+	assert.Nil(t, find(endpoints, "./components/user"))
+	assert.Nil(t, find(endpoints, "Hello world"))
+	assert.Nil(t, find(endpoints, "application/json"))
+	assert.Nil(t, find(endpoints, "/api/"))
+}
+
+func TestExtractInvalid(t *testing.T) {
+	_, err := Extract("const a = <div/>;")
+	assert.NotNil(t, err)
+}
+
+func TestScan(t *testing.T) {
+	source := `
+import {get} from "./request";
+export const getUser = (id: number): Promise<User> => get<User>("/api/user/" + id);
+// const old = fetch("/api/v1/legacy");
+/* docs: https://api.example.com/v2/docs */
+const el = <a href="/profile">Profile</a>;`
+	_, err := Extract(source)
+	assert.NotNil(t, err)
+	endpoints := Scan(source)
+	assert.NotNil(t, find(endpoints, "/api/user/"))
+	assert.Equal(t, "comment", find(endpoints, "/api/v1/legacy").Source)
+	assert.NotNil(t, find(endpoints, "https://api.example.com/v2/docs"))
+	assert.Nil(t, find(endpoints, "./request"))
+	assert.Len(t, ScanComments(source), 2)
+}
+
+func TestSafe(t *testing.T) {
+	for method, safe := range map[string]bool{
+		"":        true,
+		"GET":     true,
+		"HEAD":    true,
+		"OPTIONS": true,
+		"POST":    false,
+		"PUT":     false,
+		"PATCH":   false,
+		"DELETE":  false,
+	} {
+		assert.Equal(t, safe, Safe(method), method)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"Venom-Crawler/pkg/katana/navigation"
@@ -18,10 +20,12 @@ import (
 	"Venom-Crawler/pkg/katana/types"
 	"Venom-Crawler/pkg/katana/utils"
 	"Venom-Crawler/pkg/katana/utils/queue"
+	"Venom-Crawler/pkg/session"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-rod/rod"
 	errorutil "github.com/projectdiscovery/utils/errors"
+	fileutil "github.com/projectdiscovery/utils/file"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 	urlutil "github.com/projectdiscovery/utils/url"
 	"github.com/remeh/sizedwaitgroup"
@@ -38,12 +42,12 @@ func NewShared(options *types.CrawlerOptions) (*Shared, error) {
 		Headers: options.Options.ParseCustomHeaders(),
 		Options: options,
 	}
-	if options.Options.KnownFiles != "" {
+	if options.Options.KnownFiles != "" || options.Options.OpenAPI != nil || options.Options.Services != nil {
 		httpclient, _, err := BuildHttpClient(options.Dialer, options.Options, nil)
 		if err != nil {
 			return nil, errorutil.New("could not create http client").Wrap(err)
 		}
-		shared.KnownFiles = files.New(httpclient, options.Options.KnownFiles)
+		shared.KnownFiles = files.New(httpclient, options.Options.KnownFiles, options.Options.OpenAPI, options.Options.Services, options.Options.WellKnown)
 	}
 	return shared, nil
 }
@@ -53,6 +57,10 @@ func (s *Shared) Enqueue(queue *queue.Queue, navigationRequests ...*navigation.R
 		if nr.URL == "" || !utils.IsURL(nr.URL) {
 			continue
 		}
+		if nr.OutputOnly {
+			s.Emit(nr, nil)
+			continue
+		}
 
 		reqUrl := nr.RequestURL()
 		if s.Options.Options.IgnoreQueryParams {
@@ -87,6 +95,41 @@ func (s *Shared) Enqueue(queue *queue.Queue, navigationRequests ...*navigation.R
 	}
 }
 
+// Emit outputs a request the browser already sent, e.g. an XHR hijacked by the
+// hybrid engine, together with its response. It goes through the same scope
+// checks as Enqueue but is not crawled again. The emitted requests are deduped
+// apart from the crawled ones, so a URL first seen as an XHR is still crawled.
+func (s *Shared) Emit(nr *navigation.Request, resp *navigation.Response) {
+	if nr.URL == "" || !utils.IsURL(nr.URL) {
+		return
+	}
+	if !s.Options.UniqueFilter.UniqueURL(emitKey(nr, s.Options.Options.IgnoreQueryParams)) {
+		return
+	}
+	if !s.ValidateScope(nr.URL, nr.RootHostname) {
+		if s.Options.Options.DisplayOutScope {
+			s.Output(nr, nil, ErrOutOfScope)
+		}
+		return
+	}
+	s.Output(nr, resp, nil)
+}
+
+// emitKey returns the dedupe key of an emitted request, prefixed to keep it
+// apart from the keys of the crawled requests. Every method is keyed, the
+// body is part of the key when the request has one.
+func emitKey(nr *navigation.Request, ignoreQueryParams bool) string {
+	reqUrl := nr.URL
+	if ignoreQueryParams {
+		reqUrl = utils.ReplaceAllQueryParam(reqUrl, "")
+	}
+	key := "emit:" + nr.Method + " " + reqUrl
+	if nr.Body != "" {
+		key += ":" + nr.Body
+	}
+	return key
+}
+
 func (s *Shared) ValidateScope(URL string, root string) bool {
 	parsed, err := urlutil.Parse(URL)
 	if err != nil {
@@ -123,7 +166,9 @@ type CrawlSession struct {
 	Hostname   string
 	Queue      *queue.Queue
 	HttpClient *retryablehttp.Client
-	Browser    *rod.Browser
+	// Jar holds the cookies of the crawl session, shared by every request to the site
+	Jar     *session.Jar
+	Browser *rod.Browser
 }
 
 func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
@@ -173,6 +218,18 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 	if err != nil {
 		return nil, errorutil.New("error: 不能创建HTTP客户端").Wrap(err)
 	}
+	jar, _ := httpclient.HTTPClient.Jar.(*session.Jar)
+	if jar != nil {
+		// the jar is no longer seeded once the crawl session ends
+		unregister := s.Options.Options.SessionMonitor.OnRefresh(func(state *session.State) {
+			state.Seed(jar)
+		})
+		cancelSession := cancel
+		cancel = func() {
+			cancelSession()
+			unregister()
+		}
+	}
 	crawlSession := &CrawlSession{
 		Ctx:        ctx,
 		CancelFunc: cancel,
@@ -180,38 +237,82 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 		Hostname:   hostname,
 		Queue:      queue,
 		HttpClient: httpclient,
+		Jar:        jar,
+	}
+	if jarPath := s.cookieJarPath(crawlSession); jarPath != "" && fileutil.FileExists(jarPath) {
+		if err := jar.Load(jarPath); err != nil {
+			log.Println(chalk.Red.Color("error: 加载Cookie文件出错, " + jarPath + ", " + err.Error()))
+		}
 	}
 	return crawlSession, nil
 }
 
+// SaveCookieJar persists the cookies of the crawl session if a cookie jar directory is set
+func (s *Shared) SaveCookieJar(crawlSession *CrawlSession) {
+	jarPath := s.cookieJarPath(crawlSession)
+	if jarPath == "" {
+		return
+	}
+	if err := crawlSession.Jar.Save(jarPath); err != nil {
+		log.Println(chalk.Red.Color("error: 保存Cookie文件出错, " + jarPath + ", " + err.Error()))
+	}
+}
+
+// cookieJarPath returns the file the cookies of the crawl session are persisted to
+func (s *Shared) cookieJarPath(crawlSession *CrawlSession) string {
+	dir := s.Options.Options.CookieJarDir
+	if dir == "" || crawlSession.Jar == nil {
+		return ""
+	}
+	return filepath.Join(dir, strings.ReplaceAll(crawlSession.URL.Host, ":", "_")+".json")
+}
+
+// IsSessionLost returns true if the response matches the logged-out signature
+func (s *Shared) IsSessionLost(resp *navigation.Response) bool {
+	monitor := s.Options.Options.SessionMonitor
+	if monitor == nil || resp == nil || resp.Resp == nil {
+		return false
+	}
+	var requestURL string
+	if resp.Resp.Request != nil {
+		requestURL = resp.Resp.Request.URL.String()
+	}
+	return monitor.Check(resp.StatusCode, requestURL, resp.Resp.Header.Get("Location"), resp.Body)
+}
+
+// crawlable returns true if the request is a URL in the scope of the crawl session
+func (s *Shared) crawlable(crawlSession *CrawlSession, req *navigation.Request) bool {
+	if !utils.IsURL(req.URL) {
+		return false
+	}
+	if ok, err := s.Options.ValidateScope(req.URL, crawlSession.Hostname); err != nil || !ok {
+		return false
+	}
+	return s.Options.ValidatePath(req.URL)
+}
+
 type DoRequestFunc func(crawlSession *CrawlSession, req *navigation.Request) (*navigation.Response, error)
 
 func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 	wg := sizedwaitgroup.New(s.Options.Options.Concurrency)
 	for item := range crawlSession.Queue.Pop() {
 		if ctxErr := crawlSession.Ctx.Err(); ctxErr != nil {
+			crawlSession.Queue.Release()
 			return ctxErr
 		}
 
 		req, ok := item.(*navigation.Request)
-		if !ok {
-			continue
-		}
-
-		if !utils.IsURL(req.URL) {
-			continue
-		}
-
-		if ok, err := s.Options.ValidateScope(req.URL, crawlSession.Hostname); err != nil || !ok {
-			continue
-		}
-		if !s.Options.ValidatePath(req.URL) {
+		if !ok || !s.crawlable(crawlSession, req) {
+			crawlSession.Queue.Release()
 			continue
 		}
 
 		wg.Add()
 		go func() {
 			defer wg.Done()
+			// the queue is kept open until the request is released, the
+			// re-authentication can take longer than the queue timeout
+			defer crawlSession.Queue.Release()
 
 			s.Options.RateLimit.Take()
 
@@ -220,8 +321,25 @@ func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 				time.Sleep(time.Duration(s.Options.Options.Delay) * time.Second)
 			}
 
+			generation := s.Options.Options.SessionMonitor.Wait()
 			resp, err := doRequest(crawlSession, req)
 
+			// the session was lost, re-authenticate and requeue the request
+			if err == nil && s.IsSessionLost(resp) && req.ReauthCount < session.MaxReauthRetries {
+				log.Println(chalk.Yellow.Color("检测到会话失效, 重新认证: " + req.URL))
+				if reauthErr := s.Options.Options.SessionMonitor.Reauthenticate(generation); reauthErr != nil {
+					log.Println(chalk.Red.Color("error: 重新认证失败, " + reauthErr.Error()))
+				}
+				req.ReauthCount++
+				crawlSession.Queue.Push(req, req.Depth)
+				return
+			}
+
+			// soft-404 responses are dropped with the links they hold
+			if err == nil && s.DropSoft404(crawlSession, req, resp) {
+				return
+			}
+
 			s.Output(req, resp, err)
 
 			if err != nil {
@@ -240,6 +358,11 @@ func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 
 			navigationRequests := parser.ParseResponse(resp)
 			s.Enqueue(crawlSession.Queue, navigationRequests...)
+			s.Enqueue(crawlSession.Queue, s.SourceMapRequests(crawlSession, resp)...)
+			s.Enqueue(crawlSession.Queue, s.RecordGraphQL(crawlSession, req, resp)...)
+			s.Enqueue(crawlSession.Queue, s.GraphQLRequests(crawlSession, resp)...)
+			s.Enqueue(crawlSession.Queue, s.ServiceRequests(crawlSession, resp)...)
+			s.ArchiveScripts(req.Source, resp)
 		}()
 	}
 	wg.Wait()
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/output"
+	"Venom-Crawler/pkg/katana/types"
+	"Venom-Crawler/pkg/katana/utils/filters"
+	"Venom-Crawler/pkg/katana/utils/queue"
+	"Venom-Crawler/pkg/katana/utils/scope"
+	"Venom-Crawler/pkg/session"
+
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestShared returns a Shared crawling every URL up to depth 3
+func newTestShared(t *testing.T) *Shared {
+	uniqueFilter, err := filters.NewSimple()
+	assert.Nil(t, err)
+	t.Cleanup(uniqueFilter.Close)
+	scopeManager, err := scope.NewManager(nil, nil, "rdn", true)
+	assert.Nil(t, err)
+	return &Shared{Options: &types.CrawlerOptions{
+		Options:      &types.Options{MaxDepth: 3},
+		UniqueFilter: uniqueFilter,
+		ScopeManager: scopeManager,
+	}}
+}
+
+// drain returns the method, URL and body of the requests left in the queue
+func drain(q *queue.Queue) []string {
+	var requests []string
+	for item := range q.Pop() {
+		req := item.(*navigation.Request)
+		requests = append(requests, req.Method+" "+req.URL+" "+req.Body)
+		q.Release()
+	}
+	return requests
+}
+
+func TestEnqueue(t *testing.T) {
+	s := newTestShared(t)
+	q, err := queue.New("breadth-first", 0)
+	assert.Nil(t, err)
+
+	s.Enqueue(q,
+		&navigation.Request{Method: "PUT", URL: "https://example.com/api/users/1", Body: `{"name":"1"}`},
+		&navigation.Request{Method: "PUT", URL: "https://example.com/api/users/2", Body: `{"name":"1"}`},
+		&navigation.Request{Method: "PUT", URL: "https://example.com/api/users/2", Body: `{"name":"2"}`},
+		&navigation.Request{Method: "PUT", URL: "https://example.com/api/users/2", Body: `{"name":"2"}`},
+		&navigation.Request{Method: "DELETE", URL: "https://example.com/api/users/2"},
+		&navigation.Request{Method: "GET", URL: "https://example.com/api/users/2"},
+	)
+	assert.ElementsMatch(t, []string{
+		`PUT https://example.com/api/users/1 {"name":"1"}`,
+		`PUT https://example.com/api/users/2 {"name":"1"}`,
+		`PUT https://example.com/api/users/2 {"name":"2"}`,
+		"DELETE https://example.com/api/users/2 ",
+		"GET https://example.com/api/users/2 ",
+	}, drain(q), "should dedupe on the method, URL and body")
+}
+
+// resultWriter records the requests written to the output
+type resultWriter struct {
+	sync.Mutex
+	requests []string
+}
+
+func (w *resultWriter) Close() error { return nil }
+
+func (w *resultWriter) Write(result *output.Result) error {
+	w.Lock()
+	defer w.Unlock()
+	w.requests = append(w.requests, result.Request.URL)
+	return nil
+}
+
+func (w *resultWriter) WriteErr(*output.Error) error { return nil }
+
+func TestDo_Reauthenticate(t *testing.T) {
+	s := newTestShared(t)
+	writer := &resultWriter{}
+	s.Options.OutputWriter = writer
+	s.Options.RateLimit = *ratelimit.NewUnlimited(context.Background())
+	s.Options.Options.Concurrency = 1
+	// the re-login outlasts the queue timeout
+	s.Options.Options.SessionMonitor = session.NewMonitor(&session.Signature{LoginURL: "/login"}, session.NewState(), func() (*session.State, error) {
+		time.Sleep(2 * time.Second)
+		return nil, nil
+	})
+
+	q, err := queue.New("breadth-first", 0)
+	assert.Nil(t, err)
+	q.Push(&navigation.Request{Method: "GET", URL: "https://example.com/account"}, 0)
+	crawlSession := &CrawlSession{Ctx: context.Background(), Hostname: "example.com", Queue: q}
+
+	loggedIn := false
+	err = s.Do(crawlSession, func(_ *CrawlSession, req *navigation.Request) (*navigation.Response, error) {
+		location := "https://example.com/login"
+		if loggedIn {
+			location = req.URL
+		}
+		loggedIn = true
+		requestURL, _ := url.Parse(location)
+		return &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: requestURL}, Header: http.Header{}}}, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://example.com/account"}, writer.requests, "should send the request again once re-authenticated")
+}
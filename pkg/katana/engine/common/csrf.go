@@ -0,0 +1,127 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+
+	"github.com/PuerkitoBio/goquery"
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// RefreshFormTokens re-fetches the page a form request was discovered on and
+// substitutes the anti-CSRF fields of the request with their fresh values.
+// The page is fetched with a copy of the crawl session client, so a token
+// bound to a cookie is sent back with the matching cookie from the session
+// jar. The copy follows redirects without parsing and enqueueing them.
+func (s *Shared) RefreshFormTokens(crawlSession *CrawlSession, request *navigation.Request) error {
+	if !IsFormRequest(request) {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(crawlSession.Ctx, http.MethodGet, request.Source, nil)
+	if err != nil {
+		return errorutil.NewWithTag("csrf", "could not create form page request").Wrap(err)
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	client := *crawlSession.HttpClient.HTTPClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) == 10 {
+			return errorutil.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errorutil.NewWithTag("csrf", "could not fetch form page").Wrap(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+	if err != nil {
+		return errorutil.NewWithTag("csrf", "could not read form page").Wrap(err)
+	}
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return errorutil.NewWithTag("csrf", "could not parse form page").Wrap(err)
+	}
+
+	return s.ApplyFormTokens(request, document, resp.Request.URL)
+}
+
+// IsFormRequest returns true if request submits a form found on a page,
+// its anti-CSRF fields can be refreshed from that page
+func IsFormRequest(request *navigation.Request) bool {
+	return request.Tag == "form" && request.Source != ""
+}
+
+// ApplyFormTokens substitutes the anti-CSRF fields of a form request with
+// the values of the matching form in the document of pageURL
+func (s *Shared) ApplyFormTokens(request *navigation.Request, document *goquery.Document, pageURL *url.URL) error {
+	patterns := s.Options.Options.CSRFTokenPatterns
+	if len(patterns) == 0 {
+		patterns = utils.DefaultCSRFTokenPatterns
+	}
+	tokens := findFormTokens(document, pageURL, request, patterns)
+	if len(tokens) == 0 {
+		return nil
+	}
+	switch request.Method {
+	case http.MethodGet:
+		parsed, err := url.Parse(request.URL)
+		if err != nil {
+			return err
+		}
+		parsed.RawQuery, err = utils.ReplaceQueryValues(parsed.RawQuery, tokens)
+		if err != nil {
+			return err
+		}
+		request.URL = parsed.String()
+	default:
+		body, err := utils.ReplaceFormValues(request.Body, request.Headers["Content-Type"], tokens)
+		if err != nil {
+			return err
+		}
+		request.Body = body
+	}
+	return nil
+}
+
+// findFormTokens returns the fresh tokens of the first form on the page
+// submitting to the same action with the same method as request
+func findFormTokens(document *goquery.Document, pageURL *url.URL, request *navigation.Request, patterns []string) map[string]string {
+	target := stripQuery(request.URL)
+	var tokens map[string]string
+	document.Find("form").EachWithBreak(func(_ int, form *goquery.Selection) bool {
+		method, _ := form.Attr("method")
+		if method == "" {
+			method = http.MethodGet
+		}
+		if !strings.EqualFold(method, request.Method) {
+			return true
+		}
+		action, _ := form.Attr("action")
+		actionURL, err := pageURL.Parse(action)
+		if err != nil || stripQuery(actionURL.String()) != target {
+			return true
+		}
+		if found := utils.ExtractCSRFTokens(form, patterns); len(found) > 0 {
+			tokens = found
+			return false
+		}
+		return true
+	})
+	return tokens
+}
+
+func stripQuery(rawURL string) string {
+	if index := strings.IndexAny(rawURL, "?#"); index != -1 {
+		return rawURL[:index]
+	}
+	return rawURL
+}
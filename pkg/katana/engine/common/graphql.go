@@ -0,0 +1,142 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// GraphQLRequests returns a request for every GraphQL operation defined in
+// a script response or in the inline scripts of a page, sent to the
+// endpoints the scripts configure or to /graphql. The endpoints are
+// introspected when enabled.
+func (s *Shared) GraphQLRequests(crawlSession *CrawlSession, resp *navigation.Response) []*navigation.Request {
+	recorder := s.Options.Options.GraphQL
+	if recorder == nil || resp.Resp == nil || resp.Resp.Request == nil {
+		return nil
+	}
+	var scripts []string
+	if isScriptResponse(resp.Resp) {
+		scripts = append(scripts, resp.Body)
+	} else if strings.Contains(resp.Resp.Header.Get("Content-Type"), "html") {
+		scripts = jsarchive.InlineScripts(resp.Body)
+	}
+
+	var navigationRequests []*navigation.Request
+	for _, script := range scripts {
+		operations, endpoints := graphql.ScanScript(script)
+		if len(operations) == 0 {
+			continue
+		}
+		if len(endpoints) == 0 {
+			endpoints = append(endpoints, graphql.DefaultPath)
+		}
+		for _, endpoint := range endpoints {
+			endpoint = resp.AbsoluteURL(endpoint)
+			if endpoint == "" {
+				continue
+			}
+			for _, operation := range operations {
+				operation.Endpoint = endpoint
+				if recorder.Add(operation) {
+					navigationRequests = append(navigationRequests, newGraphQLRequest(operation, resp))
+				}
+			}
+			navigationRequests = append(navigationRequests, s.introspectGraphQL(crawlSession, endpoint, resp)...)
+		}
+	}
+	return navigationRequests
+}
+
+// RecordGraphQL records the GraphQL operations sent by a request of the
+// page and returns a request for each operation of a batched request. The
+// endpoint is introspected when enabled.
+func (s *Shared) RecordGraphQL(crawlSession *CrawlSession, request *navigation.Request, resp *navigation.Response) []*navigation.Request {
+	recorder := s.Options.Options.GraphQL
+	if recorder == nil {
+		return nil
+	}
+	operations := graphql.ParseRequest(request.Method, request.URL, request.Body)
+	if len(operations) == 0 {
+		return nil
+	}
+	var navigationRequests []*navigation.Request
+	for _, operation := range operations {
+		if recorder.Add(operation) && len(operations) > 1 {
+			navigationRequests = append(navigationRequests, newGraphQLRequest(operation, resp))
+		}
+	}
+	return append(navigationRequests, s.introspectGraphQL(crawlSession, operations[0].Endpoint, resp)...)
+}
+
+// introspectGraphQL runs the introspection query against an endpoint and
+// returns a request for every query of its schema, and for the mutations
+// when enabled. Every operation is recorded and every endpoint is
+// introspected once across both engines.
+func (s *Shared) introspectGraphQL(crawlSession *CrawlSession, endpoint string, resp *navigation.Response) []*navigation.Request {
+	recorder := s.Options.Options.GraphQL
+	if !recorder.ClaimIntrospection(endpoint) {
+		return nil
+	}
+	operations, err := graphql.Introspect(endpoint, func(URL string, body []byte) (int, []byte, error) {
+		return s.postGraphQL(crawlSession, URL, body)
+	})
+	if err != nil {
+		return nil
+	}
+	var navigationRequests []*navigation.Request
+	for _, operation := range operations {
+		if recorder.Add(operation) && recorder.Replay(operation) {
+			navigationRequests = append(navigationRequests, newGraphQLRequest(operation, resp))
+		}
+	}
+	return navigationRequests
+}
+
+// postGraphQL sends a JSON body to a GraphQL endpoint
+func (s *Shared) postGraphQL(crawlSession *CrawlSession, URL string, body []byte) (int, []byte, error) {
+	req, err := retryablehttp.NewRequestWithContext(crawlSession.Ctx, http.MethodPost, URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, errorutil.NewWithTag("graphql", "could not create introspection request").Wrap(err)
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := crawlSession.HttpClient.Do(req)
+	if err != nil {
+		return 0, nil, errorutil.NewWithTag("graphql", "could not send introspection request").Wrap(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+	return resp.StatusCode, data, err
+}
+
+// newGraphQLRequest returns a POST request sending an operation
+func newGraphQLRequest(operation graphql.Operation, resp *navigation.Response) *navigation.Request {
+	source := ""
+	if resp.Resp != nil && resp.Resp.Request != nil {
+		source = resp.Resp.Request.URL.String()
+	}
+	return &navigation.Request{
+		Method:       http.MethodPost,
+		URL:          operation.Endpoint,
+		Body:         operation.Body(),
+		Headers:      map[string]string{"Content-Type": "application/json"},
+		Depth:        resp.Depth,
+		RootHostname: resp.RootHostname,
+		Source:       source,
+		Tag:          "graphql",
+		Attribute:    operation.Source,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"time"
 
+	"Venom-Crawler/pkg/auth"
 	"Venom-Crawler/pkg/katana/navigation"
 	"Venom-Crawler/pkg/katana/types"
 
@@ -23,6 +24,8 @@ func BuildHttpClient(dialer *fastdialer.Dialer, options *types.Options, redirect
 	retryablehttpOptions := retryablehttp.DefaultOptionsSingle
 	retryablehttpOptions.RetryMax = options.Retries
 	transport := &http.Transport{
+		// the fastdialer resolves the mapped hosts to their fixed IP,
+		// TLS on top of it keeps the real hostname for SNI
 		DialContext:         dialer.Dial,
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 10,
@@ -39,11 +42,19 @@ func BuildHttpClient(dialer *fastdialer.Dialer, options *types.Options, redirect
 		if ok, err := proxyutil.IsBurp(options.Proxy); err == nil && ok {
 			transport.TLSClientConfig.MaxVersion = tls.VersionTLS12
 		}
+		if credential, ok := options.Credentials.Proxy(); ok && !credential.Cancel && proxyURL.User == nil {
+			proxyURL.User = url.UserPassword(credential.Username, credential.Password)
+		}
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
+	var roundTripper http.RoundTripper = transport
+	if options.Credentials != nil {
+		roundTripper = &auth.Transport{Base: transport, Store: options.Credentials}
+	}
+
 	client := retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport: transport,
+		Transport: roundTripper,
 		Timeout:   time.Duration(options.Timeout) * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) == 10 {
@@ -59,6 +70,16 @@ func BuildHttpClient(dialer *fastdialer.Dialer, options *types.Options, redirect
 			return nil
 		},
 	}, retryablehttpOptions)
+	// every client gets its own jar so Set-Cookie responses are sent back
+	// on redirects and later requests, seeded with the user session if any
+	jar, err := options.Session.Jar()
+	if err != nil {
+		return nil, nil, errorutil.NewWithErr(err).Msgf("could not create cookie jar")
+	}
+	client.HTTPClient.Jar = jar
+	if client.HTTPClient2 != nil {
+		client.HTTPClient2.Jar = jar
+	}
 	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
 	return client, dialer, nil
 }
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"log"
+	"strings"
+
+	"Venom-Crawler/pkg/katana/navigation"
+
+	"github.com/ttacon/chalk"
+)
+
+// ArchiveScripts saves the script of a javascript response, or the inline
+// scripts of an html response, to the javascript archive. page is the page
+// the response was loaded by.
+func (s *Shared) ArchiveScripts(page string, resp *navigation.Response) {
+	archive := s.Options.Options.JSArchive
+	if archive == nil || resp.Resp == nil || resp.Resp.Request == nil || resp.Body == "" {
+		return
+	}
+	responseURL := resp.Resp.Request.URL.String()
+	var err error
+	switch {
+	case isScriptResponse(resp.Resp):
+		err = archive.Add(page, responseURL, []byte(resp.Body))
+	case strings.Contains(resp.Resp.Header.Get("Content-Type"), "html"):
+		err = archive.AddInline(responseURL, resp.Body)
+	}
+	if err != nil {
+		log.Println(chalk.Red.Color("error: JS文件保存失败, " + err.Error()))
+	}
+}
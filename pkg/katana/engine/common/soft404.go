@@ -0,0 +1,64 @@
+package common
+
+import (
+	"io"
+	"net/http"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+	"Venom-Crawler/pkg/soft404"
+
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// DropSoft404 checks the response of a GET request against the responses
+// of non-existent paths of its directory and returns true if it is a
+// soft-404 to drop. Redirected responses are not checked, the baselines
+// are built without following redirects.
+func (s *Shared) DropSoft404(crawlSession *CrawlSession, req *navigation.Request, resp *navigation.Response) bool {
+	detector := s.Options.Options.Soft404
+	if detector == nil || resp == nil || resp.Resp == nil || resp.Resp.Request == nil {
+		return false
+	}
+	if req.Method != "" && req.Method != http.MethodGet {
+		return false
+	}
+	if resp.Resp.Request.URL.String() != req.URL {
+		return false
+	}
+	probe := func(URL string) (soft404.Response, error) {
+		return s.probeSoft404(crawlSession, URL)
+	}
+	matched := detector.Check(req.URL, soft404.Response{
+		StatusCode: resp.StatusCode,
+		Location:   resp.Resp.Header.Get("Location"),
+		Body:       resp.Body,
+	}, probe)
+	return matched && detector.Dropping()
+}
+
+// probeSoft404 requests a non-existent path without following redirects
+func (s *Shared) probeSoft404(crawlSession *CrawlSession, URL string) (soft404.Response, error) {
+	req, err := http.NewRequestWithContext(crawlSession.Ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return soft404.Response{}, errorutil.NewWithTag("soft404", "could not create probe request").Wrap(err)
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	client := *crawlSession.HttpClient.HTTPClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return soft404.Response{}, errorutil.NewWithTag("soft404", "could not request probe").Wrap(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+	if err != nil {
+		return soft404.Response{}, errorutil.NewWithTag("soft404", "could not read probe").Wrap(err)
+	}
+	return soft404.Response{StatusCode: resp.StatusCode, Location: resp.Header.Get("Location"), Body: string(data)}, nil
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"Venom-Crawler/pkg/katana/engine/parser"
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+	"Venom-Crawler/pkg/sourcemap"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// SourceMapRequests retrieves the source map of a script response, records
+// the original sources it embeds and returns the endpoints extracted from
+// them. Every script and map is processed once across both engines.
+func (s *Shared) SourceMapRequests(crawlSession *CrawlSession, resp *navigation.Response) []*navigation.Request {
+	recorder := s.Options.Options.SourceMaps
+	if recorder == nil || resp.Resp == nil || resp.Resp.Request == nil || !isScriptResponse(resp.Resp) {
+		return nil
+	}
+	scriptURL := resp.Resp.Request.URL.String()
+	if !recorder.Claim(scriptURL) {
+		return nil
+	}
+	for _, mapURL := range sourcemap.Locate(scriptURL, resp.Resp.Header, resp.Body) {
+		if !recorder.Claim(mapURL) {
+			continue
+		}
+		data, err := s.fetchSourceMap(crawlSession, mapURL)
+		if err != nil {
+			continue
+		}
+		m, err := sourcemap.Parse(data)
+		if err != nil {
+			continue
+		}
+		endpoints := m.Endpoints()
+		recorder.Record(scriptURL, mapURL, m, endpoints)
+		return parser.JSEndpointRequests(endpoints, "sourcemap", resp)
+	}
+	return nil
+}
+
+// fetchSourceMap returns the content of a source map, inline maps are decoded
+func (s *Shared) fetchSourceMap(crawlSession *CrawlSession, mapURL string) ([]byte, error) {
+	if data, ok := sourcemap.DecodeDataURL(mapURL); ok {
+		return data, nil
+	}
+	req, err := retryablehttp.NewRequestWithContext(crawlSession.Ctx, http.MethodGet, mapURL, nil)
+	if err != nil {
+		return nil, errorutil.NewWithTag("sourcemap", "could not create source map request").Wrap(err)
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := crawlSession.HttpClient.Do(req)
+	if err != nil {
+		return nil, errorutil.NewWithTag("sourcemap", "could not fetch source map").Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorutil.NewWithTag("sourcemap", "source map not found")
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+}
+
+// isScriptResponse returns true if the response is a javascript file
+func isScriptResponse(resp *http.Response) bool {
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "javascript") || strings.Contains(contentType, "ecmascript") {
+		return true
+	}
+	return strings.HasSuffix(resp.Request.URL.Path, ".js")
+}
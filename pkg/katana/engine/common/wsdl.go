@@ -0,0 +1,70 @@
+package common
+
+import (
+	"io"
+	"net/http"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+	"Venom-Crawler/pkg/wsdl"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// ServiceRequests returns an example request for every operation of the
+// WSDL or WADL description crawled, or of the descriptions of the SOAP
+// endpoint or service listing crawled. Every description is parsed once
+// across both engines.
+func (s *Shared) ServiceRequests(crawlSession *CrawlSession, resp *navigation.Response) []*navigation.Request {
+	catalog := s.Options.Options.Services
+	if catalog == nil || resp.Resp == nil || resp.Resp.Request == nil {
+		return nil
+	}
+	fetch := func(URL string) (int, []byte, error) {
+		return s.fetchDescription(crawlSession, URL)
+	}
+	var navigationRequests []*navigation.Request
+	for _, operation := range catalog.Discover(resp.Resp.Request.URL.String(), []byte(resp.Body), fetch) {
+		navigationRequests = append(navigationRequests, newServiceRequest(operation, resp))
+	}
+	return navigationRequests
+}
+
+// fetchDescription requests a service description
+func (s *Shared) fetchDescription(crawlSession *CrawlSession, URL string) (int, []byte, error) {
+	req, err := retryablehttp.NewRequestWithContext(crawlSession.Ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return 0, nil, errorutil.NewWithTag("wsdl", "could not create description request").Wrap(err)
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := crawlSession.HttpClient.Do(req)
+	if err != nil {
+		return 0, nil, errorutil.NewWithTag("wsdl", "could not request description").Wrap(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+	return resp.StatusCode, data, err
+}
+
+// newServiceRequest returns the example request of a service operation
+func newServiceRequest(operation wsdl.Operation, resp *navigation.Response) *navigation.Request {
+	source := ""
+	if resp.Resp != nil && resp.Resp.Request != nil {
+		source = resp.Resp.Request.URL.String()
+	}
+	return &navigation.Request{
+		Method:       operation.Request.Method,
+		URL:          resp.AbsoluteURL(operation.Request.URL),
+		Body:         operation.Request.Body,
+		Headers:      operation.Request.Headers,
+		Depth:        resp.Depth,
+		RootHostname: resp.RootHostname,
+		Source:       source,
+		Tag:          "wsdl",
+		Attribute:    operation.Name,
+	}
+}
@@ -0,0 +1,133 @@
+package hybrid
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Venom-Crawler/pkg/katana/engine/common"
+	"Venom-Crawler/pkg/session"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ttacon/chalk"
+)
+
+// seedBrowserCookies sets the cookies loaded into the crawl session jar in
+// the browser, so the pages are crawled with them
+func (c *Crawler) seedBrowserCookies(s *common.CrawlSession) {
+	if s.Jar == nil || c.Options.Options.CookieJarDir == "" {
+		return
+	}
+	cookies := toNetworkCookieParams(s.Jar.Export())
+	if len(cookies) == 0 {
+		return
+	}
+	if err := s.Browser.SetCookies(cookies); err != nil {
+		log.Println(chalk.Red.Color("error: 设置浏览器Cookie出错, " + err.Error()))
+	}
+}
+
+// copyBrowserCookies copies the cookies the browser holds for the crawled
+// site into the crawl session jar before it is saved
+func (c *Crawler) copyBrowserCookies(s *common.CrawlSession) {
+	if s.Jar == nil || c.Options.Options.CookieJarDir == "" {
+		return
+	}
+	cookies, err := s.Browser.GetCookies()
+	if err != nil {
+		log.Println(chalk.Red.Color("error: 获取浏览器Cookie出错, " + err.Error()))
+		return
+	}
+	for _, cookie := range siteCookies(cookies, s.Hostname) {
+		s.Jar.SetCookies(cookieURL(cookie.Domain, cookie.Path, cookie.Secure), []*http.Cookie{toHTTPCookie(cookie)})
+	}
+}
+
+// siteCookies returns the cookies of the browser set for hostname, its
+// parent domains or its subdomains. The browser is shared by every crawled
+// site, the cookies of the other sites are left out.
+func siteCookies(cookies []*proto.NetworkCookie, hostname string) []*proto.NetworkCookie {
+	hostname = strings.ToLower(hostname)
+	var matched []*proto.NetworkCookie
+	for _, cookie := range cookies {
+		domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+		switch {
+		case domain == hostname,
+			strings.HasPrefix(cookie.Domain, ".") && strings.HasSuffix(hostname, "."+domain),
+			strings.HasSuffix(domain, "."+hostname):
+			matched = append(matched, cookie)
+		}
+	}
+	return matched
+}
+
+// toHTTPCookie converts a browser cookie to the cookie a response would have
+// set, a host-only cookie is written by the browser without a leading dot
+func toHTTPCookie(cookie *proto.NetworkCookie) *http.Cookie {
+	httpCookie := &http.Cookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		HttpOnly: cookie.HTTPOnly,
+		Secure:   cookie.Secure,
+	}
+	if strings.HasPrefix(cookie.Domain, ".") {
+		httpCookie.Domain = cookie.Domain
+	}
+	if !cookie.Session && cookie.Expires > 0 {
+		httpCookie.Expires = time.Unix(int64(cookie.Expires), 0)
+	}
+	switch cookie.SameSite {
+	case proto.NetworkCookieSameSiteStrict:
+		httpCookie.SameSite = http.SameSiteStrictMode
+	case proto.NetworkCookieSameSiteLax:
+		httpCookie.SameSite = http.SameSiteLaxMode
+	case proto.NetworkCookieSameSiteNone:
+		httpCookie.SameSite = http.SameSiteNoneMode
+	}
+	return httpCookie
+}
+
+// cookieURL returns the URL a cookie of domain and path is set from
+func cookieURL(domain, path string, secure bool) *url.URL {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	if path == "" {
+		path = "/"
+	}
+	return &url.URL{Scheme: scheme, Host: strings.TrimPrefix(domain, "."), Path: path}
+}
+
+// toNetworkCookieParams converts session cookies to browser cookies. A domain
+// without a leading dot is host-only, it is set through the URL of the host
+// since the browser turns every cookie with a domain into a domain cookie.
+func toNetworkCookieParams(cookies []session.Cookie) []*proto.NetworkCookieParam {
+	var params []*proto.NetworkCookieParam
+	for _, cookie := range cookies {
+		param := &proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: proto.NetworkCookieSameSite(cookie.SameSite),
+		}
+		if param.Path == "" {
+			param.Path = "/"
+		}
+		if strings.HasPrefix(cookie.Domain, ".") {
+			param.Domain = cookie.Domain
+		} else {
+			param.URL = cookieURL(cookie.Domain, param.Path, cookie.Secure).String()
+		}
+		if cookie.Expires > 0 {
+			param.Expires = proto.TimeSinceEpoch(cookie.Expires)
+		}
+		params = append(params, param)
+	}
+	return params
+}
@@ -0,0 +1,56 @@
+package hybrid
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"Venom-Crawler/pkg/session"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteCookies(t *testing.T) {
+	cookies := []*proto.NetworkCookie{
+		{Name: "host", Domain: "app.example.com"},
+		{Name: "domain", Domain: ".example.com"},
+		{Name: "sub", Domain: "api.app.example.com"},
+		{Name: "sibling", Domain: "www.example.com"},
+		{Name: "other", Domain: ".other.com"},
+	}
+	var names []string
+	for _, cookie := range siteCookies(cookies, "app.example.com") {
+		names = append(names, cookie.Name)
+	}
+	assert.Equal(t, []string{"host", "domain", "sub"}, names)
+}
+
+func TestToHTTPCookie(t *testing.T) {
+	jar, err := session.NewJar()
+	assert.Nil(t, err)
+	for _, cookie := range []*proto.NetworkCookie{
+		{Name: "host", Value: "1", Domain: "app.example.com", Path: "/", Session: true},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/", Secure: true, Expires: 4102444800, SameSite: proto.NetworkCookieSameSiteLax},
+	} {
+		jar.SetCookies(cookieURL(cookie.Domain, cookie.Path, cookie.Secure), []*http.Cookie{toHTTPCookie(cookie)})
+	}
+	assert.ElementsMatch(t, []session.Cookie{
+		{Name: "host", Value: "1", Domain: "app.example.com", Path: "/"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/", Secure: true, Expires: 4102444800, SameSite: "Lax"},
+	}, jar.Export())
+
+	api, _ := url.Parse("https://api.example.com/")
+	assert.Len(t, jar.Cookies(api), 1, "should keep the host-only cookie on its host")
+}
+
+func TestToNetworkCookieParams(t *testing.T) {
+	params := toNetworkCookieParams([]session.Cookie{
+		{Name: "host", Value: "1", Domain: "app.example.com", Secure: true},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/api", Expires: 4102444800},
+	})
+	assert.Equal(t, []*proto.NetworkCookieParam{
+		{Name: "host", Value: "1", URL: "https://app.example.com/", Path: "/", Secure: true},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/api", Expires: 4102444800},
+	}, params)
+}
@@ -12,6 +12,7 @@ import (
 	"net/http/httputil"
 
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"Venom-Crawler/pkg/katana/navigation"
@@ -32,6 +33,11 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 		Depth:        depth,
 		RootHostname: s.Hostname,
 	}
+	if c.Options.Options.RefreshCSRFTokens {
+		if err := c.refreshFormTokens(s, request); err != nil {
+			log.Println(chalk.Yellow.Color("warning: 刷新表单CSRF令牌出错, " + request.URL + ", " + err.Error()))
+		}
+	}
 
 	page, err := s.Browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
@@ -39,17 +45,57 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 	}
 	defer page.Close()
 	c.addHeadersToPage(page)
+	c.addSessionToPage(page)
+
+	// interacting is set while the interaction phase runs, requests sent
+	// during it are captured and the page is kept from navigating away
+	var interacting atomic.Bool
+	interact := c.Options.Options.AutomaticFormFill
+	if interact {
+		if _, err := page.EvalOnNewDocument(interactionHookJS); err != nil {
+			log.Println(chalk.Red.Color("error: 注入交互脚本出错, " + err.Error()))
+		}
+	}
 
 	pageRouter := NewHijack(page)
 	pageRouter.SetPattern(&proto.FetchRequestPattern{
 		URLPattern:   "*",
 		RequestStage: proto.FetchRequestStageResponse,
 	})
+	if c.Options.Options.Rewrite.HasRequestRules() || !c.Options.Options.Block.Empty() {
+		pageRouter.AddPattern(&proto.FetchRequestPattern{
+			URLPattern:   "*",
+			RequestStage: proto.FetchRequestStageRequest,
+		})
+	} else if interact {
+		pageRouter.AddPattern(&proto.FetchRequestPattern{
+			URLPattern:   "*",
+			ResourceType: proto.NetworkResourceTypeDocument,
+			RequestStage: proto.FetchRequestStageRequest,
+		})
+	}
 	go pageRouter.Start(func(e *proto.FetchRequestPaused) error {
+		// request stage, only paused for the interaction phase, the rewrite rules and the blocking policy
+		if e.ResponseStatusCode == nil && e.ResponseErrorReason == "" {
+			mainDocument := e.ResourceType == proto.NetworkResourceTypeDocument && e.FrameID == page.FrameID
+			if interacting.Load() && mainDocument {
+				c.Enqueue(s.Queue, newHijackedRequest(e, "interaction", request.URL, depth, s.Hostname))
+				return FetchFailRequest(page, e)
+			}
+			if !mainDocument && c.Options.Options.Block.Block(e.Request.URL, string(e.ResourceType)) {
+				return FetchFailRequest(page, e)
+			}
+			return c.continueRewrittenRequest(page, e)
+		}
 		URL, _ := urlutil.Parse(e.Request.URL)
 		body, _ := FetchGetResponseBody(page, e)
+		rewrittenHeaders, body, rewritten := c.rewriteResponse(e, body)
+		responseHeaders := e.ResponseHeaders
+		if rewritten {
+			responseHeaders = rewrittenHeaders
+		}
 		headers := make(map[string][]string)
-		for _, h := range e.ResponseHeaders {
+		for _, h := range responseHeaders {
 			headers[h.Name] = []string{h.Value}
 		}
 		var (
@@ -114,9 +160,32 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 			response = resp
 		}
 
+		// API calls made by the page JavaScript are endpoints of their own
+		if e.ResourceType == proto.NetworkResourceTypeXHR || e.ResourceType == proto.NetworkResourceTypeFetch {
+			tag := "xhr"
+			if interacting.Load() {
+				tag = "interaction"
+			}
+			hijacked := newHijackedRequest(e, tag, request.URL, depth, s.Hostname)
+			c.Emit(hijacked, resp)
+			go func() {
+				c.Enqueue(s.Queue, c.RecordGraphQL(s, hijacked, resp)...)
+			}()
+		}
+
 		// process the raw response
 		navigationRequests := parser.ParseResponse(resp)
 		c.Enqueue(s.Queue, navigationRequests...)
+		// the source map, the introspection and the service descriptions are fetched aside, not to hold the hijacked request
+		go func() {
+			c.Enqueue(s.Queue, c.SourceMapRequests(s, resp)...)
+			c.Enqueue(s.Queue, c.GraphQLRequests(s, resp)...)
+			c.Enqueue(s.Queue, c.ServiceRequests(s, resp)...)
+		}()
+		c.ArchiveScripts(request.URL, resp)
+		if rewritten {
+			return FetchFulfillRequest(page, e, rewrittenHeaders, body)
+		}
 		return FetchContinueRequest(page, e)
 	})() //nolint
 	defer func() {
@@ -126,6 +195,7 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 	}()
 
 	timeout := time.Duration(c.Options.Options.Timeout) * time.Second
+	interactPage := page
 	page = page.Timeout(timeout)
 
 	// wait the page to be fully loaded and becoming idle
@@ -137,6 +207,12 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 
 	waitNavigation()
 
+	if interact {
+		interacting.Store(true)
+		c.interact(interactPage.Timeout(timeout))
+		interacting.Store(false)
+	}
+
 	var getDocumentDepth = int(-1)
 	getDocument := &proto.DOMGetDocument{Depth: &getDocumentDepth, Pierce: true}
 	result, err := getDocument.Call(page)
@@ -195,6 +271,24 @@ func (c *Crawler) addHeadersToPage(page *rod.Page) {
 	}
 }
 
+// addSessionToPage applies the authenticated session cookies and web storage to the page
+func (c *Crawler) addSessionToPage(page *rod.Page) {
+	state := c.Options.Options.Session
+	if state == nil {
+		return
+	}
+	if cookies := toNetworkCookieParams(state.GetCookies()); len(cookies) > 0 {
+		if err := page.SetCookies(cookies); err != nil {
+			log.Println(chalk.Red.Color("error: 设置浏览器Cookie出错, " + err.Error()))
+		}
+	}
+	if script := state.StorageInjectJS(); script != "" {
+		if _, err := page.EvalOnNewDocument(script); err != nil {
+			log.Println(chalk.Red.Color("error: 设置浏览器Storage出错, " + err.Error()))
+		}
+	}
+}
+
 // traverseDOMNode performs traversal of node completely building a pseudo-HTML
 // from it including the Shadow DOM, Pseudo elements and other children.
 //
@@ -0,0 +1,56 @@
+package hybrid
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"Venom-Crawler/pkg/katana/engine/common"
+	"Venom-Crawler/pkg/katana/navigation"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-rod/rod/lib/proto"
+	errorutil "github.com/projectdiscovery/utils/errors"
+)
+
+// refreshFormTokens loads the page a form request was discovered on in a new
+// page of the browser and substitutes the anti-CSRF fields of the request
+// with their fresh values. The page is loaded with the browser cookies, so
+// the token matches the session the form is then submitted with.
+func (c *Crawler) refreshFormTokens(s *common.CrawlSession, request *navigation.Request) error {
+	if !common.IsFormRequest(request) {
+		return nil
+	}
+	page, err := s.Browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return errorutil.NewWithTag("hybrid", "could not create form page target").Wrap(err)
+	}
+	defer page.Close()
+	c.addHeadersToPage(page)
+	c.addSessionToPage(page)
+
+	page = page.Timeout(time.Duration(c.Options.Options.Timeout) * time.Second)
+	if err := page.Navigate(request.Source); err != nil {
+		return errorutil.NewWithTag("hybrid", "could not navigate form page").Wrap(err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return errorutil.NewWithTag("hybrid", "could not load form page").Wrap(err)
+	}
+	info, err := page.Info()
+	if err != nil {
+		return errorutil.NewWithTag("hybrid", "could not get form page info").Wrap(err)
+	}
+	pageURL, err := url.Parse(info.URL)
+	if err != nil {
+		return errorutil.NewWithTag("hybrid", "could not parse form page url").Wrap(err)
+	}
+	body, err := page.HTML()
+	if err != nil {
+		return errorutil.NewWithTag("hybrid", "could not get form page html").Wrap(err)
+	}
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return errorutil.NewWithTag("hybrid", "could not parse form page").Wrap(err)
+	}
+	return c.ApplyFormTokens(request, document, pageURL)
+}
@@ -33,6 +33,15 @@ func (h *Hijack) SetPattern(pattern *proto.FetchRequestPattern) {
 	}
 }
 
+// AddPattern adds a pattern to the ones already set
+func (h *Hijack) AddPattern(pattern *proto.FetchRequestPattern) {
+	if h.enable == nil {
+		h.SetPattern(pattern)
+		return
+	}
+	h.enable.Patterns = append(h.enable.Patterns, pattern)
+}
+
 // Start hijack.
 func (h *Hijack) Start(handler HijackHandler) func() error {
 	if h.enable == nil {
@@ -95,3 +104,27 @@ func FetchContinueRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
 	}
 	return m.Call(page)
 }
+
+// FetchFulfillRequest answers a paused request with the given headers and body
+func FetchFulfillRequest(page *rod.Page, e *proto.FetchRequestPaused, headers []*proto.FetchHeaderEntry, body []byte) error {
+	statusCode := 200
+	if e.ResponseStatusCode != nil {
+		statusCode = *e.ResponseStatusCode
+	}
+	m := proto.FetchFulfillRequest{
+		RequestID:       e.RequestID,
+		ResponseCode:    statusCode,
+		ResponseHeaders: headers,
+		Body:            body,
+	}
+	return m.Call(page)
+}
+
+// FetchFailRequest aborts a paused request
+func FetchFailRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
+	m := proto.FetchFailRequest{
+		RequestID:   e.RequestID,
+		ErrorReason: proto.NetworkErrorReasonAborted,
+	}
+	return m.Call(page)
+}
@@ -81,6 +81,10 @@ func New(options *types.CrawlerOptions) (*Crawler, error) {
 		chromeLauncher.Set("proxy-server", proxyURL.String())
 	}
 
+	if rules := options.Options.Resolve.HostResolverRules(); rules != "" {
+		chromeLauncher.Set("host-resolver-rules", rules)
+	}
+
 	for k, v := range options.Options.ParseHeadlessOptionalArguments() {
 		chromeLauncher.Set(flags.Flag(k), v)
 	}
@@ -136,11 +140,17 @@ func (c *Crawler) Close() error {
 // Crawl crawls a URL with the specified options
 func (c *Crawler) Crawl(rootURL string) error {
 	crawlSession, err := c.NewCrawlSessionWithURL(rootURL)
-	crawlSession.Browser = c.browser
 	if err != nil {
 		return errorutil.NewWithErr(err).WithTag("hybrid")
 	}
+	crawlSession.Browser = c.browser
 	defer crawlSession.CancelFunc()
+	// the cookies set in the browser are saved with the jar
+	c.seedBrowserCookies(crawlSession)
+	defer func() {
+		c.copyBrowserCookies(crawlSession)
+		c.SaveCookieJar(crawlSession)
+	}()
 
 	if err := c.Do(crawlSession, c.navigateRequest); err != nil {
 		return errorutil.NewWithErr(err).WithTag("standard")
@@ -0,0 +1,138 @@
+package hybrid
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ttacon/chalk"
+)
+
+const (
+	// maxInteractionForms is the maximum number of forms submitted on a page
+	maxInteractionForms = 10
+	// maxInteractionClicks is the maximum number of elements clicked on a page
+	maxInteractionClicks = 50
+	// interactionWait is the time given to the requests triggered by an action to be sent
+	interactionWait = time.Second
+)
+
+// interactionHookJS marks the elements which get a click-like event listener
+// registered, so the interaction phase can find handlers added from JS.
+const interactionHookJS = `(function () {
+	const add = EventTarget.prototype.addEventListener;
+	const events = ['click', 'mousedown', 'mouseup', 'dblclick'];
+	EventTarget.prototype.addEventListener = function (type, listener, options) {
+		if (events.indexOf(type) !== -1 && this instanceof Element) {
+			this.setAttribute('data-katana-click', '');
+		}
+		return add.call(this, type, listener, options);
+	};
+})();`
+
+// formInputsJS returns the attributes of the inputs of every form on the page
+const formInputsJS = `() => Array.from(document.forms).map(form =>
+	Array.from(form.querySelectorAll('input')).map(input => {
+		const attributes = {};
+		for (const attribute of input.attributes) {
+			attributes[attribute.name] = attribute.value;
+		}
+		return attributes;
+	}))`
+
+// fillFormJS fills a form with the suggested values and submits it
+const fillFormJS = `(index, values) => {
+	const form = document.forms[index];
+	if (!form) return;
+	for (const element of Array.from(form.elements)) {
+		if (!element.name || !(element.name in values)) continue;
+		const value = values[element.name];
+		if (element.type === 'radio' || element.type === 'checkbox') {
+			if (element.value === value) element.checked = true;
+		} else if (element.type !== 'file' && element.type !== 'hidden') {
+			element.value = value;
+		}
+		element.dispatchEvent(new Event('input', {bubbles: true}));
+		element.dispatchEvent(new Event('change', {bubbles: true}));
+	}
+	try {
+		form.requestSubmit ? form.requestSubmit() : form.submit();
+	} catch (e) {}
+}`
+
+// clickJS clicks the buttons and the elements with JS handlers, skipping
+// the ones which look like destructive actions
+const clickJS = `(max) => {
+	const selector = 'button, [onclick], [data-katana-click], [role=button], a[href^="javascript:"], input[type=button], input[type=submit]';
+	const danger = /log ?out|sign ?out|delete|remove|注销|退出|删除/i;
+	let clicked = 0;
+	for (const element of Array.from(document.querySelectorAll(selector))) {
+		if (clicked >= max) break;
+		const text = (element.innerText || element.value || '') + ' ' + (element.getAttribute('href') || '');
+		if (danger.test(text)) continue;
+		try {
+			element.click();
+			clicked++;
+		} catch (e) {}
+	}
+	return clicked;
+}`
+
+// interact fills and submits the forms of the page, then clicks the buttons
+// and the elements with JS handlers. The requests they trigger are captured
+// by the page hijack.
+func (c *Crawler) interact(page *rod.Page) {
+	result, err := page.Eval(formInputsJS)
+	if err != nil {
+		log.Println(chalk.Yellow.Color("warning: 获取页面表单出错, " + err.Error()))
+		return
+	}
+	var forms [][]map[string]string
+	_ = result.Value.Unmarshal(&forms)
+	for index, attributes := range forms {
+		if index >= maxInteractionForms {
+			break
+		}
+		formInputs := make([]utils.FormInput, 0, len(attributes))
+		for _, attrs := range attributes {
+			formInputs = append(formInputs, utils.ConvertAttributesToFormInput(attrs))
+		}
+		values := utils.FormInputFillSuggestions(formInputs)
+		if _, err := page.Eval(fillFormJS, index, values); err != nil {
+			continue
+		}
+		time.Sleep(interactionWait)
+	}
+
+	if _, err := page.Eval(clickJS, maxInteractionClicks); err != nil {
+		log.Println(chalk.Yellow.Color("warning: 页面点击交互出错, " + err.Error()))
+		return
+	}
+	time.Sleep(interactionWait)
+}
+
+// newHijackedRequest converts a request paused by the page hijack into a
+// navigation request keeping its method, body and content type
+func newHijackedRequest(e *proto.FetchRequestPaused, tag, source string, depth int, rootHostname string) *navigation.Request {
+	req := &navigation.Request{
+		Method:       e.Request.Method,
+		URL:          e.Request.URL,
+		Body:         e.Request.PostData,
+		Depth:        depth,
+		RootHostname: rootHostname,
+		Tag:          tag,
+		Attribute:    strings.ToLower(string(e.ResourceType)),
+		Source:       source,
+	}
+	for key, value := range e.Request.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			req.Headers = map[string]string{"Content-Type": value.Str()}
+		}
+	}
+	return req
+}
@@ -0,0 +1,69 @@
+package hybrid
+
+import (
+	"testing"
+
+	"Venom-Crawler/pkg/katana/navigation"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmood/gson"
+)
+
+func TestNewHijackedRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		paused *proto.FetchRequestPaused
+		want   *navigation.Request
+	}{
+		{
+			name: "form submission",
+			paused: &proto.FetchRequestPaused{
+				ResourceType: proto.NetworkResourceTypeDocument,
+				Request: &proto.NetworkRequest{
+					Method:   "POST",
+					URL:      "https://example.com/login",
+					PostData: "user=katana&password=katana",
+					Headers: proto.NetworkHeaders{
+						"content-type": gson.New("application/x-www-form-urlencoded"),
+						"Accept":       gson.New("*/*"),
+					},
+				},
+			},
+			want: &navigation.Request{
+				Method:       "POST",
+				URL:          "https://example.com/login",
+				Body:         "user=katana&password=katana",
+				Headers:      map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Depth:        2,
+				RootHostname: "example.com",
+				Tag:          "interaction",
+				Attribute:    "document",
+				Source:       "https://example.com/",
+			},
+		},
+		{
+			name: "xhr without body",
+			paused: &proto.FetchRequestPaused{
+				ResourceType: proto.NetworkResourceTypeXHR,
+				Request: &proto.NetworkRequest{
+					Method:  "GET",
+					URL:     "https://example.com/api/items?page=1",
+					Headers: proto.NetworkHeaders{"Accept": gson.New("application/json")},
+				},
+			},
+			want: &navigation.Request{
+				Method:       "GET",
+				URL:          "https://example.com/api/items?page=1",
+				Depth:        2,
+				RootHostname: "example.com",
+				Tag:          "interaction",
+				Attribute:    "xhr",
+				Source:       "https://example.com/",
+			},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, newHijackedRequest(test.paused, "interaction", "https://example.com/", 2, "example.com"), test.name)
+	}
+}
@@ -0,0 +1,53 @@
+package hybrid
+
+import (
+	"Venom-Crawler/pkg/rewrite"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// continueRewrittenRequest continues a request paused at the request stage
+// applying the request rewrite rules
+func (c *Crawler) continueRewrittenRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
+	var headers []rewrite.Header
+	for name, value := range e.Request.Headers {
+		headers = append(headers, rewrite.Header{Name: name, Value: value.Str()})
+	}
+	url, headers, changed := c.Options.Options.Rewrite.RewriteRequest(e.Request.URL, string(e.ResourceType), headers)
+	if !changed {
+		return FetchContinueRequest(page, e)
+	}
+	m := proto.FetchContinueRequest{
+		RequestID: e.RequestID,
+		URL:       url,
+		Headers:   toFetchHeaderEntries(headers),
+	}
+	return m.Call(page)
+}
+
+// rewriteResponse applies the response rewrite rules to a response paused at
+// the response stage, returning the new headers and body if it was rewritten
+func (c *Crawler) rewriteResponse(e *proto.FetchRequestPaused, body []byte) ([]*proto.FetchHeaderEntry, []byte, bool) {
+	rules := c.Options.Options.Rewrite
+	if e.ResponseStatusCode != nil && *e.ResponseStatusCode >= 300 && *e.ResponseStatusCode < 400 {
+		return nil, body, false
+	}
+	if !rules.MatchResponse(e.Request.URL, string(e.ResourceType)) {
+		return nil, body, false
+	}
+	var headers []rewrite.Header
+	for _, header := range e.ResponseHeaders {
+		headers = append(headers, rewrite.Header{Name: header.Name, Value: header.Value})
+	}
+	headers, body, changed := rules.RewriteResponse(e.Request.URL, string(e.ResourceType), headers, body)
+	return toFetchHeaderEntries(headers), body, changed
+}
+
+func toFetchHeaderEntries(headers []rewrite.Header) []*proto.FetchHeaderEntry {
+	entries := make([]*proto.FetchHeaderEntry, 0, len(headers))
+	for _, header := range headers {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: header.Name, Value: header.Value})
+	}
+	return entries
+}
@@ -0,0 +1,42 @@
+package files
+
+import (
+	"net/http"
+	"net/url"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/openapi"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+type openapiCrawler struct {
+	httpclient *retryablehttp.Client
+	catalog    *openapi.Catalog
+}
+
+// Visit discovers the OpenAPI descriptions of the site and returns the
+// requests expanded from their operations
+func (o *openapiCrawler) Visit(URL string) (navigationRequests []*navigation.Request, err error) {
+	fetch := func(URL string) (int, []byte, error) {
+		return fetchFile(o.httpclient, URL)
+	}
+	for _, document := range openapi.Discover(URL, fetch) {
+		specURL, err := url.Parse(document.URL)
+		if err != nil {
+			continue
+		}
+		navResp := &navigation.Response{
+			Depth: 2,
+			Resp:  &http.Response{Request: &http.Request{URL: specURL}},
+		}
+		for _, request := range o.catalog.Add(document) {
+			navRequest := navigation.NewNavigationRequestURLFromResponse(request.URL, document.URL, "file", "openapi", navResp)
+			navRequest.Method = request.Method
+			navRequest.Body = request.Body
+			navRequest.Headers = request.Headers
+			navigationRequests = append(navigationRequests, navRequest)
+		}
+	}
+	return
+}
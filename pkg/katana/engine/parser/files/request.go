@@ -1,10 +1,20 @@
 package files
 
 import (
+	"io"
+	"net/http"
+
 	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/katana/utils"
+	"Venom-Crawler/pkg/openapi"
+	"Venom-Crawler/pkg/wellknown"
+	"Venom-Crawler/pkg/wsdl"
 	"github.com/projectdiscovery/retryablehttp-go"
 )
 
+// maxFileSize bounds the size of a fetched known file, API or service description
+const maxFileSize = 10 << 20
+
 type visitFunc func(URL string) ([]*navigation.Request, error)
 
 type KnownFiles struct {
@@ -12,23 +22,33 @@ type KnownFiles struct {
 	httpclient *retryablehttp.Client
 }
 
-// New returns a new known files parser instance
-func New(httpclient *retryablehttp.Client, files string) *KnownFiles {
+// New returns a new known files parser instance, files is robotstxt,
+// sitemapxml or any other value for all the well-known files. The OpenAPI
+// and service descriptions are discovered too when catalog and services
+// are not nil.
+func New(httpclient *retryablehttp.Client, files string, catalog *openapi.Catalog, services *wsdl.Catalog, wellKnown *wellknown.Recorder) *KnownFiles {
 	parser := &KnownFiles{
 		httpclient: httpclient,
 	}
 	switch files {
+	case "":
 	case "robotstxt":
-		crawler := &robotsTxtCrawler{httpclient: httpclient}
+		crawler := &wellKnownCrawler{httpclient: httpclient, recorder: wellKnown, robotsOnly: true}
 		parser.parsers = append(parser.parsers, crawler.Visit)
 	case "sitemapxml":
 		crawler := &sitemapXmlCrawler{httpclient: httpclient}
 		parser.parsers = append(parser.parsers, crawler.Visit)
 	default:
-		crawler := &robotsTxtCrawler{httpclient: httpclient}
+		crawler := &wellKnownCrawler{httpclient: httpclient, recorder: wellKnown}
+		parser.parsers = append(parser.parsers, crawler.Visit)
+	}
+	if catalog != nil {
+		crawler := &openapiCrawler{httpclient: httpclient, catalog: catalog}
+		parser.parsers = append(parser.parsers, crawler.Visit)
+	}
+	if services != nil {
+		crawler := &wsdlCrawler{httpclient: httpclient, catalog: services}
 		parser.parsers = append(parser.parsers, crawler.Visit)
-		another := &sitemapXmlCrawler{httpclient: httpclient}
-		parser.parsers = append(parser.parsers, another.Visit)
 	}
 	return parser
 }
@@ -44,3 +64,20 @@ func (k *KnownFiles) Request(URL string) (navigationRequests []*navigation.Reque
 	}
 	return
 }
+
+// fetchFile requests a known file, an API or a service description
+func fetchFile(httpclient *retryablehttp.Client, URL string) (int, []byte, error) {
+	req, err := retryablehttp.NewRequest(http.MethodGet, URL, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+
+	resp, err := httpclient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+	return resp.StatusCode, body, err
+}
@@ -0,0 +1,46 @@
+package files
+
+import (
+	"net/http"
+	"net/url"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/wellknown"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+type wellKnownCrawler struct {
+	httpclient *retryablehttp.Client
+	recorder   *wellknown.Recorder
+	// robotsOnly requests robots.txt and the sitemaps it declares only
+	robotsOnly bool
+}
+
+// Visit requests the well-known files of the site and returns the URLs
+// they name, every site is visited once across both engines
+func (w *wellKnownCrawler) Visit(URL string) (navigationRequests []*navigation.Request, err error) {
+	fetch := func(URL string) (int, []byte, error) {
+		return fetchFile(w.httpclient, URL)
+	}
+	var results []wellknown.Result
+	if w.robotsOnly {
+		results = w.recorder.Robots(URL, fetch)
+	} else {
+		results = w.recorder.Discover(URL, fetch)
+	}
+	for _, result := range results {
+		fileURL, err := url.Parse(result.URL)
+		if err != nil {
+			continue
+		}
+		navResp := &navigation.Response{
+			Depth: 2,
+			Resp:  &http.Response{Request: &http.Request{URL: fileURL}},
+		}
+		for _, fileURL := range result.URLs {
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(fileURL, result.URL, "file", result.Source, navResp))
+		}
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package files
+
+import (
+	"net/http"
+	"net/url"
+
+	"Venom-Crawler/pkg/katana/navigation"
+	"Venom-Crawler/pkg/wsdl"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+type wsdlCrawler struct {
+	httpclient *retryablehttp.Client
+	catalog    *wsdl.Catalog
+}
+
+// Visit probes the common locations of the WADL descriptions and SOAP
+// service listings of the site and returns the example requests of their
+// operations
+func (w *wsdlCrawler) Visit(URL string) (navigationRequests []*navigation.Request, err error) {
+	fetch := func(URL string) (int, []byte, error) {
+		return fetchFile(w.httpclient, URL)
+	}
+	for _, operation := range w.catalog.Probe(URL, fetch) {
+		requestURL, err := url.Parse(operation.Request.URL)
+		if err != nil {
+			continue
+		}
+		navResp := &navigation.Response{
+			Depth: 2,
+			Resp:  &http.Response{Request: &http.Request{URL: requestURL}},
+		}
+		navRequest := navigation.NewNavigationRequestURLFromResponse(operation.Request.URL, URL, "file", "wsdl", navResp)
+		navRequest.Method = operation.Request.Method
+		navRequest.Body = operation.Request.Body
+		navRequest.Headers = operation.Request.Headers
+		navigationRequests = append(navigationRequests, navRequest)
+	}
+	return
+}
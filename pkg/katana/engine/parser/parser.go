@@ -6,10 +6,12 @@ import (
 	"mime/multipart"
 	"strings"
 
+	"Venom-Crawler/pkg/jsextract"
 	"Venom-Crawler/pkg/katana/navigation"
 	"Venom-Crawler/pkg/katana/output"
 	"Venom-Crawler/pkg/katana/types"
 	"Venom-Crawler/pkg/katana/utils"
+	"Venom-Crawler/pkg/openapi"
 
 	"github.com/PuerkitoBio/goquery"
 	urlutil "github.com/projectdiscovery/utils/url"
@@ -72,15 +74,28 @@ var responseParsers = []responseParser{
 	{bodyParser, customFieldRegexParser},
 }
 
+// baseParsersCount is the number of parsers enabled regardless of options
+var baseParsersCount = len(responseParsers)
+
+// replayJSEndpoints crawls the javascript endpoints which modify the target
+var replayJSEndpoints bool
+
 func InitWithOptions(options *types.Options) {
+	// drop parsers appended by a previous call so the runner can be created repeatedly
+	responseParsers = responseParsers[:baseParsersCount:baseParsersCount]
+	replayJSEndpoints = options.JSReplay
 	if options.AutomaticFormFill {
 		responseParsers = append(responseParsers, responseParser{bodyParser, bodyFormTagParser})
 	}
 	if options.ScrapeJSResponses {
-		responseParsers = append(responseParsers, responseParser{bodyParser, scriptContentRegexParser})
-		responseParsers = append(responseParsers, responseParser{contentParser, scriptJSFileRegexParser})
+		responseParsers = append(responseParsers, responseParser{bodyParser, scriptContentParser})
+		responseParsers = append(responseParsers, responseParser{contentParser, scriptJSFileParser})
+		responseParsers = append(responseParsers, responseParser{contentParser, scriptChunkParser})
 		responseParsers = append(responseParsers, responseParser{contentParser, bodyScrapeEndpointsParser})
 	}
+	if options.OpenAPI != nil {
+		responseParsers = append(responseParsers, responseParser{contentParser, openAPIParser(options.OpenAPI)})
+	}
 }
 
 // parseResponse runs the response parsers on the navigation response
@@ -614,16 +629,21 @@ func bodyMetaContentTagParser(resp *navigation.Response) (navigationRequests []*
 }
 
 // -------------------------------------------------------------------------
-// Begin JS Regex based parsers
+// Begin JS based parsers
 // -------------------------------------------------------------------------
 
-// scriptContentRegexParser parses script content endpoints from response
-func scriptContentRegexParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+// scriptContentParser parses script content endpoints from response
+func scriptContentParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
 	resp.Reader.Find("script").Each(func(i int, item *goquery.Selection) {
 		text := item.Text()
 		if text == "" {
 			return
 		}
+		if endpoints, err := jsextract.Extract(text); err == nil {
+			navigationRequests = append(navigationRequests, JSEndpointRequests(endpoints, "script", resp)...)
+			return
+		}
+		// not parsable, JSON data blocks, templates or JSX
 		endpoints := utils.ExtractRelativeEndpoints(text)
 		for _, item := range endpoints {
 			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(item, resp.Resp.Request.URL.String(), "script", "text", resp))
@@ -632,15 +652,21 @@ func scriptContentRegexParser(resp *navigation.Response) (navigationRequests []*
 	return
 }
 
-// scriptJSFileRegexParser parses relative endpoints from js file pages
-func scriptJSFileRegexParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+// scriptJSFileParser parses relative endpoints from js file pages
+func scriptJSFileParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
 	// Only process javascript file based on path or content type
 	// CSS, JS are supported for relative endpoint extraction.
 	contentType := resp.Resp.Header.Get("Content-Type")
-	if !(strings.HasSuffix(resp.Resp.Request.URL.Path, ".js") || strings.HasSuffix(resp.Resp.Request.URL.Path, ".css") || strings.Contains(contentType, "/javascript")) {
+	isJS := strings.HasSuffix(resp.Resp.Request.URL.Path, ".js") || strings.Contains(contentType, "/javascript")
+	if !(isJS || strings.HasSuffix(resp.Resp.Request.URL.Path, ".css")) {
 		return
 	}
 
+	if isJS {
+		if endpoints, err := jsextract.Extract(string(resp.Body)); err == nil {
+			return JSEndpointRequests(endpoints, "js", resp)
+		}
+	}
 	endpoints := utils.ExtractRelativeEndpoints(string(resp.Body))
 	for _, item := range endpoints {
 		navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(item, resp.Resp.Request.URL.String(), "js", "regex", resp))
@@ -648,6 +674,49 @@ func scriptJSFileRegexParser(resp *navigation.Response) (navigationRequests []*n
 	return
 }
 
+// scriptChunkParser parses the lazily loaded chunks of webpack and vite bundles,
+// from js files and from the runtime inlined in html pages
+func scriptChunkParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	pageURL := resp.Resp.Request.URL.String()
+	var scripts []string
+	if strings.HasSuffix(resp.Resp.Request.URL.Path, ".js") || strings.Contains(resp.Resp.Header.Get("Content-Type"), "/javascript") {
+		scripts = append(scripts, string(resp.Body))
+	} else if resp.Reader != nil {
+		resp.Reader.Find("script:not([src])").Each(func(i int, item *goquery.Selection) {
+			scripts = append(scripts, item.Text())
+		})
+	}
+	for _, script := range scripts {
+		chunks, err := jsextract.Chunks(pageURL, script)
+		if err != nil {
+			continue
+		}
+		for _, chunk := range chunks {
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(chunk, pageURL, "js", "chunk", resp))
+		}
+	}
+	return
+}
+
+// JSEndpointRequests converts endpoints extracted from javascript into
+// navigation requests, keeping the inferred method and parameters. The
+// endpoints modifying the target are only written to the output unless
+// their replay is enabled.
+func JSEndpointRequests(endpoints []jsextract.Endpoint, tag string, resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	for _, endpoint := range endpoints {
+		method, url, contentType, body := endpoint.Request()
+		req := navigation.NewNavigationRequestURLFromResponse(url, resp.Resp.Request.URL.String(), tag, endpoint.Source, resp)
+		req.Method = method
+		req.OutputOnly = !replayJSEndpoints && !jsextract.Safe(method)
+		if body != "" {
+			req.Body = body
+			req.Headers = map[string]string{"Content-Type": contentType}
+		}
+		navigationRequests = append(navigationRequests, req)
+	}
+	return
+}
+
 // bodyScrapeEndpointsParser parses scraped URLs from HTML body
 func bodyScrapeEndpointsParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
 	endpoints := utils.ExtractBodyEndpoints(string(resp.Body))
@@ -701,3 +770,25 @@ func customFieldRegexParser(resp *navigation.Response) (navigationRequests []*na
 	}
 	return
 }
+
+// openAPIParser returns a parser expanding the OpenAPI descriptions crawled
+// into requests, and following the descriptions referenced by Swagger UI pages
+func openAPIParser(catalog *openapi.Catalog) ResponseParserFunc {
+	return func(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+		pageURL := resp.Resp.Request.URL.String()
+		if spec, err := openapi.Parse([]byte(resp.Body)); err == nil {
+			for _, request := range catalog.Add(&openapi.Document{URL: pageURL, Spec: spec}) {
+				navRequest := navigation.NewNavigationRequestURLFromResponse(request.URL, pageURL, "file", "openapi", resp)
+				navRequest.Method = request.Method
+				navRequest.Body = request.Body
+				navRequest.Headers = request.Headers
+				navigationRequests = append(navigationRequests, navRequest)
+			}
+			return
+		}
+		for _, specURL := range openapi.SpecURLs(pageURL, resp.Body) {
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(specURL, pageURL, "file", "openapi", resp))
+		}
+		return
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"context"
 	"github.com/projectdiscovery/retryablehttp-go"
+	"github.com/ttacon/chalk"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httputil"
 	"strings"
@@ -24,6 +26,11 @@ func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Reques
 		Depth:        request.Depth + 1,
 		RootHostname: s.Hostname,
 	}
+	if c.Options.Options.RefreshCSRFTokens {
+		if err := c.RefreshFormTokens(s, request); err != nil {
+			log.Println(chalk.Yellow.Color("warning: 刷新表单CSRF令牌出错, " + request.URL + ", " + err.Error()))
+		}
+	}
 	ctx := context.WithValue(s.Ctx, navigation.Depth{}, request.Depth)
 	httpReq, err := http.NewRequestWithContext(ctx, request.Method, request.URL, nil)
 	if err != nil {
@@ -33,6 +33,7 @@ func (c *Crawler) Crawl(rootURL string) error {
 		return errorutil.NewWithErr(err).WithTag("standard")
 	}
 	defer crawlSession.CancelFunc()
+	defer c.SaveCookieJar(crawlSession)
 	if err := c.Do(crawlSession, c.makeRequest); err != nil {
 		return errorutil.NewWithErr(err).WithTag("standard")
 	}
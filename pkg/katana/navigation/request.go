@@ -21,6 +21,8 @@ type Request struct {
 	Source       string              `json:"source,omitempty"`
 	CustomFields map[string][]string `json:"-"`
 	Raw          string              `json:"raw,omitempty"`
+	ReauthCount  int                 `json:"-"`
+	OutputOnly   bool                `json:"-"`
 }
 
 // RequestURL returns the request URL for the navigation
@@ -35,8 +37,19 @@ func (n *Request) RequestURL() string {
 		builder.WriteString(n.Body)
 		builtURL := builder.String()
 		return builtURL
+	case "":
+		return ""
 	}
-	return ""
+	// other methods, e.g. PUT or DELETE, are keyed by method, URL and body
+	builder := &strings.Builder{}
+	builder.WriteString(n.Method)
+	builder.WriteString(" ")
+	builder.WriteString(n.URL)
+	if n.Body != "" {
+		builder.WriteString(":")
+		builder.WriteString(n.Body)
+	}
+	return builder.String()
 }
 
 // newNavigationRequestURL generates a navigation request from a relative URL
@@ -41,11 +41,7 @@ type CrawlerOptions struct {
 func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 	extensionsValidator := extensions.NewValidator(options.ExtensionsMatch, options.ExtensionFilter)
 
-	dialerOpts := fastdialer.DefaultOptions
-	if len(options.Resolvers) > 0 {
-		dialerOpts.BaseResolvers = options.Resolvers
-	}
-	fastdialerInstance, err := fastdialer.NewDialer(dialerOpts)
+	fastdialerInstance, err := newDialer(options)
 	if err != nil {
 		return nil, err
 	}
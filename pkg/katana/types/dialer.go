@@ -0,0 +1,93 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"Venom-Crawler/pkg/resolve"
+
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+)
+
+// hostsPathLock serializes the dialers created with a custom HOSTS_PATH
+var hostsPathLock sync.Mutex
+
+// newDialer creates the fastdialer of the crawler. The hosts of the resolve
+// map are written with the system hosts to a temporary hosts file loaded by
+// the dialer, so every connection it makes honours the mapping. A hosts file
+// has no ports: the rule matching every port of a host is preferred, the
+// first rule of the host otherwise.
+func newDialer(options *Options) (*fastdialer.Dialer, error) {
+	dialerOpts := fastdialer.DefaultOptions
+	if len(options.Resolvers) > 0 {
+		dialerOpts.BaseResolvers = options.Resolvers
+	}
+	rules := options.Resolve.Rules()
+	if len(rules) == 0 {
+		return fastdialer.NewDialer(dialerOpts)
+	}
+
+	hostsPathLock.Lock()
+	defer hostsPathLock.Unlock()
+	hostsPath, err := writeHostsFile(rules)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(hostsPath)
+
+	previous, isSet := os.LookupEnv("HOSTS_PATH")
+	if err := os.Setenv("HOSTS_PATH", hostsPath); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if isSet {
+			_ = os.Setenv("HOSTS_PATH", previous)
+		} else {
+			_ = os.Unsetenv("HOSTS_PATH")
+		}
+	}()
+	dialerOpts.HostsFile = true
+	return fastdialer.NewDialer(dialerOpts)
+}
+
+// writeHostsFile writes the mapped hosts followed by the system hosts to a
+// temporary file and returns its path
+func writeHostsFile(rules []resolve.Rule) (string, error) {
+	var hosts []string
+	ips := make(map[string]resolve.Rule)
+	for _, rule := range rules {
+		chosen, ok := ips[rule.Host]
+		if !ok {
+			hosts = append(hosts, rule.Host)
+		}
+		if !ok || (rule.Port == resolve.AnyPort && chosen.Port != resolve.AnyPort) {
+			ips[rule.Host] = rule
+		}
+	}
+
+	builder := &strings.Builder{}
+	for _, host := range hosts {
+		builder.WriteString(ips[host].IP + " " + host + "\n")
+	}
+	systemHostsPath := os.ExpandEnv(filepath.FromSlash(fastdialer.HostsFilePath))
+	if env, ok := os.LookupEnv("HOSTS_PATH"); ok && env != "" {
+		systemHostsPath = os.ExpandEnv(filepath.FromSlash(env))
+	}
+	// a missing system hosts file is not a failure
+	if data, err := os.ReadFile(systemHostsPath); err == nil {
+		builder.Write(data)
+	}
+
+	file, err := os.CreateTemp("", "katana-hosts-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := file.WriteString(builder.String()); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
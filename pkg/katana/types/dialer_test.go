@@ -0,0 +1,30 @@
+package types
+
+import (
+	"os"
+	"testing"
+
+	"Venom-Crawler/pkg/resolve"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDialer(t *testing.T) {
+	resolveMap, err := resolve.Parse("www.venom.invalid:443:10.0.0.8,www.venom.invalid:*:10.0.0.9,api.venom.invalid:8443:[::1]")
+	assert.Nil(t, err)
+	_, isSet := os.LookupEnv("HOSTS_PATH")
+
+	dialer, err := newDialer(&Options{Resolve: resolveMap})
+	assert.Nil(t, err)
+	defer dialer.Close()
+
+	data, err := dialer.GetDNSDataFromCache("www.venom.invalid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.9"}, data.A, "should prefer the rule of every port")
+	data, err = dialer.GetDNSDataFromCache("api.venom.invalid")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"::1"}, data.AAAA)
+
+	_, stillSet := os.LookupEnv("HOSTS_PATH")
+	assert.Equal(t, isSet, stillSet, "should restore HOSTS_PATH")
+}
@@ -4,7 +4,19 @@ import (
 	"regexp"
 	"strings"
 
+	"Venom-Crawler/pkg/auth"
+	"Venom-Crawler/pkg/block"
+	"Venom-Crawler/pkg/graphql"
+	"Venom-Crawler/pkg/jsarchive"
 	"Venom-Crawler/pkg/katana/output"
+	"Venom-Crawler/pkg/openapi"
+	"Venom-Crawler/pkg/resolve"
+	"Venom-Crawler/pkg/rewrite"
+	"Venom-Crawler/pkg/session"
+	"Venom-Crawler/pkg/soft404"
+	"Venom-Crawler/pkg/sourcemap"
+	"Venom-Crawler/pkg/wellknown"
+	"Venom-Crawler/pkg/wsdl"
 
 	"github.com/projectdiscovery/goflags"
 )
@@ -77,6 +89,9 @@ type Options struct {
 	Version bool
 	// ScrapeJSResponses enables scraping of relative endpoints from javascript
 	ScrapeJSResponses bool
+	// JSReplay crawls the endpoints extracted from javascript whose inferred
+	// method modifies the target, e.g. POST, PUT or DELETE
+	JSReplay bool
 	// CustomHeaders is a list of custom headers to add to request
 	CustomHeaders goflags.StringSlice
 	// Headless enables headless scraping
@@ -127,6 +142,43 @@ type Options struct {
 	IgnoreQueryParams bool
 	// Debug
 	Debug bool
+	// Credentials is the credential store used to answer 401/407 challenges
+	Credentials *auth.Store
+	// Session is the authenticated session state (cookies, localStorage) applied to every request
+	Session *session.State
+	// SessionMonitor detects logged-out responses and re-authenticates
+	SessionMonitor *session.Monitor
+	// CookieJarDir is the directory the cookie jar of every crawled site is persisted to
+	CookieJarDir string
+	// RefreshCSRFTokens re-fetches the page of a discovered form before submitting it
+	// and substitutes its anti-CSRF fields with fresh values
+	RefreshCSRFTokens bool
+	// CSRFTokenPatterns are the name patterns of anti-CSRF form fields
+	CSRFTokenPatterns []string
+	// Rewrite is the request/response rewrite rule set applied by the hybrid engine
+	Rewrite *rewrite.RuleSet
+	// Block is the policy of requests blocked by the hybrid engine browser
+	Block *block.Policy
+	// Resolve points hostnames at fixed IPs for both the http client and the browser
+	Resolve *resolve.Map
+	// SourceMaps retrieves the source maps of crawled scripts and records their original sources
+	SourceMaps *sourcemap.Recorder
+	// JSArchive saves every distinct script seen to a directory per host
+	JSArchive *jsarchive.Archive
+	// OpenAPI discovers the OpenAPI and Swagger descriptions of the site and
+	// crawls the requests expanded from them
+	OpenAPI *openapi.Catalog
+	// GraphQL records the GraphQL operations seen and sends each as a request of its own
+	GraphQL *graphql.Recorder
+	// Services enumerates the operations of the WSDL and WADL descriptions
+	// found or probed and crawls an example request for each
+	Services *wsdl.Catalog
+	// WellKnown records the well-known files found when KnownFiles requests
+	// them all, every site is visited once across both engines
+	WellKnown *wellknown.Recorder
+	// Soft404 compares the responses with those of non-existent paths of
+	// their directory and marks or drops the soft-404 ones
+	Soft404 *soft404.Detector
 }
 
 func (options *Options) ParseCustomHeaders() map[string]string {
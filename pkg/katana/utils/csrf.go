@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultCSRFTokenPatterns are the name patterns of common anti-CSRF form fields
+var DefaultCSRFTokenPatterns = []string{"csrf", "xsrf", "_token", "authenticity_token", "__RequestVerificationToken", "__EVENTVALIDATION", "__VIEWSTATE"}
+
+// IsCSRFTokenField returns true if the field name contains one of the patterns (case-insensitive)
+func IsCSRFTokenField(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if pattern != "" && strings.Contains(name, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractCSRFTokens returns the hidden inputs of a form whose name matches the patterns
+func ExtractCSRFTokens(form *goquery.Selection, patterns []string) map[string]string {
+	tokens := make(map[string]string)
+	form.Find("input").Each(func(_ int, item *goquery.Selection) {
+		if inputType, _ := item.Attr("type"); !strings.EqualFold(inputType, "hidden") {
+			return
+		}
+		name, _ := item.Attr("name")
+		if name == "" || !IsCSRFTokenField(name, patterns) {
+			return
+		}
+		value, _ := item.Attr("value")
+		tokens[name] = value
+	})
+	return tokens
+}
+
+// ReplaceFormValues substitutes the values of the fields present in values
+// in an urlencoded or multipart form body. Fields missing from the body are
+// not added and other fields keep their order and value.
+func ReplaceFormValues(body, contentType string, values map[string]string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return ReplaceQueryValues(body, values)
+	}
+
+	reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	if err := writer.SetBoundary(params["boundary"]); err != nil {
+		return body, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return body, err
+		}
+		if value, ok := values[part.FormName()]; ok && part.FileName() == "" {
+			content = []byte(value)
+		}
+		partWriter, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return body, err
+		}
+		if _, err := partWriter.Write(content); err != nil {
+			return body, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return body, err
+	}
+	return buffer.String(), nil
+}
+
+// ReplaceQueryValues substitutes the values of the fields present in values
+// in an urlencoded query, keeping the order of the parameters.
+func ReplaceQueryValues(query string, values map[string]string) (string, error) {
+	if query == "" {
+		return query, nil
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			return query, err
+		}
+		if value, ok := values[name]; ok {
+			pairs[i] = key + "=" + url.QueryEscape(value)
+		}
+	}
+	return strings.Join(pairs, "&"), nil
+}
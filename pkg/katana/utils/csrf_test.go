@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceFormValues(t *testing.T) {
+	body, err := ReplaceFormValues("name=katana&_token=old&remember=1", "application/x-www-form-urlencoded", map[string]string{"_token": "new/="})
+	require.Nil(t, err, "could not replace urlencoded values")
+	require.Equal(t, "name=katana&_token=new%2F%3D&remember=1", body, "got wrong urlencoded body")
+
+	var sb strings.Builder
+	writer := multipart.NewWriter(&sb)
+	_ = writer.WriteField("name", "katana")
+	_ = writer.WriteField("csrf_token", "old")
+	_ = writer.Close()
+	body, err = ReplaceFormValues(sb.String(), writer.FormDataContentType(), map[string]string{"csrf_token": "new"})
+	require.Nil(t, err, "could not replace multipart values")
+
+	reader := multipart.NewReader(strings.NewReader(body), writer.Boundary())
+	form, err := reader.ReadForm(1024)
+	require.Nil(t, err, "could not read multipart body")
+	require.Equal(t, []string{"katana"}, form.Value["name"], "got wrong untouched field")
+	require.Equal(t, []string{"new"}, form.Value["csrf_token"], "got wrong token field")
+}
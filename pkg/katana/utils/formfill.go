@@ -124,3 +124,22 @@ func ConvertGoquerySelectionToFormInput(item *goquery.Selection) FormInput {
 	}
 	return input
 }
+
+// ConvertAttributesToFormInput converts the attributes of an input element
+// read from a live browser page to a form input
+func ConvertAttributesToFormInput(attrs map[string]string) FormInput {
+	input := FormInput{Attributes: make(map[string]string)}
+	for key, value := range attrs {
+		switch key {
+		case "name":
+			input.Name = value
+		case "value":
+			input.Value = value
+		case "type":
+			input.Type = value
+		default:
+			input.Attributes[key] = value
+		}
+	}
+	return input
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAttributesToFormInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		want  FormInput
+	}{
+		{
+			name:  "typed input",
+			attrs: map[string]string{"type": "email", "name": "login", "id": "login"},
+			want:  FormInput{Type: "email", Name: "login", Attributes: map[string]string{"id": "login"}},
+		},
+		{
+			name:  "input with value",
+			attrs: map[string]string{"type": "hidden", "name": "_token", "value": "abc"},
+			want:  FormInput{Type: "hidden", Name: "_token", Value: "abc", Attributes: map[string]string{}},
+		},
+		{
+			name:  "untyped input",
+			attrs: map[string]string{"name": "q", "min": "1"},
+			want:  FormInput{Name: "q", Attributes: map[string]string{"min": "1"}},
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, ConvertAttributesToFormInput(test.attrs), test.name)
+	}
+}
+
+func TestFormInputFillSuggestions(t *testing.T) {
+	var inputs []FormInput
+	for _, attrs := range []map[string]string{
+		{"type": "email", "name": "email"},
+		{"type": "password", "name": "password"},
+		{"type": "number", "name": "age", "min": "18", "max": "99", "step": "2"},
+		{"type": "radio", "name": "plan", "value": "free"},
+		{"type": "radio", "name": "plan", "value": "pro"},
+		{"type": "checkbox", "name": "remember", "value": "1"},
+		{"type": "hidden", "name": "_token", "value": "abc"},
+		{"name": "nickname"},
+	} {
+		inputs = append(inputs, ConvertAttributesToFormInput(attrs))
+	}
+	require.Equal(t, map[string]string{
+		"email":    FormData.Email,
+		"password": FormData.Password,
+		"age":      "20",
+		"plan":     "free",
+		"remember": "1",
+		"_token":   "abc",
+		"nickname": FormData.Placeholder,
+	}, FormInputFillSuggestions(inputs), "got wrong form fill suggestions")
+}
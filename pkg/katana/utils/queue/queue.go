@@ -22,6 +22,7 @@ type Queue struct {
 	Strategy      Strategy
 	stack         *stack
 	priorityQueue *priorityQueue
+	held          int
 }
 
 // New creates a new queue from the type specified.
@@ -69,8 +70,20 @@ func (q *Queue) Push(x interface{}, priority int) {
 	}
 }
 
+// Release marks a popped item as done. The queue is not closed on timeout
+// while popped items are not released, as they may push new items back.
+func (q *Queue) Release() {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.held > 0 {
+		q.held--
+	}
+}
+
 // Pop pops an element from the queue. Result can be nil if no more
-// elements are present in the queue.
+// elements are present in the queue. Every popped element must be
+// released with Release once it has been processed.
 func (q *Queue) Pop() chan interface{} {
 	items := make(chan interface{})
 
@@ -85,9 +98,18 @@ func (q *Queue) Pop() chan interface{} {
 			case DepthFirst:
 				item = q.stack.Pop()
 			}
+			held := q.held
+			if item != nil {
+				q.held++
+			}
 			q.Unlock()
 
 			if item == nil {
+				if held > 0 {
+					start = time.Now()
+					time.Sleep(1 * time.Second)
+					continue
+				}
 				if !start.Add(q.Timeout).Before(time.Now()) {
 					time.Sleep(1 * time.Second)
 					continue
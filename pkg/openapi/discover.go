@@ -0,0 +1,212 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxFetches bounds the documents fetched while discovering a site
+const maxFetches = 40
+
+// Locations are the common paths API descriptions and Swagger UI are served at
+var Locations = []string{
+	"/swagger.json", "/swagger.yaml", "/v2/api-docs", "/v3/api-docs", "/openapi.json", "/openapi.yaml",
+	"/api-docs", "/swagger-ui/", "/swagger-ui.html", "/swagger-resources", "/swagger/v1/swagger.json",
+	"/api/swagger.json", "/api/openapi.json", "/v3/api-docs/swagger-config",
+}
+
+var (
+	// specRefRegex matches the description URLs in Swagger UI and ReDoc
+	// pages and configurations: url: "...", "configUrl": "...", spec-url="..."
+	specRefRegex = regexp.MustCompile(`(?i)["']?\b(?:url|configUrl|spec-url|specUrl|location)["']?\s*[:=]\s*["']([^"'\s<>]+)["']`)
+	// scriptRefRegex matches the scripts of a Swagger UI page, the description
+	// URL is set in swagger-initializer.js since Swagger UI 4
+	scriptRefRegex = regexp.MustCompile(`(?i)\bsrc\s*=\s*["']([^"'\s<>]*swagger[^"'\s<>]*\.js)["']`)
+	// apiDocRegex matches the URLs likely to be API descriptions
+	apiDocRegex = regexp.MustCompile(`(?i)(swagger|openapi|api-docs|api_docs|apidocs)`)
+)
+
+// demoHosts serve the demo descriptions Swagger UI is configured with by default
+var demoHosts = map[string]struct{}{"petstore.swagger.io": {}, "petstore3.swagger.io": {}}
+
+// SpecURLs returns the description URLs referenced by a Swagger UI or
+// ReDoc page, or a Swagger UI configuration, resolved against pageURL
+func SpecURLs(pageURL string, body string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil || !apiDocRegex.MatchString(body) && !strings.Contains(strings.ToLower(body), "redoc") {
+		return nil
+	}
+	var references []string
+	for _, match := range specRefRegex.FindAllStringSubmatch(body, -1) {
+		references = append(references, match[1])
+	}
+	for _, match := range scriptRefRegex.FindAllStringSubmatch(body, -1) {
+		references = append(references, match[1])
+	}
+
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, reference := range references {
+		resolved, err := base.Parse(reference)
+		if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+			continue
+		}
+		if _, ok := demoHosts[resolved.Hostname()]; ok {
+			continue
+		}
+		switch strings.ToLower(path.Ext(resolved.Path)) {
+		case "", ".json", ".yaml", ".yml", ".js":
+		default:
+			continue
+		}
+		if !apiDocRegex.MatchString(resolved.Path) {
+			continue
+		}
+		resolved.Fragment = ""
+		value := resolved.String()
+		if _, ok := seen[value]; !ok && value != pageURL {
+			seen[value] = struct{}{}
+			urls = append(urls, value)
+		}
+	}
+	return urls
+}
+
+// FetchFunc requests a URL and returns the response status code and body
+type FetchFunc func(url string) (int, []byte, error)
+
+// Document is an API description served by a site
+type Document struct {
+	URL  string
+	Spec *Spec
+}
+
+// Discover probes the common locations of the site of siteURL and follows
+// the description URLs referenced by the Swagger UI pages and configurations
+// it finds. The descriptions found are returned in the order found.
+func Discover(siteURL string, fetch FetchFunc) []*Document {
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return nil
+	}
+	var queue []string
+	for _, location := range Locations {
+		queue = append(queue, site.Scheme+"://"+site.Host+location)
+		// the application may be deployed under a path
+		if dir := strings.TrimSuffix(path.Dir(site.Path+"x"), "/"); dir != "" && dir != "." {
+			queue = append(queue, site.Scheme+"://"+site.Host+dir+location)
+		}
+	}
+
+	var documents []*Document
+	visited := make(map[string]struct{})
+	for len(queue) > 0 && len(visited) < maxFetches {
+		target := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[target]; ok {
+			continue
+		}
+		visited[target] = struct{}{}
+
+		status, body, err := fetch(target)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		if spec, err := Parse(body); err == nil {
+			documents = append(documents, &Document{URL: target, Spec: spec})
+			continue
+		}
+		queue = append(queue, SpecURLs(target, string(body))...)
+	}
+	return documents
+}
+
+// Entry is a description recorded in a catalog with its expanded requests
+type Entry struct {
+	URL      string    `json:"url"`
+	Title    string    `json:"title,omitempty"`
+	Version  string    `json:"version"`
+	Requests []Request `json:"requests"`
+}
+
+// Catalog collects the API descriptions found by both engines
+type Catalog struct {
+	replay  bool
+	entries map[string]*Entry
+	lock    sync.Mutex
+}
+
+// NewCatalog returns an empty catalog, the operations changing the state
+// of the server are returned to be crawled when replay is true
+func NewCatalog(replay bool) *Catalog {
+	return &Catalog{replay: replay, entries: make(map[string]*Entry)}
+}
+
+// Add records a description with all its expanded requests and returns
+// those to crawl: the GET, HEAD and OPTIONS requests, and the others when
+// the catalog replays them
+func (c *Catalog) Add(document *Document) []Request {
+	requests := document.Spec.Requests(document.URL)
+	if c == nil {
+		return Safe(requests)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[document.URL]; !ok {
+		c.entries[document.URL] = &Entry{
+			URL:      document.URL,
+			Title:    document.Spec.Info.Title,
+			Version:  document.Spec.Version(),
+			Requests: requests,
+		}
+	}
+	if c.replay {
+		return requests
+	}
+	return Safe(requests)
+}
+
+// Safe returns the requests which do not change the state of the server
+func Safe(requests []Request) []Request {
+	var safe []Request
+	for _, request := range requests {
+		switch request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			safe = append(safe, request)
+		}
+	}
+	return safe
+}
+
+// Entries returns the recorded descriptions sorted by URL
+func (c *Catalog) Entries() []Entry {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+	return entries
+}
+
+// WriteJSON writes the recorded descriptions and their requests to path
+func (c *Catalog) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(c.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
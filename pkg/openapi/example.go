@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// formatExamples are the example values of the string formats
+var formatExamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"time":      "00:00:00",
+	"email":     "test@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "127.0.0.1",
+	"ipv6":      "::1",
+	"byte":      "dGVzdA==",
+	"password":  "Test@123456",
+}
+
+// example returns an example value of a schema: its example, default or
+// first enum value, otherwise a value generated from its type and format.
+// refs holds the references being expanded, a recursive reference is left
+// out of the example.
+func (s *Spec) example(schema *Schema, refs map[string]struct{}) interface{} {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		if _, ok := refs[schema.Ref]; ok {
+			return nil
+		}
+		if refs == nil {
+			refs = make(map[string]struct{})
+		}
+		refs[schema.Ref] = struct{}{}
+		defer delete(refs, schema.Ref)
+		return s.example(s.schemaRef(schema.Ref), refs)
+	}
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+	if len(schema.AllOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, part := range schema.AllOf {
+			if object, ok := s.example(part, refs).(map[string]interface{}); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		for key, value := range s.properties(schema, refs) {
+			merged[key] = value
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return s.example(schema.OneOf[0], refs)
+	}
+	if len(schema.AnyOf) > 0 {
+		return s.example(schema.AnyOf[0], refs)
+	}
+
+	switch schemaType(schema.Type) {
+	case "object":
+		return s.properties(schema, refs)
+	case "array":
+		if item := s.example(schema.Items, refs); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "string":
+		return stringExample(schema.Format)
+	case "file":
+		return "test"
+	}
+	if schema.Properties != nil {
+		return s.properties(schema, refs)
+	}
+	return stringExample(schema.Format)
+}
+
+// properties returns an example object with every property of schema
+func (s *Spec) properties(schema *Schema, refs map[string]struct{}) map[string]interface{} {
+	object := map[string]interface{}{}
+	for name, property := range schema.Properties {
+		if value := s.example(property, refs); value != nil {
+			object[name] = value
+		}
+	}
+	return object
+}
+
+// parameterExample returns the example value of a non body parameter
+func (s *Spec) parameterExample(parameter *Parameter) interface{} {
+	if parameter.Example != nil {
+		return parameter.Example
+	}
+	// OpenAPI 3 named examples, the first by name
+	names := make([]string, 0, len(parameter.Examples))
+	for name := range parameter.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example, ok := parameter.Examples[name].(map[string]interface{}); ok && example["value"] != nil {
+			return example["value"]
+		}
+	}
+	if parameter.Schema != nil {
+		return s.example(parameter.Schema, nil)
+	}
+	return s.example(&Schema{
+		Type:    parameter.Type,
+		Format:  parameter.Format,
+		Default: parameter.Default,
+		Enum:    parameter.Enum,
+		Items:   parameter.Items,
+	}, nil)
+}
+
+// isBinary returns true if the schema of a form field is a file
+func isBinary(schema *Schema) bool {
+	return schema != nil && (schemaType(schema.Type) == "file" || schema.Format == "binary")
+}
+
+func stringExample(format string) string {
+	if value, ok := formatExamples[format]; ok {
+		return value
+	}
+	return "test"
+}
+
+// schemaType returns the type of a schema, the first non null type of a
+// type list
+func schemaType(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []interface{}:
+		for _, item := range value {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// stringify formats a value for a path, query, header or form field
+func stringify(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, stringify(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	case time.Time:
+		// YAML decodes unquoted dates as timestamps
+		if value.Hour() == 0 && value.Minute() == 0 && value.Second() == 0 {
+			return value.Format("2006-01-02")
+		}
+		return value.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
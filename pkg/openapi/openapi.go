@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is an OpenAPI 3 or Swagger (OpenAPI 2) description. Only the parts
+// needed to build requests are decoded.
+type Spec struct {
+	Swagger string `json:"swagger" yaml:"swagger"`
+	OpenAPI string `json:"openapi" yaml:"openapi"`
+	Info    struct {
+		Title   string `json:"title" yaml:"title"`
+		Version string `json:"version" yaml:"version"`
+	} `json:"info" yaml:"info"`
+	Paths map[string]*PathItem `json:"paths" yaml:"paths"`
+
+	// Swagger 2
+	Host        string                `json:"host" yaml:"host"`
+	BasePath    string                `json:"basePath" yaml:"basePath"`
+	Schemes     []string              `json:"schemes" yaml:"schemes"`
+	Consumes    []string              `json:"consumes" yaml:"consumes"`
+	Definitions map[string]*Schema    `json:"definitions" yaml:"definitions"`
+	Parameters  map[string]*Parameter `json:"parameters" yaml:"parameters"`
+
+	// OpenAPI 3
+	Servers    []Server `json:"servers" yaml:"servers"`
+	Components struct {
+		Schemas       map[string]*Schema      `json:"schemas" yaml:"schemas"`
+		Parameters    map[string]*Parameter   `json:"parameters" yaml:"parameters"`
+		RequestBodies map[string]*RequestBody `json:"requestBodies" yaml:"requestBodies"`
+	} `json:"components" yaml:"components"`
+}
+
+// Server is an OpenAPI 3 server, the base URL of the paths
+type Server struct {
+	URL       string `json:"url" yaml:"url"`
+	Variables map[string]struct {
+		Default string `json:"default" yaml:"default"`
+	} `json:"variables" yaml:"variables"`
+}
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Get        *Operation   `json:"get" yaml:"get"`
+	Put        *Operation   `json:"put" yaml:"put"`
+	Post       *Operation   `json:"post" yaml:"post"`
+	Delete     *Operation   `json:"delete" yaml:"delete"`
+	Options    *Operation   `json:"options" yaml:"options"`
+	Head       *Operation   `json:"head" yaml:"head"`
+	Patch      *Operation   `json:"patch" yaml:"patch"`
+	Parameters []*Parameter `json:"parameters" yaml:"parameters"`
+}
+
+// Operation is an API operation, a method on a path
+type Operation struct {
+	OperationID string       `json:"operationId" yaml:"operationId"`
+	Parameters  []*Parameter `json:"parameters" yaml:"parameters"`
+	RequestBody *RequestBody `json:"requestBody" yaml:"requestBody"`
+	Consumes    []string     `json:"consumes" yaml:"consumes"`
+}
+
+// Parameter is an operation parameter. Swagger 2 declares the type of
+// non body parameters on the parameter itself, OpenAPI 3 in its schema.
+type Parameter struct {
+	Ref      string                 `json:"$ref" yaml:"$ref"`
+	Name     string                 `json:"name" yaml:"name"`
+	In       string                 `json:"in" yaml:"in"`
+	Required bool                   `json:"required" yaml:"required"`
+	Schema   *Schema                `json:"schema" yaml:"schema"`
+	Example  interface{}            `json:"example" yaml:"example"`
+	Examples map[string]interface{} `json:"examples" yaml:"examples"`
+
+	Type    interface{}   `json:"type" yaml:"type"`
+	Format  string        `json:"format" yaml:"format"`
+	Default interface{}   `json:"default" yaml:"default"`
+	Enum    []interface{} `json:"enum" yaml:"enum"`
+	Items   *Schema       `json:"items" yaml:"items"`
+}
+
+// RequestBody is an OpenAPI 3 request body
+type RequestBody struct {
+	Ref     string               `json:"$ref" yaml:"$ref"`
+	Content map[string]MediaType `json:"content" yaml:"content"`
+}
+
+// MediaType is the schema of a request body for a content type
+type MediaType struct {
+	Schema  *Schema     `json:"schema" yaml:"schema"`
+	Example interface{} `json:"example" yaml:"example"`
+}
+
+// Schema is a JSON schema, type is a list of types since OpenAPI 3.1
+type Schema struct {
+	Ref        string             `json:"$ref" yaml:"$ref"`
+	Type       interface{}        `json:"type" yaml:"type"`
+	Format     string             `json:"format" yaml:"format"`
+	Example    interface{}        `json:"example" yaml:"example"`
+	Default    interface{}        `json:"default" yaml:"default"`
+	Enum       []interface{}      `json:"enum" yaml:"enum"`
+	Items      *Schema            `json:"items" yaml:"items"`
+	Properties map[string]*Schema `json:"properties" yaml:"properties"`
+	AllOf      []*Schema          `json:"allOf" yaml:"allOf"`
+	OneOf      []*Schema          `json:"oneOf" yaml:"oneOf"`
+	AnyOf      []*Schema          `json:"anyOf" yaml:"anyOf"`
+}
+
+// Parse parses a JSON or YAML API description
+func Parse(data []byte) (*Spec, error) {
+	data = bytes.TrimSpace(data)
+	spec := &Spec{}
+	var err error
+	if bytes.HasPrefix(data, []byte("{")) {
+		err = json.Unmarshal(data, spec)
+	} else {
+		err = yaml.Unmarshal(data, spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(spec.Swagger, "2") && !strings.HasPrefix(spec.OpenAPI, "3") {
+		return nil, errors.New("not an OpenAPI 2 or 3 description")
+	}
+	return spec, nil
+}
+
+// Version returns the OpenAPI version of the description
+func (s *Spec) Version() string {
+	if s.OpenAPI != "" {
+		return s.OpenAPI
+	}
+	return s.Swagger
+}
+
+// schemaRef resolves a local schema reference
+func (s *Spec) schemaRef(ref string) *Schema {
+	switch {
+	case strings.HasPrefix(ref, "#/definitions/"):
+		return s.Definitions[refName(ref)]
+	case strings.HasPrefix(ref, "#/components/schemas/"):
+		return s.Components.Schemas[refName(ref)]
+	}
+	return nil
+}
+
+// parameter resolves a parameter reference
+func (s *Spec) parameter(parameter *Parameter) *Parameter {
+	if parameter == nil || parameter.Ref == "" {
+		return parameter
+	}
+	switch {
+	case strings.HasPrefix(parameter.Ref, "#/parameters/"):
+		return s.Parameters[refName(parameter.Ref)]
+	case strings.HasPrefix(parameter.Ref, "#/components/parameters/"):
+		return s.Components.Parameters[refName(parameter.Ref)]
+	}
+	return nil
+}
+
+// requestBody resolves a request body reference
+func (s *Spec) requestBody(body *RequestBody) *RequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+	if strings.HasPrefix(body.Ref, "#/components/requestBodies/") {
+		return s.Components.RequestBodies[refName(body.Ref)]
+	}
+	return nil
+}
+
+// refName returns the unescaped last segment of a JSON pointer
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
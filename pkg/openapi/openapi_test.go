@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwagger2Requests(t *testing.T) {
+	data, err := os.ReadFile("testdata/swagger.json")
+	assert.Nil(t, err)
+	spec, err := Parse(data)
+	assert.Nil(t, err)
+
+	requests := spec.Requests("http://www.example.com/swagger.json")
+	assert.Equal(t, []Request{
+		{Method: http.MethodPost, URL: "https://api.example.com/v1/pets", OperationID: "addPet",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    `{"birthday":"2024-01-01","name":"doggie","tags":[{"id":1}]}`},
+		{Method: http.MethodGet, URL: "https://api.example.com/v1/pets/1?limit=20", OperationID: "getPet",
+			Headers: map[string]string{"X-Trace": "test"}},
+		{Method: http.MethodDelete, URL: "https://api.example.com/v1/pets/1", OperationID: "deletePet"},
+		{Method: http.MethodPost, URL: "https://api.example.com/v1/pets/a%20b/photo",
+			Headers: map[string]string{"Content-Type": "multipart/form-data; boundary=VenomCrawlerFormBoundary"},
+			Body: "--VenomCrawlerFormBoundary\r\nContent-Disposition: form-data; name=\"caption\"\r\n\r\nfront\r\n" +
+				"--VenomCrawlerFormBoundary\r\nContent-Disposition: form-data; name=\"file\"; filename=\"test.txt\"\r\nContent-Type: application/octet-stream\r\n\r\ntest\r\n" +
+				"--VenomCrawlerFormBoundary--\r\n"},
+	}, requests)
+}
+
+func TestOpenAPI3Requests(t *testing.T) {
+	data, err := os.ReadFile("testdata/openapi.yaml")
+	assert.Nil(t, err)
+	spec, err := Parse(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "3.0.1", spec.Version())
+
+	assert.Equal(t, "https://www.example.com/api/v2", spec.BaseURL("https://www.example.com/docs/openapi.yaml"),
+		"should prefer the server on the host serving the description")
+	assert.Equal(t, "https://prod.example.net/api", spec.BaseURL("https://docs.example.org/openapi.yaml"))
+
+	requests := spec.Requests("https://www.example.com/docs/openapi.yaml")
+	assert.Equal(t, []Request{
+		{Method: http.MethodGet, URL: "https://www.example.com/api/v2/users?role=admin&since=2024-01-01T00%3A00%3A00Z"},
+		{Method: http.MethodPost, URL: "https://www.example.com/api/v2/users",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    `{"active":true,"email":"test@example.com"}`},
+		{Method: http.MethodPut, URL: "https://www.example.com/api/v2/users/3fa85f64-5717-4562-b3fc-2c963f66afa6/avatar",
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:    "size=1&url=https%3A%2F%2Fexample.com"},
+	}, requests)
+}
+
+func TestParseRejectsOtherDocuments(t *testing.T) {
+	_, err := Parse([]byte(`{"name": "package", "version": "1.0.0"}`))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte(`<html><body>Swagger UI</body></html>`))
+	assert.NotNil(t, err)
+}
+
+func TestSpecURLs(t *testing.T) {
+	page := `<html><head><title>Swagger UI</title></head><body><div id="swagger-ui"></div>
+<script src="./swagger-ui-bundle.js"></script><script src="./swagger-initializer.js"></script>
+<script>window.ui = SwaggerUIBundle({url: "https://petstore.swagger.io/v2/swagger.json", dom_id: '#swagger-ui'})</script>
+<script>SwaggerUIBundle({urls: [{url: "/api/v1/api-docs", name: "v1"}, {url: "../openapi.yaml", name: "v2"}]})</script>
+<redoc spec-url="/docs/openapi.json"></redoc><a href="/login">login</a></body></html>`
+	assert.Equal(t, []string{
+		"https://example.com/api/v1/api-docs",
+		"https://example.com/openapi.yaml",
+		"https://example.com/docs/openapi.json",
+		"https://example.com/swagger-ui/swagger-ui-bundle.js",
+		"https://example.com/swagger-ui/swagger-initializer.js",
+	}, SpecURLs("https://example.com/swagger-ui/index.html", page))
+
+	config := `{"configUrl":"/v3/api-docs/swagger-config","oauth2RedirectUrl":"https://example.com/swagger-ui/oauth2-redirect.html","urls":[{"url":"/v3/api-docs/public","name":"public"}],"validatorUrl":""}`
+	assert.Equal(t, []string{"https://example.com/v3/api-docs/public"}, SpecURLs("https://example.com/v3/api-docs/swagger-config", config))
+}
+
+func TestDiscover(t *testing.T) {
+	spec, err := os.ReadFile("testdata/swagger.json")
+	assert.Nil(t, err)
+	responses := map[string]string{
+		"https://example.com/swagger-ui/":                       `<html><title>Swagger UI</title><script src="swagger-initializer.js"></script></html>`,
+		"https://example.com/swagger-ui/swagger-initializer.js": `window.ui = SwaggerUIBundle({ url: "/internal/api-docs", dom_id: '#swagger-ui' });`,
+		"https://example.com/internal/api-docs":                 string(spec),
+	}
+	var fetched []string
+	documents := Discover("https://example.com/app/", func(url string) (int, []byte, error) {
+		fetched = append(fetched, url)
+		if body, ok := responses[url]; ok {
+			return http.StatusOK, []byte(body), nil
+		}
+		return http.StatusNotFound, nil, nil
+	})
+	assert.Contains(t, fetched, "https://example.com/app/swagger.json", "should probe under the application path")
+	assert.Len(t, documents, 1)
+	assert.Equal(t, "https://example.com/internal/api-docs", documents[0].URL)
+
+	catalog := NewCatalog(false)
+	requests := catalog.Add(documents[0])
+	for _, request := range requests {
+		assert.Equal(t, http.MethodGet, request.Method, "should not crawl the operations changing the server state")
+	}
+	assert.Len(t, catalog.Entries(), 1)
+	assert.Greater(t, len(catalog.Entries()[0].Requests), len(requests), "should record every operation")
+
+	replaying := NewCatalog(true)
+	assert.Len(t, replaying.Add(documents[0]), len(catalog.Entries()[0].Requests))
+	assert.Len(t, replaying.Add(documents[0]), len(catalog.Entries()[0].Requests))
+}
@@ -0,0 +1,325 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// multipartBoundary is the fixed boundary of the multipart bodies, so the
+// same operation always gives the same request
+const multipartBoundary = "VenomCrawlerFormBoundary"
+
+// Request is a concrete request for an API operation
+type Request struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	OperationID string            `json:"operation_id,omitempty"`
+}
+
+// methods are the operations of a path item in the order they are expanded
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost,
+	http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// skippedHeaders are the header parameters left to the crawler, an example
+// value would replace the session credentials
+var skippedHeaders = map[string]struct{}{
+	"authorization": {}, "cookie": {}, "content-type": {}, "accept": {}, "content-length": {}, "host": {},
+}
+
+// Requests expands every path and operation of the description into a
+// concrete request, with example values for the parameters and the body.
+// specURL is the URL the description was served at, the base URL of the
+// paths defaults to it.
+func (s *Spec) Requests(specURL string) []Request {
+	base := s.BaseURL(specURL)
+	if base == "" {
+		return nil
+	}
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var requests []Request
+	for _, path := range paths {
+		item := s.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, method := range methods {
+			operation := item.operation(method)
+			if operation == nil {
+				continue
+			}
+			requests = append(requests, s.request(base, path, method, item, operation))
+		}
+	}
+	return requests
+}
+
+// BaseURL returns the base URL of the paths, resolved against specURL
+func (s *Spec) BaseURL(specURL string) string {
+	spec, err := url.Parse(specURL)
+	if err != nil {
+		return ""
+	}
+	if s.Swagger != "" {
+		base := &url.URL{Scheme: spec.Scheme, Host: spec.Host, Path: s.BasePath}
+		if len(s.Schemes) > 0 && !contains(s.Schemes, spec.Scheme) {
+			base.Scheme = s.Schemes[0]
+		}
+		if s.Host != "" {
+			base.Host = s.Host
+		}
+		return strings.TrimSuffix(base.String(), "/")
+	}
+
+	// a server on the host serving the description first
+	var servers []*url.URL
+	for _, server := range s.Servers {
+		value := server.URL
+		for name, variable := range server.Variables {
+			value = strings.ReplaceAll(value, "{"+name+"}", variable.Default)
+		}
+		if resolved, err := spec.Parse(value); err == nil && resolved.Host != "" {
+			servers = append(servers, resolved)
+		}
+	}
+	if len(servers) == 0 {
+		return spec.Scheme + "://" + spec.Host
+	}
+	for _, server := range servers {
+		if server.Host == spec.Host {
+			return strings.TrimSuffix(server.String(), "/")
+		}
+	}
+	return strings.TrimSuffix(servers[0].String(), "/")
+}
+
+func (s *Spec) request(base string, path string, method string, item *PathItem, operation *Operation) Request {
+	request := Request{Method: method, OperationID: operation.OperationID, Headers: map[string]string{}}
+	query := url.Values{}
+	form := &formBody{}
+	var body interface{}
+	hasBody := false
+
+	for _, parameter := range s.mergeParameters(item.Parameters, operation.Parameters) {
+		switch parameter.In {
+		case "path":
+			value := stringify(s.parameterExample(parameter))
+			path = strings.ReplaceAll(path, "{"+parameter.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Add(parameter.Name, stringify(s.parameterExample(parameter)))
+		case "header":
+			if _, ok := skippedHeaders[strings.ToLower(parameter.Name)]; !ok {
+				request.Headers[parameter.Name] = stringify(s.parameterExample(parameter))
+			}
+		case "formData":
+			form.add(parameter.Name, s.parameterExample(parameter), schemaType(parameter.Type) == "file")
+		case "body":
+			body, hasBody = s.example(parameter.Schema, nil), true
+		}
+	}
+
+	request.URL = base + path
+	if len(query) > 0 {
+		request.URL += "?" + query.Encode()
+	}
+
+	consumes := operation.Consumes
+	if len(consumes) == 0 {
+		consumes = s.Consumes
+	}
+	switch {
+	case hasBody:
+		request.Body, request.Headers["Content-Type"] = encodeJSON(body)
+	case len(form.fields) > 0:
+		if contains(consumes, "multipart/form-data") || form.files {
+			request.Body, request.Headers["Content-Type"] = form.multipart()
+		} else {
+			request.Body, request.Headers["Content-Type"] = form.urlencoded()
+		}
+	default:
+		if requestBody := s.requestBody(operation.RequestBody); requestBody != nil {
+			request.Body, request.Headers["Content-Type"] = s.encodeBody(requestBody)
+		}
+	}
+	if request.Headers["Content-Type"] == "" {
+		delete(request.Headers, "Content-Type")
+	}
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+	return request
+}
+
+// mergeParameters resolves the parameters of a path and of an operation,
+// the operation overriding the path parameters of the same name and location
+func (s *Spec) mergeParameters(pathParameters, operationParameters []*Parameter) []*Parameter {
+	var merged []*Parameter
+	index := make(map[string]int)
+	for _, parameter := range append(append([]*Parameter{}, pathParameters...), operationParameters...) {
+		parameter = s.parameter(parameter)
+		if parameter == nil || parameter.Name == "" {
+			continue
+		}
+		key := parameter.In + ":" + parameter.Name
+		if i, ok := index[key]; ok {
+			merged[i] = parameter
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, parameter)
+	}
+	return merged
+}
+
+// encodeBody encodes an OpenAPI 3 request body, preferring JSON, then
+// url encoded and multipart forms
+func (s *Spec) encodeBody(body *RequestBody) (string, string) {
+	contentTypes := make([]string, 0, len(body.Content))
+	for contentType := range body.Content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+	choose := func(match func(string) bool) (string, bool) {
+		for _, contentType := range contentTypes {
+			if match(contentType) {
+				return contentType, true
+			}
+		}
+		return "", false
+	}
+	if contentType, ok := choose(func(c string) bool { return strings.Contains(c, "json") }); ok {
+		media := body.Content[contentType]
+		value := media.Example
+		if value == nil {
+			value = s.example(media.Schema, nil)
+		}
+		encoded, _ := encodeJSON(value)
+		return encoded, contentType
+	}
+	for _, contentType := range []string{"application/x-www-form-urlencoded", "multipart/form-data"} {
+		media, ok := body.Content[contentType]
+		if !ok {
+			continue
+		}
+		form := &formBody{}
+		schema := media.Schema
+		if schema != nil && schema.Ref != "" {
+			schema = s.schemaRef(schema.Ref)
+		}
+		if schema != nil {
+			names := make([]string, 0, len(schema.Properties))
+			for name := range schema.Properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				property := schema.Properties[name]
+				form.add(name, s.example(property, nil), isBinary(property))
+			}
+		}
+		if contentType == "multipart/form-data" {
+			return form.multipart()
+		}
+		return form.urlencoded()
+	}
+	// text, xml and other bodies, only a literal example can be sent
+	for _, contentType := range contentTypes {
+		if example, ok := body.Content[contentType].Example.(string); ok {
+			return example, contentType
+		}
+	}
+	return "", ""
+}
+
+// formBody is a form body built from form fields
+type formBody struct {
+	fields []formField
+	files  bool
+}
+
+type formField struct {
+	name  string
+	value string
+	file  bool
+}
+
+func (f *formBody) add(name string, value interface{}, file bool) {
+	f.fields = append(f.fields, formField{name: name, value: stringify(value), file: file})
+	f.files = f.files || file
+}
+
+func (f *formBody) urlencoded() (string, string) {
+	values := url.Values{}
+	for _, field := range f.fields {
+		values.Add(field.name, field.value)
+	}
+	return values.Encode(), "application/x-www-form-urlencoded"
+}
+
+func (f *formBody) multipart() (string, string) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	_ = writer.SetBoundary(multipartBoundary)
+	for _, field := range f.fields {
+		if field.file {
+			part, err := writer.CreateFormFile(field.name, "test.txt")
+			if err == nil {
+				_, _ = part.Write([]byte("test"))
+			}
+			continue
+		}
+		_ = writer.WriteField(field.name, field.value)
+	}
+	_ = writer.Close()
+	return buffer.String(), writer.FormDataContentType()
+}
+
+func encodeJSON(value interface{}) (string, string) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", ""
+	}
+	return string(data), "application/json"
+}
+
+func (p *PathItem) operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodHead:
+		return p.Head
+	case http.MethodOptions:
+		return p.Options
+	case http.MethodPost:
+		return p.Post
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodDelete:
+		return p.Delete
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package pathfuzz
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"Venom-Crawler/pkg/soft404"
+
+	"github.com/projectdiscovery/ratelimit"
+)
+
+// DefaultThreads is the number of concurrent requests when none is set
+const DefaultThreads = 20
+
+// BackupSuffixes are appended to the files found to request their backups
+var BackupSuffixes = []string{".bak", "~", ".old"}
+
+// Options configure a fuzzing run
+type Options struct {
+	// Depth is the number of directory levels fuzzed, the root is the first
+	// level and the directories found are fuzzed until the depth is reached
+	Depth int
+	// Extensions are appended to the words without an extension, the word
+	// itself is always requested
+	Extensions []string
+	// Backups requests the backup and editor artifacts of the files found:
+	// the BackupSuffixes and the vim swap file
+	Backups bool
+	// Threads is the number of concurrent requests
+	Threads int
+	// RateLimit is the maximum number of requests per second to a host,
+	// unlimited when not positive
+	RateLimit int
+	// FilterStatus are the status codes of the responses ignored
+	FilterStatus []int
+	// FilterLength are the body lengths of the responses ignored
+	FilterLength []int
+	// Soft404 drops the responses matching those of non-existent paths
+	Soft404 *soft404.Detector
+}
+
+// Response is the response of a fuzzed URL, fetched without following
+// redirects
+type Response struct {
+	StatusCode int
+	Location   string
+	Body       string
+}
+
+// FetchFunc requests a URL without following redirects
+type FetchFunc func(url string) (Response, error)
+
+// Hit is a path found
+type Hit struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Length     int    `json:"length"`
+	Depth      int    `json:"depth"`
+	Directory  bool   `json:"directory,omitempty"`
+	Backup     bool   `json:"backup,omitempty"`
+}
+
+// fuzzer is the state of a run
+type fuzzer struct {
+	options  Options
+	fetch    FetchFunc
+	ctx      context.Context
+	limiters map[string]*ratelimit.Limiter
+	seen     map[string]struct{}
+	lock     sync.Mutex
+}
+
+// target is a URL to request
+type target struct {
+	url    string
+	depth  int
+	backup bool
+}
+
+// Run fuzzes the words under the root of the site of siteURL and returns
+// the paths found sorted by URL. A path is found when it responds with a
+// success, moves permanently within the host or redirects to its
+// directory, and is not filtered.
+func Run(siteURL string, words []string, options Options, fetch FetchFunc) []Hit {
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return nil
+	}
+	if options.Depth < 1 {
+		options.Depth = 1
+	}
+	if options.Threads < 1 {
+		options.Threads = DefaultThreads
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := &fuzzer{
+		options:  options,
+		fetch:    fetch,
+		ctx:      ctx,
+		limiters: make(map[string]*ratelimit.Limiter),
+		seen:     make(map[string]struct{}),
+	}
+
+	var hits []Hit
+	directories := []string{site.Scheme + "://" + site.Host + "/"}
+	for depth := 1; depth <= options.Depth && len(directories) > 0; depth++ {
+		var targets []target
+		for _, directory := range directories {
+			targets = append(targets, f.expand(directory, words, depth)...)
+		}
+		found := f.request(targets)
+		directories = nil
+		for _, hit := range found {
+			if hit.Directory {
+				directories = append(directories, strings.TrimSuffix(hit.URL, "/")+"/")
+			}
+		}
+		hits = append(hits, found...)
+		if options.Backups {
+			hits = append(hits, f.request(f.backups(found))...)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].URL < hits[j].URL
+	})
+	return hits
+}
+
+// expand returns the URLs of the words and their extensions under a
+// directory, the URLs already requested are skipped
+func (f *fuzzer) expand(directory string, words []string, depth int) []target {
+	var targets []target
+	for _, word := range words {
+		word = strings.TrimSpace(strings.TrimPrefix(word, "/"))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		candidates := []string{word}
+		if path.Ext(strings.TrimSuffix(word, "/")) == "" && !strings.HasSuffix(word, "/") {
+			for _, extension := range f.options.Extensions {
+				candidates = append(candidates, word+"."+strings.TrimPrefix(extension, "."))
+			}
+		}
+		for _, candidate := range candidates {
+			if f.claim(directory + candidate) {
+				targets = append(targets, target{url: directory + candidate, depth: depth})
+			}
+		}
+	}
+	return targets
+}
+
+// backups returns the URLs of the backups of the files found
+func (f *fuzzer) backups(hits []Hit) []target {
+	var targets []target
+	for _, hit := range hits {
+		if hit.Directory || hit.Backup {
+			continue
+		}
+		directory, name := path.Split(hit.URL)
+		if name == "" {
+			continue
+		}
+		candidates := []string{directory + "." + name + ".swp"}
+		for _, suffix := range BackupSuffixes {
+			candidates = append(candidates, hit.URL+suffix)
+		}
+		for _, candidate := range candidates {
+			if f.claim(candidate) {
+				targets = append(targets, target{url: candidate, depth: hit.Depth, backup: true})
+			}
+		}
+	}
+	return targets
+}
+
+// claim returns true the first time a URL is claimed
+func (f *fuzzer) claim(rawURL string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.seen[rawURL]; ok {
+		return false
+	}
+	f.seen[rawURL] = struct{}{}
+	return true
+}
+
+// request requests the targets concurrently and returns the hits
+func (f *fuzzer) request(targets []target) []Hit {
+	var hits []Hit
+	var hitsLock sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan target)
+	for i := 0; i < f.options.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				if hit, ok := f.check(t); ok {
+					hitsLock.Lock()
+					hits = append(hits, hit)
+					hitsLock.Unlock()
+				}
+			}
+		}()
+	}
+	for _, t := range targets {
+		queue <- t
+	}
+	close(queue)
+	wg.Wait()
+	return hits
+}
+
+// check requests a target and returns the hit if it is found
+func (f *fuzzer) check(t target) (Hit, bool) {
+	parsed, err := url.Parse(t.url)
+	if err != nil {
+		return Hit{}, false
+	}
+	f.limiter(parsed.Host).Take()
+	resp, err := f.fetch(t.url)
+	if err != nil {
+		return Hit{}, false
+	}
+	hit := Hit{URL: t.url, StatusCode: resp.StatusCode, Length: len(resp.Body), Depth: t.depth, Backup: t.backup}
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		hit.Directory = strings.HasSuffix(parsed.Path, "/")
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		location, err := parsed.Parse(resp.Location)
+		if err != nil || location.Host != parsed.Host {
+			return Hit{}, false
+		}
+		hit.Directory = location.Path == strings.TrimSuffix(parsed.Path, "/")+"/"
+		// the other redirects are often those of every missing path to a
+		// login or home page
+		if resp.StatusCode != 301 && !hit.Directory {
+			return Hit{}, false
+		}
+	default:
+		return Hit{}, false
+	}
+	if t.backup && hit.StatusCode >= 300 {
+		return Hit{}, false
+	}
+	if contains(f.options.FilterStatus, hit.StatusCode) || contains(f.options.FilterLength, hit.Length) {
+		return Hit{}, false
+	}
+	// the probes count towards the rate limit of the host
+	probe := func(probeURL string) (soft404.Response, error) {
+		if probe, err := url.Parse(probeURL); err == nil {
+			f.limiter(probe.Host).Take()
+		}
+		resp, err := f.fetch(probeURL)
+		return soft404.Response(resp), err
+	}
+	if f.options.Soft404.Check(t.url, soft404.Response(resp), probe) {
+		return Hit{}, false
+	}
+	return hit, true
+}
+
+// limiter returns the rate limiter of a host
+func (f *fuzzer) limiter(host string) *ratelimit.Limiter {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	limiter, ok := f.limiters[host]
+	if !ok {
+		if f.options.RateLimit > 0 {
+			limiter = ratelimit.New(f.ctx, uint(f.options.RateLimit), time.Second)
+		} else {
+			limiter = ratelimit.NewUnlimited(f.ctx)
+		}
+		f.limiters[host] = limiter
+	}
+	return limiter
+}
+
+func contains(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
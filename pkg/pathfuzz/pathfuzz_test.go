@@ -0,0 +1,137 @@
+package pathfuzz
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Venom-Crawler/pkg/soft404"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// site serves /admin/ with /admin/login.php and its backup, /static/
+// with an empty index, /index.php and /home which redirects to the login
+// page, every other path is missing
+func site(requested *[]string, lock *sync.Mutex) FetchFunc {
+	pages := map[string]Response{
+		"/admin":               {StatusCode: 301, Location: "/admin/"},
+		"/admin/":              {StatusCode: 200, Body: "<html><title>Admin</title></html>"},
+		"/admin/login.php":     {StatusCode: 200, Body: "<form>login</form>"},
+		"/admin/login.php.bak": {StatusCode: 200, Body: "<?php $password = 'secret'; ?>"},
+		"/static":              {StatusCode: 302, Location: "https://example.com/static/"},
+		"/static/":             {StatusCode: 200},
+		"/index.php":           {StatusCode: 200, Body: "<html>home</html>"},
+		"/home":                {StatusCode: 302, Location: "/login"},
+	}
+	return func(rawURL string) (Response, error) {
+		lock.Lock()
+		*requested = append(*requested, rawURL)
+		lock.Unlock()
+		parsed, _ := url.Parse(rawURL)
+		if resp, ok := pages[parsed.Path]; ok {
+			return resp, nil
+		}
+		return Response{StatusCode: 404, Body: "not found"}, nil
+	}
+}
+
+func hitURLs(hits []Hit) []string {
+	var urls []string
+	for _, hit := range hits {
+		urls = append(urls, hit.URL)
+	}
+	return urls
+}
+
+func TestRun(t *testing.T) {
+	var requested []string
+	var lock sync.Mutex
+	words := []string{"admin", "/static", "index", "login", "home", "", "# comment"}
+	hits := Run("https://example.com/path?q=1", words, Options{Depth: 2, Extensions: []string{"php"}, Backups: true}, site(&requested, &lock))
+
+	assert.Equal(t, []string{
+		"https://example.com/admin",
+		"https://example.com/admin/login.php",
+		"https://example.com/admin/login.php.bak",
+		"https://example.com/index.php",
+		"https://example.com/static",
+	}, hitURLs(hits))
+	for _, hit := range hits {
+		switch hit.URL {
+		case "https://example.com/admin", "https://example.com/static":
+			assert.True(t, hit.Directory, hit.URL)
+			assert.Equal(t, 1, hit.Depth)
+		case "https://example.com/admin/login.php":
+			assert.Equal(t, 2, hit.Depth)
+		case "https://example.com/admin/login.php.bak":
+			assert.True(t, hit.Backup)
+		}
+	}
+	assert.Contains(t, requested, "https://example.com/admin/.login.php.swp")
+	assert.Contains(t, requested, "https://example.com/static/login.php", "should recurse into the directories redirected to")
+	assert.NotContains(t, requested, "https://example.com/admin/login.php/", "should not fuzz under files")
+	assert.NotContains(t, requested, "https://example.com/admin/login.php.bak.bak", "should not back up the backups")
+
+	seen := make(map[string]struct{})
+	for _, r := range requested {
+		_, ok := seen[r]
+		assert.False(t, ok, "should request %s once", r)
+		seen[r] = struct{}{}
+	}
+}
+
+func TestRunDepth(t *testing.T) {
+	var requested []string
+	var lock sync.Mutex
+	hits := Run("https://example.com", []string{"admin", "login.php"}, Options{}, site(&requested, &lock))
+	assert.Equal(t, []string{"https://example.com/admin"}, hitURLs(hits))
+	for _, r := range requested {
+		assert.False(t, strings.HasPrefix(r, "https://example.com/admin/"), "should fuzz the root only")
+	}
+}
+
+func TestRunFilters(t *testing.T) {
+	var requested []string
+	var lock sync.Mutex
+	fetch := site(&requested, &lock)
+	hits := Run("https://example.com", []string{"admin", "static", "index.php"}, Options{FilterStatus: []int{301}, FilterLength: []int{17}}, fetch)
+	assert.Equal(t, []string{"https://example.com/static"}, hitURLs(hits))
+}
+
+func TestRunSoft404(t *testing.T) {
+	detector, err := soft404.NewDetector(soft404.ModeMark)
+	assert.Nil(t, err)
+	catchAll := func(rawURL string) (Response, error) {
+		parsed, _ := url.Parse(rawURL)
+		if parsed.Path == "/about" {
+			return Response{StatusCode: 200, Body: "<html><title>About</title><p>We make tools for the web, this is our story.</p></html>"}, nil
+		}
+		return Response{StatusCode: 200, Body: "<html><title>Oops</title><p>Nothing here, go back home.</p></html>"}, nil
+	}
+	hits := Run("https://example.com", []string{"about", "backup", "admin"}, Options{Soft404: detector}, catchAll)
+	assert.Equal(t, []string{"https://example.com/about"}, hitURLs(hits))
+	assert.Len(t, detector.Matches(), 2)
+}
+
+func TestRunRateLimit(t *testing.T) {
+	var requested []string
+	var lock sync.Mutex
+	start := time.Now()
+	Run("https://example.com", []string{"a", "b", "c", "d", "e", "f"}, Options{RateLimit: 3}, site(&requested, &lock))
+	assert.Len(t, requested, 6)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "should wait for the next second once the limit is reached")
+}
+
+func TestRunSoft404RateLimit(t *testing.T) {
+	detector, err := soft404.NewDetector(soft404.ModeMark)
+	assert.Nil(t, err)
+	var requested []string
+	var lock sync.Mutex
+	start := time.Now()
+	Run("https://example.com", []string{"index.php"}, Options{RateLimit: 2, Soft404: detector}, site(&requested, &lock))
+	assert.Greater(t, len(requested), 2, "should probe the non-existent paths")
+	assert.GreaterOrEqual(t, time.Since(start), time.Second, "should rate limit the probes")
+}
@@ -0,0 +1,127 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// AnyPort matches every port of a host
+const AnyPort = "*"
+
+// Rule points a host (and port) at a fixed IP address
+type Rule struct {
+	Host string
+	Port string
+	IP   string
+}
+
+// Map is a set of host to IP rules, like curl --resolve
+type Map struct {
+	rules []Rule
+}
+
+// DialFunc is the signature of net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Parse parses comma separated host:port:ip entries. The port may be * to
+// match every port, an IPv6 address may be written with or without brackets.
+func Parse(value string) (*Map, error) {
+	m := &Map{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.New("invalid resolve entry, expected host:port:ip: " + entry)
+		}
+		ip := strings.Trim(parts[2], "[]")
+		if net.ParseIP(ip) == nil {
+			return nil, errors.New("invalid resolve ip: " + entry)
+		}
+		m.rules = append(m.rules, Rule{Host: strings.ToLower(parts[0]), Port: parts[1], IP: ip})
+	}
+	if len(m.rules) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// Rules returns the rules of the map
+func (m *Map) Rules() []Rule {
+	if m == nil {
+		return nil
+	}
+	return m.rules
+}
+
+// With returns a copy of the map with rules added in front of the existing ones
+func (m *Map) With(rules ...Rule) *Map {
+	merged := &Map{rules: append([]Rule{}, rules...)}
+	for i := range merged.rules {
+		merged.rules[i].Host = strings.ToLower(merged.rules[i].Host)
+	}
+	merged.rules = append(merged.rules, m.Rules()...)
+	return merged
+}
+
+// Lookup returns the IP a host and port are pointed at
+func (m *Map) Lookup(host, port string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	host = strings.ToLower(host)
+	for _, rule := range m.rules {
+		if rule.Host == host && (rule.Port == AnyPort || rule.Port == port) {
+			return rule.IP, true
+		}
+	}
+	return "", false
+}
+
+// Address rewrites a host:port dial address to the mapped ip:port
+func (m *Map) Address(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	if ip, ok := m.Lookup(host, port); ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return address
+}
+
+// Dial wraps a dial function so mapped hosts are dialed at their IP. TLS is
+// negotiated by the caller on top of the connection, so SNI, the Host header
+// and cookies keep the real hostname.
+func (m *Map) Dial(dial DialFunc) DialFunc {
+	if m == nil {
+		return dial
+	}
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dial(ctx, network, m.Address(address))
+	}
+}
+
+// HostResolverRules returns the value of Chromium's --host-resolver-rules flag
+func (m *Map) HostResolverRules() string {
+	var rules []string
+	for _, rule := range m.Rules() {
+		ip := rule.IP
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		pattern := rule.Host
+		if rule.Port != AnyPort {
+			pattern += ":" + rule.Port
+		}
+		rules = append(rules, "MAP "+pattern+" "+ip)
+	}
+	return strings.Join(rules, ", ")
+}
@@ -0,0 +1,24 @@
+package resolve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	m, err := Parse("www.example.com:443:10.0.0.8, api.example.com:*:[::1]")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.8:443", m.Address("www.example.com:443"))
+	assert.Equal(t, "www.example.com:80", m.Address("www.example.com:80"))
+	assert.Equal(t, "[::1]:8080", m.Address("API.example.com:8080"))
+	assert.Equal(t, "MAP www.example.com:443 10.0.0.8, MAP api.example.com [::1]", m.HostResolverRules())
+
+	_, err = Parse("www.example.com:443:not-an-ip")
+	assert.NotNil(t, err)
+
+	m, err = Parse("")
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+	assert.Equal(t, "", m.HostResolverRules())
+}
@@ -0,0 +1,255 @@
+package rewrite
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Header is a single HTTP header
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Replace replaces the matches of a regex
+type Replace struct {
+	Regex   string `yaml:"regex"`
+	Replace string `yaml:"replace"`
+	regex   *regexp.Regexp
+}
+
+// HeaderRules modifies a header list
+type HeaderRules struct {
+	// Set adds headers, replacing the value of existing ones
+	Set map[string]string `yaml:"set"`
+	// Remove removes headers by name (case-insensitive)
+	Remove []string `yaml:"remove"`
+}
+
+// RequestRules rewrites a request before it is sent
+type RequestRules struct {
+	Headers HeaderRules `yaml:"headers"`
+	URL     []*Replace  `yaml:"url"`
+}
+
+// ResponseRules rewrites a response before the page receives it
+type ResponseRules struct {
+	Headers HeaderRules `yaml:"headers"`
+	Body    []*Replace  `yaml:"body"`
+}
+
+// Rule is a match-and-replace rule scoped by URL and resource type
+type Rule struct {
+	Name string `yaml:"name"`
+	// URL is a regex matched against the request URL, empty matches all
+	URL string `yaml:"url"`
+	// Types are CDP resource types (document, script, xhr, fetch...), empty matches all
+	Types    []string      `yaml:"types"`
+	Request  RequestRules  `yaml:"request"`
+	Response ResponseRules `yaml:"response"`
+	url      *regexp.Regexp
+}
+
+// RuleSet is an ordered list of rewrite rules
+type RuleSet struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Load reads a YAML rule set from disk
+//
+//	rules:
+//	  - name: strip-csp
+//	    url: example\.com
+//	    types: [document]
+//	    request:
+//	      headers:
+//	        set: {X-Forwarded-For: 127.0.0.1}
+//	        remove: [Origin]
+//	      url:
+//	        - regex: "debug=0"
+//	          replace: "debug=1"
+//	    response:
+//	      headers:
+//	        remove: [Content-Security-Policy, X-Frame-Options]
+//	      body:
+//	        - regex: "disabled"
+//	          replace: ""
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses and compiles a YAML rule set
+func Parse(data []byte) (*RuleSet, error) {
+	rules := &RuleSet{}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, errors.New("rule " + rule.Name + ": " + err.Error())
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) compile() error {
+	if r.URL != "" {
+		compiled, err := regexp.Compile(r.URL)
+		if err != nil {
+			return err
+		}
+		r.url = compiled
+	}
+	replaces := append(append([]*Replace{}, r.Request.URL...), r.Response.Body...)
+	for _, replace := range replaces {
+		compiled, err := regexp.Compile(replace.Regex)
+		if err != nil {
+			return err
+		}
+		replace.regex = compiled
+	}
+	return nil
+}
+
+func (r *Rule) match(url, resourceType string) bool {
+	if r.url != nil && !r.url.MatchString(url) {
+		return false
+	}
+	if len(r.Types) == 0 {
+		return true
+	}
+	for _, t := range r.Types {
+		if strings.EqualFold(t, resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) hasRequestRules() bool {
+	return len(r.Request.Headers.Set) > 0 || len(r.Request.Headers.Remove) > 0 || len(r.Request.URL) > 0
+}
+
+func (r *Rule) hasResponseRules() bool {
+	return len(r.Response.Headers.Set) > 0 || len(r.Response.Headers.Remove) > 0 || len(r.Response.Body) > 0
+}
+
+// HasRequestRules returns true if any rule rewrites requests
+func (rs *RuleSet) HasRequestRules() bool {
+	if rs == nil {
+		return false
+	}
+	for _, rule := range rs.Rules {
+		if rule.hasRequestRules() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasResponseRules returns true if any rule rewrites responses
+func (rs *RuleSet) HasResponseRules() bool {
+	if rs == nil {
+		return false
+	}
+	for _, rule := range rs.Rules {
+		if rule.hasResponseRules() {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchResponse returns true if a response of url needs to be rewritten
+func (rs *RuleSet) MatchResponse(url, resourceType string) bool {
+	if rs == nil {
+		return false
+	}
+	for _, rule := range rs.Rules {
+		if rule.hasResponseRules() && rule.match(url, resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// RewriteRequest applies the matching rules to a request and
+// returns the new URL and headers and whether anything changed
+func (rs *RuleSet) RewriteRequest(url, resourceType string, headers []Header) (string, []Header, bool) {
+	if rs == nil {
+		return url, headers, false
+	}
+	changed := false
+	for _, rule := range rs.Rules {
+		if !rule.hasRequestRules() || !rule.match(url, resourceType) {
+			continue
+		}
+		for _, replace := range rule.Request.URL {
+			url = replace.regex.ReplaceAllString(url, replace.Replace)
+		}
+		headers = rule.Request.Headers.apply(headers)
+		changed = true
+	}
+	return url, headers, changed
+}
+
+// RewriteResponse applies the matching rules to a response and returns the
+// new headers and body and whether anything changed. The body is expected to
+// be decoded, so Content-Encoding and Content-Length are dropped when it changes.
+func (rs *RuleSet) RewriteResponse(url, resourceType string, headers []Header, body []byte) ([]Header, []byte, bool) {
+	if rs == nil {
+		return headers, body, false
+	}
+	changed := false
+	for _, rule := range rs.Rules {
+		if !rule.hasResponseRules() || !rule.match(url, resourceType) {
+			continue
+		}
+		for _, replace := range rule.Response.Body {
+			body = replace.regex.ReplaceAll(body, []byte(replace.Replace))
+		}
+		headers = rule.Response.Headers.apply(headers)
+		changed = true
+	}
+	if changed {
+		headers = removeHeaders(headers, []string{"Content-Encoding", "Content-Length"})
+	}
+	return headers, body, changed
+}
+
+func (h HeaderRules) apply(headers []Header) []Header {
+	names := append([]string{}, h.Remove...)
+	for name := range h.Set {
+		names = append(names, name)
+	}
+	headers = removeHeaders(headers, names)
+	for name, value := range h.Set {
+		headers = append(headers, Header{Name: name, Value: value})
+	}
+	return headers
+}
+
+func removeHeaders(headers []Header, names []string) []Header {
+	result := make([]Header, 0, len(headers))
+	for _, header := range headers {
+		removed := false
+		for _, name := range names {
+			if strings.EqualFold(header.Name, name) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			result = append(result, header)
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleSet_Rewrite(t *testing.T) {
+	rules, err := Parse([]byte(`
+rules:
+  - url: example\.com
+    types: [Document]
+    request:
+      headers:
+        set: {X-Test: "1"}
+        remove: [origin]
+      url:
+        - regex: debug=0
+          replace: debug=1
+    response:
+      headers:
+        remove: [Content-Security-Policy]
+      body:
+        - regex: disabled
+          replace: ""
+`))
+	assert.Nil(t, err)
+
+	url, headers, changed := rules.RewriteRequest("https://example.com/?debug=0", "document",
+		[]Header{{Name: "Origin", Value: "https://example.com"}, {Name: "Accept", Value: "*/*"}})
+	assert.True(t, changed)
+	assert.Equal(t, "https://example.com/?debug=1", url)
+	assert.Equal(t, []Header{{Name: "Accept", Value: "*/*"}, {Name: "X-Test", Value: "1"}}, headers)
+
+	_, _, changed = rules.RewriteRequest("https://example.com/app.js", "script", nil)
+	assert.False(t, changed)
+	assert.False(t, rules.MatchResponse("https://other.com/", "document"))
+
+	headers, body, changed := rules.RewriteResponse("https://example.com/", "document",
+		[]Header{{Name: "Content-Security-Policy", Value: "default-src 'self'"}, {Name: "Content-Encoding", Value: "gzip"}},
+		[]byte(`<button disabled>`))
+	assert.True(t, changed)
+	assert.Empty(t, headers)
+	assert.Equal(t, "<button >", string(body))
+}
@@ -0,0 +1,102 @@
+package roles
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// ReplayFunc requests an endpoint as identity and returns the status code
+type ReplayFunc func(identity Identity, endpoint Endpoint) (int, error)
+
+// Exclusive is an endpoint only discovered by a role and those above it
+type Exclusive struct {
+	Endpoint
+	// Role is the lowest privileged identity that discovered the endpoint
+	Role string `json:"role"`
+}
+
+// Exposure is an exclusive endpoint that still responds successfully
+// for a lower privileged identity, a broken access control candidate.
+type Exposure struct {
+	Endpoint
+	Role       string `json:"role"`
+	Identity   string `json:"identity"`
+	StatusCode int    `json:"status_code"`
+}
+
+// Report is the access control difference report of a multi-role crawl
+type Report struct {
+	Identities []Identity            `json:"identities"`
+	Endpoints  map[string][]Endpoint `json:"endpoints"`
+	Exclusive  []Exclusive           `json:"exclusive"`
+	Exposures  []Exposure            `json:"exposures"`
+}
+
+// BuildReport compares what every identity discovered. identities must be
+// sorted highest privilege first. If replay is not nil, every exclusive
+// endpoint is requested again as each lower privileged identity.
+func BuildReport(identities []Identity, collector *Collector, replay ReplayFunc) *Report {
+	report := &Report{
+		Identities: identities,
+		Endpoints:  make(map[string][]Endpoint),
+	}
+	for _, identity := range identities {
+		report.Endpoints[identity.Name] = collector.Endpoints(identity.Name)
+	}
+
+	seen := make(map[string]struct{})
+	// walk from the lowest privileged role up, so an endpoint is attributed
+	// to the least privileged role that could see it
+	for i := len(identities) - 2; i >= 0; i-- {
+		role := identities[i]
+		lower := identities[i+1:]
+		for _, endpoint := range report.Endpoints[role.Name] {
+			if _, ok := seen[endpoint.key()]; ok {
+				continue
+			}
+			if visibleToAny(collector, lower, endpoint) {
+				continue
+			}
+			seen[endpoint.key()] = struct{}{}
+			report.Exclusive = append(report.Exclusive, Exclusive{Endpoint: endpoint, Role: role.Name})
+
+			if replay == nil {
+				continue
+			}
+			for _, identity := range lower {
+				if identity.Level >= role.Level {
+					continue
+				}
+				statusCode, err := replay(identity, endpoint)
+				if err != nil || statusCode < 200 || statusCode >= 300 {
+					continue
+				}
+				report.Exposures = append(report.Exposures, Exposure{
+					Endpoint:   endpoint,
+					Role:       role.Name,
+					Identity:   identity.Name,
+					StatusCode: statusCode,
+				})
+			}
+		}
+	}
+	return report
+}
+
+func visibleToAny(collector *Collector, identities []Identity, endpoint Endpoint) bool {
+	for _, identity := range identities {
+		if collector.has(identity.Name, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,34 @@
+package roles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildReport(t *testing.T) {
+	identities := []Identity{{Name: "admin", Level: 2}, {Name: "user", Level: 1}, {Name: "anonymous"}}
+	collector := NewCollector()
+	collector.Add("admin", Endpoint{URL: "https://example.com/"})
+	collector.Add("admin", Endpoint{URL: "https://example.com/admin/users"})
+	collector.Add("admin", Endpoint{URL: "https://example.com/profile"})
+	collector.Add("user", Endpoint{URL: "https://example.com/"})
+	collector.Add("user", Endpoint{URL: "https://example.com/profile"})
+	collector.Add("anonymous", Endpoint{URL: "https://example.com/"})
+
+	replay := func(identity Identity, endpoint Endpoint) (int, error) {
+		if endpoint.URL == "https://example.com/admin/users" && identity.Name == "user" {
+			return 200, nil
+		}
+		return 403, nil
+	}
+	report := BuildReport(identities, collector, replay)
+
+	assert.Equal(t, []Exclusive{
+		{Endpoint: Endpoint{Method: "GET", URL: "https://example.com/profile"}, Role: "user"},
+		{Endpoint: Endpoint{Method: "GET", URL: "https://example.com/admin/users"}, Role: "admin"},
+	}, report.Exclusive)
+	assert.Equal(t, []Exposure{
+		{Endpoint: Endpoint{Method: "GET", URL: "https://example.com/admin/users"}, Role: "admin", Identity: "user", StatusCode: 200},
+	}, report.Exposures)
+}
@@ -0,0 +1,131 @@
+package roles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Identity is a role used to crawl the target, e.g. admin, user or anonymous
+type Identity struct {
+	Name string `yaml:"name" json:"name"`
+	// Level is the privilege level, higher is more privileged
+	Level   int               `yaml:"level" json:"level"`
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+}
+
+// FileName returns the name of the identity usable in a file name,
+// characters other than letters, digits, '-', '_' and '.' are replaced
+func (i Identity) FileName() string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(filepath.Clean("/"+i.Name)))
+}
+
+// LoadIdentities reads a YAML list of identities, each with a name, a level
+// and optional headers, and returns them sorted by privilege, highest first.
+func LoadIdentities(path string) ([]Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var identities []Identity
+	if err := yaml.Unmarshal(data, &identities); err != nil {
+		return nil, err
+	}
+	if len(identities) < 2 {
+		return nil, errors.New("at least two identities are required")
+	}
+	names := make(map[string]struct{})
+	for _, identity := range identities {
+		if identity.Name == "" {
+			return nil, errors.New("identity name is required")
+		}
+		// identities writing to the same result files are duplicates as well
+		if _, ok := names[identity.FileName()]; ok {
+			return nil, errors.New("duplicate identity: " + identity.Name)
+		}
+		names[identity.FileName()] = struct{}{}
+	}
+	sort.SliceStable(identities, func(i, j int) bool {
+		return identities[i].Level > identities[j].Level
+	})
+	return identities, nil
+}
+
+// Endpoint is a discovered request
+type Endpoint struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Safe returns true if replaying the endpoint does not modify the target
+func (e Endpoint) Safe() bool {
+	switch strings.ToUpper(e.Method) {
+	case "", "GET", "HEAD":
+		return true
+	}
+	return false
+}
+
+func (e Endpoint) key() string {
+	return strings.ToUpper(e.Method) + " " + e.URL + " " + e.Body
+}
+
+// Collector collects the endpoints discovered by every identity
+type Collector struct {
+	endpoints map[string]map[string]Endpoint
+	lock      sync.Mutex
+}
+
+// NewCollector returns a new endpoint collector
+func NewCollector() *Collector {
+	return &Collector{endpoints: make(map[string]map[string]Endpoint)}
+}
+
+// Add records an endpoint discovered while crawling as identity
+func (c *Collector) Add(identity string, endpoint Endpoint) {
+	if endpoint.URL == "" {
+		return
+	}
+	if endpoint.Method == "" {
+		endpoint.Method = "GET"
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.endpoints[identity]; !ok {
+		c.endpoints[identity] = make(map[string]Endpoint)
+	}
+	c.endpoints[identity][endpoint.key()] = endpoint
+}
+
+// Endpoints returns the endpoints of an identity sorted by URL
+func (c *Collector) Endpoints(identity string) []Endpoint {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var endpoints []Endpoint
+	for _, endpoint := range c.endpoints[identity] {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].key() < endpoints[j].key()
+	})
+	return endpoints
+}
+
+func (c *Collector) has(identity string, endpoint Endpoint) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.endpoints[identity][endpoint.key()]
+	return ok
+}
@@ -0,0 +1,58 @@
+package roles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoint_Safe(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"", true},
+		{"GET", true},
+		{"head", true},
+		{"POST", false},
+		{"PUT", false},
+		{"DELETE", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, Endpoint{Method: test.method}.Safe(), test.method)
+	}
+}
+
+func TestIdentity_FileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"admin", "admin"},
+		{"管理员", "管理员"},
+		{"user-1.ro", "user-1.ro"},
+		{"../../etc/cron.d/x", "x"},
+		{"..", "_"},
+		{"/", "_"},
+		{"a b:c", "a_b_c"},
+		{`..\evil`, ".._evil"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, Identity{Name: test.name}.FileName(), test.name)
+	}
+}
+
+func TestLoadIdentities(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roles.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("- name: admin\n  level: 1\n- name: user\n"), 0o644))
+	identities, err := LoadIdentities(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"admin", "user"}, []string{identities[0].Name, identities[1].Name})
+
+	// names writing to the same result files
+	assert.Nil(t, os.WriteFile(path, []byte("- name: a/user\n  level: 1\n- name: user\n"), 0o644))
+	_, err = LoadIdentities(path)
+	assert.NotNil(t, err)
+}
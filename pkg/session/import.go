@@ -0,0 +1,161 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadCookieFile reads cookies from a Netscape cookies.txt file or a
+// JSON array exported by Chrome DevTools or a cookie editor extension.
+func LoadCookieFile(path string) ([]Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return ParseJSONCookies(trimmed)
+	}
+	return ParseNetscapeCookies(data)
+}
+
+// ParseNetscapeCookies parses the Netscape cookies.txt format, seven tab
+// separated fields: domain, include subdomains, path, secure, expires, name, value
+func ParseNetscapeCookies(data []byte) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, errors.New("invalid cookies.txt line " + strconv.Itoa(lineNumber))
+		}
+		cookie := Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HTTPOnly: httpOnly,
+		}
+		// a cookie sent to the subdomains is written with a leading dot
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(cookie.Domain, ".") {
+			cookie.Domain = "." + cookie.Domain
+		}
+		if expires, err := strconv.ParseFloat(fields[4], 64); err == nil && expires > 0 {
+			cookie.Expires = expires
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, scanner.Err()
+}
+
+// jsonCookie covers the fields of the DevTools (Network.Cookie) and the
+// EditThisCookie / Cookie-Editor export formats
+type jsonCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Expires        float64 `json:"expires"`
+	ExpirationDate float64 `json:"expirationDate"`
+	HTTPOnly       bool    `json:"httpOnly"`
+	Secure         bool    `json:"secure"`
+	Session        bool    `json:"session"`
+	SameSite       string  `json:"sameSite"`
+}
+
+// ParseJSONCookies parses a JSON cookie array
+func ParseJSONCookies(data []byte) ([]Cookie, error) {
+	var items []jsonCookie
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	var cookies []Cookie
+	for _, item := range items {
+		if item.Name == "" {
+			continue
+		}
+		cookie := Cookie{
+			Name:     item.Name,
+			Value:    item.Value,
+			Domain:   item.Domain,
+			Path:     item.Path,
+			HTTPOnly: item.HTTPOnly,
+			Secure:   item.Secure,
+			SameSite: normalizeSameSite(item.SameSite),
+		}
+		if !item.Session {
+			if item.Expires > 0 {
+				cookie.Expires = item.Expires
+			} else if item.ExpirationDate > 0 {
+				cookie.Expires = item.ExpirationDate
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// normalizeSameSite maps the extension export values (no_restriction, lax,
+// strict, unspecified) to the CDP values (None, Lax, Strict)
+func normalizeSameSite(value string) string {
+	switch strings.ToLower(value) {
+	case "none", "no_restriction":
+		return "None"
+	case "lax":
+		return "Lax"
+	case "strict":
+		return "Strict"
+	}
+	return ""
+}
+
+// storageDump is the web storage of one origin
+type storageDump struct {
+	LocalStorage   map[string]string `json:"localStorage"`
+	SessionStorage map[string]string `json:"sessionStorage"`
+}
+
+// LoadStorageFile reads a JSON dump of web storage keyed by origin into state
+//
+//	{
+//	  "https://app.example.com": {
+//	    "localStorage": {"token": "eyJhbGciOi..."},
+//	    "sessionStorage": {"tab": "1"}
+//	  }
+//	}
+func LoadStorageFile(path string, state *State) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var dump map[string]storageDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return err
+	}
+	for origin, storage := range dump {
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return errors.New("invalid origin: " + origin)
+		}
+		if len(storage.LocalStorage) > 0 {
+			state.SetLocalStorage(origin, storage.LocalStorage)
+		}
+		if len(storage.SessionStorage) > 0 {
+			state.SetSessionStorage(origin, storage.SessionStorage)
+		}
+	}
+	return nil
+}
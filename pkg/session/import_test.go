@@ -0,0 +1,30 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	data := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tTRUE\t1893456000\tsid\tabc\n" +
+		"#HttpOnly_app.example.com\tFALSE\t/api\tFALSE\t0\ttoken\txyz\n"
+	cookies, err := ParseNetscapeCookies([]byte(data))
+	assert.Nil(t, err)
+	assert.Equal(t, []Cookie{
+		{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: 1893456000, Secure: true},
+		{Name: "token", Value: "xyz", Domain: "app.example.com", Path: "/api", HTTPOnly: true},
+	}, cookies)
+}
+
+func TestParseJSONCookies(t *testing.T) {
+	data := `[{"name":"sid","value":"abc","domain":".example.com","path":"/","expirationDate":1893456000.5,"sameSite":"no_restriction","secure":true},
+		{"name":"tmp","value":"1","domain":"example.com","path":"/","session":true,"expires":-1}]`
+	cookies, err := ParseJSONCookies([]byte(data))
+	assert.Nil(t, err)
+	assert.Equal(t, []Cookie{
+		{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: 1893456000.5, Secure: true, SameSite: "None"},
+		{Name: "tmp", Value: "1", Domain: "example.com", Path: "/"},
+	}, cookies)
+}
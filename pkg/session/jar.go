@@ -0,0 +1,166 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Jar is a cookie jar that remembers the full attributes of every cookie
+// it was given, so its content can be exported and persisted between runs.
+type Jar struct {
+	jar     *cookiejar.Jar
+	cookies map[string]Cookie
+	lock    sync.Mutex
+}
+
+// NewJar returns an empty cookie jar
+func NewJar() (*Jar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	return &Jar{jar: jar, cookies: make(map[string]Cookie)}, nil
+}
+
+// SetCookies implements http.CookieJar. Only the cookies the jar accepted
+// are recorded, a host-only cookie is recorded with the bare hostname and a
+// domain cookie with a leading dot.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		cookie := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   u.Hostname(),
+			Path:     c.Path,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+			SameSite: sameSiteString(c.SameSite),
+		}
+		if c.Domain != "" {
+			cookie.Domain = "." + strings.TrimPrefix(c.Domain, ".")
+		}
+		if cookie.Path == "" || cookie.Path[0] != '/' {
+			cookie.Path = defaultCookiePath(u.Path)
+		}
+		key := cookie.Name + ";" + cookie.Domain + ";" + cookie.Path
+		switch {
+		case c.MaxAge < 0:
+			delete(j.cookies, key)
+			continue
+		case c.MaxAge > 0:
+			cookie.Expires = float64(now.Add(time.Duration(c.MaxAge) * time.Second).Unix())
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(j.cookies, key)
+				continue
+			}
+			cookie.Expires = float64(c.Expires.Unix())
+		}
+		if !j.accepted(u, cookie) {
+			continue
+		}
+		j.cookies[key] = cookie
+	}
+}
+
+// accepted returns true if the underlying jar kept a cookie, it ignores
+// the cookies set for another domain or for a public suffix
+func (j *Jar) accepted(u *url.URL, cookie Cookie) bool {
+	scheme := "http"
+	if cookie.Secure {
+		scheme = "https"
+	}
+	host := u.Host
+	if strings.HasPrefix(cookie.Domain, ".") {
+		host = strings.TrimPrefix(cookie.Domain, ".")
+	}
+	for _, c := range j.jar.Cookies(&url.URL{Scheme: scheme, Host: host, Path: cookie.Path}) {
+		if c.Name == cookie.Name && c.Value == cookie.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// Cookies implements http.CookieJar
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// Export returns the cookies of the jar which have not expired
+func (j *Jar) Export() []Cookie {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	now := float64(time.Now().Unix())
+	var cookies []Cookie
+	for _, cookie := range j.cookies {
+		if cookie.Expires > 0 && cookie.Expires <= now {
+			continue
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+// Load seeds the jar from a cookie file, see LoadCookieFile
+func (j *Jar) Load(path string) error {
+	cookies, err := LoadCookieFile(path)
+	if err != nil {
+		return err
+	}
+	state := NewState()
+	state.SetCookies(cookies)
+	state.Seed(j)
+	return nil
+}
+
+// Save writes the jar cookies to path as a JSON cookie array,
+// the same format LoadCookieFile accepts.
+func (j *Jar) Save(path string) error {
+	cookies := j.Export()
+	if cookies == nil {
+		cookies = []Cookie{}
+	}
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// defaultCookiePath is the directory of the request path, RFC 6265 section 5.1.4
+func defaultCookiePath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func sameSiteString(sameSite http.SameSite) string {
+	switch sameSite {
+	case http.SameSiteNoneMode:
+		return "None"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	}
+	return ""
+}
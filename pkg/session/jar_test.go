@@ -0,0 +1,71 @@
+package session
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cookieNames(cookies []*http.Cookie) []string {
+	var names []string
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestJar_SetCookies(t *testing.T) {
+	jar, err := NewJar()
+	assert.Nil(t, err)
+	u, _ := url.Parse("https://app.example.com/account/login")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1", Path: "/"},
+		{Name: "domain", Value: "2", Domain: "example.com", Path: "/"},
+		{Name: "other", Value: "3", Domain: "other.com", Path: "/"},
+		{Name: "suffix", Value: "4", Domain: "com", Path: "/"},
+		{Name: "expired", Value: "5", Path: "/", Expires: time.Now().Add(-time.Hour)},
+		{Name: "path", Value: "6"},
+	})
+
+	domains := make(map[string]string)
+	for _, cookie := range jar.Export() {
+		domains[cookie.Name] = cookie.Domain + cookie.Path
+	}
+	assert.Equal(t, map[string]string{
+		"host":   "app.example.com/",
+		"domain": ".example.com/",
+		"path":   "app.example.com/account",
+	}, domains, "should record only the cookies accepted by the jar")
+
+	u, _ = url.Parse("https://app.example.com/")
+	jar.SetCookies(u, []*http.Cookie{{Name: "host", Value: "1", Path: "/", MaxAge: -1}})
+	assert.Len(t, jar.Export(), 2, "should forget a deleted cookie")
+}
+
+func TestJar_SaveLoad(t *testing.T) {
+	jar, err := NewJar()
+	assert.Nil(t, err)
+	u, _ := url.Parse("https://app.example.com/login")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1", Path: "/"},
+		{Name: "domain", Value: "2", Domain: "example.com", Path: "/"},
+	})
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	assert.Nil(t, jar.Save(path))
+	loaded, err := NewJar()
+	assert.Nil(t, err)
+	assert.Nil(t, loaded.Load(path))
+
+	app, _ := url.Parse("https://app.example.com/")
+	api, _ := url.Parse("https://api.example.com/")
+	assert.Equal(t, []string{"domain", "host"}, cookieNames(loaded.Cookies(app)))
+	assert.Equal(t, []string{"domain"}, cookieNames(loaded.Cookies(api)), "should keep the host-only cookie on its host")
+	assert.ElementsMatch(t, jar.Export(), loaded.Export())
+}
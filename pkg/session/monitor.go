@@ -0,0 +1,181 @@
+package session
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxReauthRetries is how many times a request is requeued after re-authentication
+const MaxReauthRetries = 1
+
+// Signature describes what a "logged-out" response looks like
+type Signature struct {
+	// LoginURL matches when the response URL or its redirect location contains it
+	LoginURL string
+	// BodyRegex matches against the response body
+	BodyRegex *regexp.Regexp
+	// StatusCodes matches the response status code
+	StatusCodes []int
+}
+
+// ParseSignature builds a signature from user input. statusCodes is a comma separated list.
+func ParseSignature(loginURL, bodyRegex, statusCodes string) (*Signature, error) {
+	signature := &Signature{LoginURL: strings.TrimSpace(loginURL)}
+	if bodyRegex != "" {
+		compiled, err := regexp.Compile(bodyRegex)
+		if err != nil {
+			return nil, err
+		}
+		signature.BodyRegex = compiled
+	}
+	for _, item := range strings.Split(statusCodes, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		code, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, errors.New("invalid status code: " + item)
+		}
+		signature.StatusCodes = append(signature.StatusCodes, code)
+	}
+	if signature.Empty() {
+		return nil, nil
+	}
+	return signature, nil
+}
+
+// Empty returns true if the signature has no condition
+func (s *Signature) Empty() bool {
+	return s.LoginURL == "" && s.BodyRegex == nil && len(s.StatusCodes) == 0
+}
+
+// Match returns true if a response looks like the user was logged out
+func (s *Signature) Match(statusCode int, url, location, body string) bool {
+	if s == nil {
+		return false
+	}
+	if s.LoginURL != "" && (strings.Contains(url, s.LoginURL) || (location != "" && strings.Contains(location, s.LoginURL))) {
+		return true
+	}
+	for _, code := range s.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return s.BodyRegex != nil && body != "" && s.BodyRegex.MatchString(body)
+}
+
+// RefreshFunc re-authenticates and returns the new session state
+type RefreshFunc func() (*State, error)
+
+// Monitor detects session loss and re-authenticates once for all
+// concurrent workers, pausing them while the refresh is running.
+type Monitor struct {
+	signature  *Signature
+	state      *State
+	refresh    RefreshFunc
+	callbacks  []refreshCallback
+	lastID     int
+	generation int
+	pause      sync.RWMutex
+	lock       sync.Mutex
+}
+
+// refreshCallback is a callback registered with OnRefresh
+type refreshCallback struct {
+	id       int
+	callback func(*State)
+}
+
+// NewMonitor returns a monitor updating state in place through refresh
+func NewMonitor(signature *Signature, state *State, refresh RefreshFunc) *Monitor {
+	return &Monitor{signature: signature, state: state, refresh: refresh}
+}
+
+// Check returns true if the response matches the logged-out signature
+func (m *Monitor) Check(statusCode int, url, location, body string) bool {
+	if m == nil {
+		return false
+	}
+	return m.signature.Match(statusCode, url, location, body)
+}
+
+// Wait blocks while a re-authentication is in progress and
+// returns the current session generation.
+func (m *Monitor) Wait() int {
+	if m == nil {
+		return 0
+	}
+	m.pause.RLock()
+	defer m.pause.RUnlock()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.generation
+}
+
+// OnRefresh registers a callback run with the new state after re-authentication
+// and returns the function unregistering it
+func (m *Monitor) OnRefresh(callback func(*State)) func() {
+	if m == nil {
+		return func() {}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lastID++
+	id := m.lastID
+	m.callbacks = append(m.callbacks, refreshCallback{id: id, callback: callback})
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		for i, c := range m.callbacks {
+			if c.id == id {
+				m.callbacks = append(m.callbacks[:i:i], m.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Reauthenticate refreshes the session if it has not already been refreshed
+// since generation was observed. Crawling is paused until it finishes.
+func (m *Monitor) Reauthenticate(generation int) error {
+	if m == nil || m.refresh == nil {
+		return nil
+	}
+	m.pause.Lock()
+	defer m.pause.Unlock()
+
+	m.lock.Lock()
+	if generation != m.generation {
+		// another worker already refreshed the session
+		m.lock.Unlock()
+		return nil
+	}
+	m.lock.Unlock()
+
+	state, err := m.refresh()
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.generation++
+	if state != nil && state != m.state {
+		m.state.SetCookies(state.GetCookies())
+		for origin, items := range state.GetLocalStorage() {
+			m.state.SetLocalStorage(origin, items)
+		}
+		for origin, items := range state.GetSessionStorage() {
+			m.state.SetSessionStorage(origin, items)
+		}
+	}
+	for _, c := range m.callbacks {
+		c.callback(m.state)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature_Match(t *testing.T) {
+	signature, err := ParseSignature("/login", "Please sign in", "401")
+	assert.Nil(t, err)
+	assert.True(t, signature.Match(302, "https://example.com/admin", "https://example.com/login?next=/admin", ""))
+	assert.True(t, signature.Match(200, "https://example.com/admin", "", "<h1>Please sign in</h1>"))
+	assert.True(t, signature.Match(401, "https://example.com/admin", "", ""))
+	assert.False(t, signature.Match(200, "https://example.com/admin", "", "<h1>Dashboard</h1>"))
+
+	signature, err = ParseSignature("", "", "")
+	assert.Nil(t, err)
+	assert.Nil(t, signature)
+}
+
+func TestMonitor_Reauthenticate(t *testing.T) {
+	state := NewState()
+	refreshed := 0
+	monitor := NewMonitor(&Signature{LoginURL: "/login"}, state, func() (*State, error) {
+		refreshed++
+		fresh := NewState()
+		fresh.SetCookies([]Cookie{{Name: "sid", Value: "fresh", Domain: "example.com"}})
+		return fresh, nil
+	})
+
+	// concurrent workers observing the same generation only refresh once
+	generation := monitor.Wait()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, monitor.Reauthenticate(generation))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, refreshed)
+	assert.Equal(t, "fresh", state.GetCookies()[0].Value)
+	assert.Equal(t, generation+1, monitor.Wait())
+}
+
+func TestMonitor_OnRefresh(t *testing.T) {
+	monitor := NewMonitor(&Signature{LoginURL: "/login"}, NewState(), func() (*State, error) {
+		return NewState(), nil
+	})
+	var calls []string
+	unregisterFirst := monitor.OnRefresh(func(*State) { calls = append(calls, "first") })
+	monitor.OnRefresh(func(*State) { calls = append(calls, "second") })
+
+	assert.Nil(t, monitor.Reauthenticate(monitor.Wait()))
+	assert.Equal(t, []string{"first", "second"}, calls)
+
+	unregisterFirst()
+	unregisterFirst()
+	calls = nil
+	assert.Nil(t, monitor.Reauthenticate(monitor.Wait()))
+	assert.Equal(t, []string{"second"}, calls, "should not run an unregistered callback")
+
+	var nilMonitor *Monitor
+	nilMonitor.OnRefresh(func(*State) {})()
+}
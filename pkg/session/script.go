@@ -0,0 +1,87 @@
+package session
+
+import (
+	"errors"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Login step actions
+const (
+	ActionNavigate = "navigate"
+	ActionType     = "type"
+	ActionClick    = "click"
+	ActionWait     = "wait"
+)
+
+// Step is a single step of a login script
+type Step struct {
+	Action   string `yaml:"action"`
+	URL      string `yaml:"url,omitempty"`      // navigate: target URL, wait: URL substring to wait for
+	Selector string `yaml:"selector,omitempty"` // type/click/wait: CSS selector
+	Value    string `yaml:"value,omitempty"`    // type: text to type
+	Timeout  int    `yaml:"timeout,omitempty"`  // wait: timeout in seconds
+}
+
+// Script is a login flow executed in a browser tab before crawling
+type Script struct {
+	Steps []Step `yaml:"steps"`
+}
+
+// LoadScript reads a YAML login script from disk
+//
+//	steps:
+//	  - action: navigate
+//	    url: https://example.com/login
+//	  - action: type
+//	    selector: "#username"
+//	    value: admin
+//	  - action: click
+//	    selector: "button[type=submit]"
+//	  - action: wait
+//	    url: /dashboard
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var script Script
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, err
+	}
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// Validate checks every step has the fields its action needs
+func (s *Script) Validate() error {
+	if len(s.Steps) == 0 {
+		return errors.New("login script has no steps")
+	}
+	for _, step := range s.Steps {
+		switch step.Action {
+		case ActionNavigate:
+			if step.URL == "" {
+				return errors.New("navigate step requires url")
+			}
+		case ActionType:
+			if step.Selector == "" {
+				return errors.New("type step requires selector")
+			}
+		case ActionClick:
+			if step.Selector == "" {
+				return errors.New("click step requires selector")
+			}
+		case ActionWait:
+			if step.Selector == "" && step.URL == "" {
+				return errors.New("wait step requires selector or url")
+			}
+		default:
+			return errors.New("unknown login step action: " + step.Action)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cookie is an engine independent cookie shared by crawlergo (chromedp),
+// katana's rod browser and katana's http client.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires,omitempty"` // seconds since the UNIX epoch, 0 for session cookies
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// State is an authenticated browser state: cookies plus the
+// localStorage and sessionStorage entries of every origin, keyed by origin.
+type State struct {
+	Cookies        []Cookie                     `json:"cookies"`
+	LocalStorage   map[string]map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]map[string]string `json:"sessionStorage,omitempty"`
+	lock           sync.RWMutex
+}
+
+// NewState returns an empty state
+func NewState() *State {
+	return &State{
+		LocalStorage:   make(map[string]map[string]string),
+		SessionStorage: make(map[string]map[string]string),
+	}
+}
+
+// SetCookies replaces all cookies of the state
+func (s *State) SetCookies(cookies []Cookie) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Cookies = cookies
+}
+
+// AddCookies adds cookies to the state, replacing the ones with
+// the same name, domain and path
+func (s *State) AddCookies(cookies []Cookie) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, cookie := range cookies {
+		replaced := false
+		for i, c := range s.Cookies {
+			if c.Name == cookie.Name && c.Domain == cookie.Domain && c.Path == cookie.Path {
+				s.Cookies[i] = cookie
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.Cookies = append(s.Cookies, cookie)
+		}
+	}
+}
+
+// GetCookies returns a copy of the state cookies
+func (s *State) GetCookies() []Cookie {
+	if s == nil {
+		return nil
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	cookies := make([]Cookie, len(s.Cookies))
+	copy(cookies, s.Cookies)
+	return cookies
+}
+
+// SetLocalStorage sets the localStorage items of an origin
+func (s *State) SetLocalStorage(origin string, items map[string]string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.LocalStorage == nil {
+		s.LocalStorage = make(map[string]map[string]string)
+	}
+	s.LocalStorage[strings.TrimSuffix(origin, "/")] = items
+}
+
+// GetLocalStorage returns a copy of the localStorage items keyed by origin
+func (s *State) GetLocalStorage() map[string]map[string]string {
+	if s == nil {
+		return make(map[string]map[string]string)
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return copyStorage(s.LocalStorage)
+}
+
+// SetSessionStorage sets the sessionStorage items of an origin
+func (s *State) SetSessionStorage(origin string, items map[string]string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.SessionStorage == nil {
+		s.SessionStorage = make(map[string]map[string]string)
+	}
+	s.SessionStorage[strings.TrimSuffix(origin, "/")] = items
+}
+
+// GetSessionStorage returns a copy of the sessionStorage items keyed by origin
+func (s *State) GetSessionStorage() map[string]map[string]string {
+	if s == nil {
+		return make(map[string]map[string]string)
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return copyStorage(s.SessionStorage)
+}
+
+func copyStorage(storage map[string]map[string]string) map[string]map[string]string {
+	copied := make(map[string]map[string]string, len(storage))
+	for origin, items := range storage {
+		values := make(map[string]string, len(items))
+		for key, value := range items {
+			values[key] = value
+		}
+		copied[origin] = values
+	}
+	return copied
+}
+
+// Clone returns a deep copy of the state
+func (s *State) Clone() *State {
+	clone := NewState()
+	clone.Cookies = s.GetCookies()
+	clone.LocalStorage = s.GetLocalStorage()
+	clone.SessionStorage = s.GetSessionStorage()
+	return clone
+}
+
+// HTTPCookies converts the state cookies into net/http cookies
+func (s *State) HTTPCookies() []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, c := range s.GetCookies() {
+		cookies = append(cookies, c.HTTPCookie())
+	}
+	return cookies
+}
+
+// HTTPCookie returns the cookie as set by a response. The domain of a
+// host-only cookie, written without a leading dot, is left empty so that
+// it is not sent to the subdomains.
+func (c Cookie) HTTPCookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		HttpOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+	}
+	if strings.HasPrefix(c.Domain, ".") {
+		cookie.Domain = c.Domain
+	}
+	if c.Expires > 0 {
+		cookie.Expires = time.Unix(int64(c.Expires), 0)
+	}
+	return cookie
+}
+
+// Jar returns a cookie jar seeded with the state cookies
+func (s *State) Jar() (*Jar, error) {
+	jar, err := NewJar()
+	if err != nil {
+		return nil, err
+	}
+	s.Seed(jar)
+	return jar, nil
+}
+
+// Seed adds the state cookies to an existing jar
+func (s *State) Seed(jar http.CookieJar) {
+	for _, c := range s.GetCookies() {
+		domain := strings.TrimPrefix(c.Domain, ".")
+		if domain == "" {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: "/"}, []*http.Cookie{c.HTTPCookie()})
+	}
+}
+
+// StorageInjectJS returns a script that restores the localStorage and
+// sessionStorage items of the current origin. It is meant to be evaluated
+// on every new document.
+func (s *State) StorageInjectJS() string {
+	if s == nil {
+		return ""
+	}
+	s.lock.RLock()
+	empty := len(s.LocalStorage) == 0 && len(s.SessionStorage) == 0
+	local, err := json.Marshal(s.LocalStorage)
+	if err != nil {
+		empty = true
+	}
+	sessionData, err := json.Marshal(s.SessionStorage)
+	if err != nil {
+		empty = true
+	}
+	s.lock.RUnlock()
+	if empty {
+		return ""
+	}
+	return fmt.Sprintf(storageInjectTemplate, local, sessionData)
+}
+
+const storageInjectTemplate = `(function () {
+	function restore(storage, items) {
+		if (!items) return;
+		for (var key in items) {
+			try { storage.setItem(key, items[key]); } catch (e) {}
+		}
+	}
+	try { restore(window.localStorage, (%s || {})[window.location.origin]); } catch (e) {}
+	try { restore(window.sessionStorage, (%s || {})[window.location.origin]); } catch (e) {}
+})();`
@@ -0,0 +1,259 @@
+package soft404
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"Venom-Crawler/pkg/vhost"
+)
+
+// Modes of a detector
+const (
+	// ModeMark keeps the soft-404 responses in the results and reports them
+	ModeMark = "mark"
+	// ModeDrop removes the soft-404 responses from the results
+	ModeDrop = "drop"
+)
+
+const (
+	// threshold is the DOM similarity above which a response is the same
+	// page as a baseline
+	threshold = 0.9
+	// bucketBase is the growth of the length buckets, the lengths of the
+	// same page vary with the reflected path and the tokens it embeds
+	bucketBase = 1.1
+	// minReflection is the length of the shortest reflected path removed
+	minReflection = 4
+)
+
+// titleRegex matches the title of a page
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Fingerprint summarizes a response so it can be compared with the
+// responses of non-existent paths
+type Fingerprint struct {
+	StatusCode   int    `json:"status_code"`
+	Location     string `json:"location,omitempty"`
+	LengthBucket int    `json:"length_bucket"`
+	Title        string `json:"title,omitempty"`
+	Hash         uint64 `json:"hash"`
+}
+
+// Response is a response to fingerprint
+type Response struct {
+	StatusCode int
+	Location   string
+	Body       string
+}
+
+// NewFingerprint fingerprints the response of a URL. The path of the URL is
+// removed from the body and the redirect first, error pages often reflect
+// it.
+func NewFingerprint(rawURL string, resp Response) Fingerprint {
+	body, location := resp.Body, resp.Location
+	for _, reflected := range reflections(rawURL) {
+		body = strings.ReplaceAll(body, reflected, "")
+		location = strings.ReplaceAll(location, reflected, "")
+	}
+	fingerprint := Fingerprint{
+		StatusCode:   resp.StatusCode,
+		Location:     location,
+		LengthBucket: int(math.Log(float64(len(body)+1)) / math.Log(bucketBase)),
+		Hash:         vhost.DOMHash(body),
+	}
+	if match := titleRegex.FindStringSubmatch(body); match != nil {
+		fingerprint.Title = strings.Join(strings.Fields(match[1]), " ")
+	}
+	return fingerprint
+}
+
+// reflections returns the forms of the path of a URL a page may reflect,
+// longest first. The short forms are kept, removing them would erase the
+// words of the page.
+func reflections(rawURL string) []string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Path == "" || parsed.Path == "/" {
+		return nil
+	}
+	name := path.Base(parsed.Path)
+	var forms []string
+	for _, form := range []string{parsed.EscapedPath(), parsed.Path, url.PathEscape(name), name} {
+		if len(form) >= minReflection {
+			forms = append(forms, form)
+		}
+	}
+	sort.SliceStable(forms, func(i, j int) bool {
+		return len(forms[i]) > len(forms[j])
+	})
+	return forms
+}
+
+// Matches returns true if a fingerprint describes the same page as a
+// baseline: same status, redirect and title, a close length and a
+// similar DOM
+func (f Fingerprint) Matches(baseline Fingerprint) bool {
+	if f.StatusCode != baseline.StatusCode || f.Location != baseline.Location || f.Title != baseline.Title {
+		return false
+	}
+	if diff := f.LengthBucket - baseline.LengthBucket; diff > 1 || diff < -1 {
+		return false
+	}
+	return vhost.Similarity(f.Hash, baseline.Hash) >= threshold
+}
+
+// ProbeFunc requests a URL without following redirects
+type ProbeFunc func(url string) (Response, error)
+
+// Match is a response found to be a soft-404
+type Match struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Baseline   string `json:"baseline"`
+}
+
+// baseline are the fingerprints of the non-existent paths of a directory
+type baseline struct {
+	once         sync.Once
+	fingerprints []Fingerprint
+}
+
+// Detector tells the soft-404 responses apart. The baseline of a directory
+// is built from a few random non-existent paths of the directory, with the
+// extension of the checked URL, the first time a URL of the directory is
+// checked.
+type Detector struct {
+	drop      bool
+	baselines map[string]*baseline
+	matches   map[string]Match
+	lock      sync.Mutex
+}
+
+// NewDetector returns a detector dropping or marking the soft-404 responses
+func NewDetector(mode string) (*Detector, error) {
+	if mode != ModeMark && mode != ModeDrop {
+		return nil, errors.New("soft-404 mode must be mark or drop")
+	}
+	return &Detector{drop: mode == ModeDrop, baselines: make(map[string]*baseline), matches: make(map[string]Match)}, nil
+}
+
+// Dropping returns true if the soft-404 responses are removed from the results
+func (d *Detector) Dropping() bool {
+	return d != nil && d.drop
+}
+
+// Check returns true if the response of a URL matches the baseline of its
+// directory, the match is recorded. The missing pages and the root of the
+// site, served for every path by catch-all sites, are not checked.
+func (d *Detector) Check(rawURL string, resp Response, probe ProbeFunc) bool {
+	if d == nil || resp.StatusCode == 404 || resp.StatusCode == 410 {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || parsed.Path == "" || parsed.Path == "/" {
+		return false
+	}
+	directory := parsed.Path[:strings.LastIndex(parsed.Path, "/")+1]
+	if directory == "" {
+		directory = "/"
+	}
+	extension := path.Ext(parsed.Path)
+	if strings.HasSuffix(parsed.Path, "/") {
+		extension = "/"
+	}
+	key := parsed.Scheme + "://" + parsed.Host + directory + " " + extension
+
+	d.lock.Lock()
+	b, ok := d.baselines[key]
+	if !ok {
+		b = &baseline{}
+		d.baselines[key] = b
+	}
+	d.lock.Unlock()
+	b.once.Do(func() {
+		b.fingerprints = probeBaseline(parsed.Scheme+"://"+parsed.Host+directory, extension, probe)
+	})
+
+	fingerprint := NewFingerprint(rawURL, resp)
+	for _, f := range b.fingerprints {
+		if fingerprint.Matches(f) {
+			d.lock.Lock()
+			d.matches[rawURL] = Match{URL: rawURL, StatusCode: resp.StatusCode, Baseline: key}
+			d.lock.Unlock()
+			return true
+		}
+	}
+	return false
+}
+
+// probeBaseline fingerprints two random paths with the extension and a
+// random directory
+func probeBaseline(directory string, extension string, probe ProbeFunc) []Fingerprint {
+	paths := []string{randomName() + extension, randomName() + extension, randomName() + "/"}
+	if extension == "/" {
+		paths = paths[1:]
+	}
+	var fingerprints []Fingerprint
+	for _, p := range paths {
+		probeURL := directory + p
+		resp, err := probe(probeURL)
+		if err != nil {
+			continue
+		}
+		fingerprints = append(fingerprints, NewFingerprint(probeURL, resp))
+	}
+	return fingerprints
+}
+
+// randomName returns a path segment which should not exist
+func randomName() string {
+	random := make([]byte, 6)
+	_, _ = rand.Read(random)
+	return "venom-" + hex.EncodeToString(random)
+}
+
+// Matched returns true if the URL was found to be a soft-404
+func (d *Detector) Matched(rawURL string) bool {
+	if d == nil {
+		return false
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	_, ok := d.matches[rawURL]
+	return ok
+}
+
+// Matches returns the soft-404 responses found sorted by URL
+func (d *Detector) Matches() []Match {
+	if d == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	matches := make([]Match, 0, len(d.matches))
+	for _, match := range d.matches {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].URL < matches[j].URL
+	})
+	return matches
+}
+
+// WriteJSON writes the soft-404 responses found to path
+func (d *Detector) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(d.Matches(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,101 @@
+package soft404
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const notFoundPage = `<html><head><title>Page not found</title></head><body><div class="nav"><a href="/">Home</a></div>
+<h1>Sorry</h1><p>The page %s does not exist.</p></body></html>`
+
+const aboutPage = `<html><head><title>About us</title></head><body><div class="nav"><a href="/">Home</a></div>
+<h1>About</h1><p>We make tools for the web since 2010, here is our story and our team.</p><ul><li>Jane</li><li>John</li></ul></body></html>`
+
+// catchAll serves a not found page with status 200 for every path but
+// /about and /admin/, /legacy/ redirects every path to the login page
+func catchAll(probed *[]string, lock *sync.Mutex) ProbeFunc {
+	return func(rawURL string) (Response, error) {
+		lock.Lock()
+		*probed = append(*probed, rawURL)
+		lock.Unlock()
+		parsed, _ := url.Parse(rawURL)
+		switch {
+		case parsed.Path == "/about":
+			return Response{StatusCode: 200, Body: aboutPage}, nil
+		case strings.HasPrefix(parsed.Path, "/legacy/"):
+			return Response{StatusCode: 302, Location: "/login?next=" + url.QueryEscape(parsed.Path)}, nil
+		}
+		return Response{StatusCode: 200, Body: strings.Replace(notFoundPage, "%s", parsed.Path, 1)}, nil
+	}
+}
+
+func TestDetector(t *testing.T) {
+	var probed []string
+	var lock sync.Mutex
+	probe := catchAll(&probed, &lock)
+	detector, err := NewDetector(ModeDrop)
+	assert.Nil(t, err)
+	assert.True(t, detector.Dropping())
+
+	check := func(rawURL string) bool {
+		resp, _ := probe(rawURL)
+		return detector.Check(rawURL, resp, probe)
+	}
+	assert.True(t, check("https://example.com/backup"), "should match the not found page reflecting the path")
+	assert.False(t, check("https://example.com/about"))
+	assert.True(t, check("https://example.com/a-much-longer-path-name-reflected-in-the-page"))
+	assert.True(t, check("https://example.com/legacy/admin.php"), "should match the redirects of the missing paths")
+	assert.False(t, check("https://example.com/"), "should not check the root")
+	assert.False(t, detector.Check("https://example.com/missing", Response{StatusCode: 404}, probe))
+
+	lock.Lock()
+	baselineProbes := 0
+	for _, p := range probed {
+		if strings.Contains(p, "venom-") {
+			baselineProbes++
+		}
+	}
+	lock.Unlock()
+	assert.Equal(t, 6, baselineProbes, "should probe each directory and extension once")
+
+	assert.True(t, detector.Matched("https://example.com/backup"))
+	assert.False(t, detector.Matched("https://example.com/about"))
+	matches := detector.Matches()
+	assert.Len(t, matches, 3)
+	assert.Equal(t, "https://example.com/a-much-longer-path-name-reflected-in-the-page", matches[0].URL)
+}
+
+func TestDetectorConcurrent(t *testing.T) {
+	var probed []string
+	var lock sync.Mutex
+	probe := catchAll(&probed, &lock)
+	detector, err := NewDetector(ModeMark)
+	assert.Nil(t, err)
+	assert.False(t, detector.Dropping())
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		wg.Add(1)
+		go func(rawURL string) {
+			defer wg.Done()
+			resp, _ := probe(rawURL)
+			assert.True(t, detector.Check(rawURL, resp, probe))
+		}("https://example.com/files/" + name)
+	}
+	wg.Wait()
+	assert.Equal(t, 8+3, len(probed), "should build the baseline once")
+}
+
+func TestNewDetector(t *testing.T) {
+	_, err := NewDetector("ignore")
+	assert.NotNil(t, err)
+
+	var disabled *Detector
+	assert.False(t, disabled.Check("https://example.com/backup", Response{StatusCode: 200}, nil))
+	assert.False(t, disabled.Dropping())
+	assert.Nil(t, disabled.Matches())
+}
@@ -0,0 +1,267 @@
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"Venom-Crawler/pkg/jsextract"
+)
+
+// mappingURLRegex matches the sourceMappingURL comment of a script,
+// including the deprecated //@ form
+var mappingURLRegex = regexp.MustCompile(`(?m)^[ \t]*//[#@][ \t]*sourceMappingURL=([^\s'"]+)[ \t]*$`)
+
+// xssiPrefix may prefix a source map to prevent it being sourced as a script
+const xssiPrefix = ")]}'"
+
+// Map is a parsed revision 3 source map. Index maps are flattened.
+type Map struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+	Sections       []struct {
+		Map *Map `json:"map"`
+	} `json:"sections"`
+}
+
+// Parse parses a source map
+func Parse(data []byte) (*Map, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte(xssiPrefix))
+	m := &Map{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Version != 3 {
+		return nil, errors.New("unsupported source map version")
+	}
+	for _, section := range m.Sections {
+		if section.Map == nil {
+			continue
+		}
+		for i, source := range section.Map.Sources {
+			m.Sources = append(m.Sources, joinRoot(section.Map.SourceRoot, source))
+			var content *string
+			if i < len(section.Map.SourcesContent) {
+				content = section.Map.SourcesContent[i]
+			}
+			m.SourcesContent = append(m.SourcesContent, content)
+		}
+	}
+	m.Sections = nil
+	return m, nil
+}
+
+// Locate returns the candidate source map URLs of a script, most specific
+// first: the SourceMap header, the sourceMappingURL comment and, when the
+// script declares none, the script URL with .map appended. Inline maps are
+// returned as data: URLs.
+func Locate(scriptURL string, header http.Header, body string) []string {
+	base, err := url.Parse(scriptURL)
+	if err != nil {
+		return nil
+	}
+	var references []string
+	for _, name := range []string{"SourceMap", "X-SourceMap"} {
+		if value := strings.TrimSpace(header.Get(name)); value != "" {
+			references = append(references, value)
+		}
+	}
+	if matches := mappingURLRegex.FindAllStringSubmatch(body, -1); len(matches) > 0 {
+		// the last comment wins, like in browsers
+		references = append(references, matches[len(matches)-1][1])
+	}
+
+	var candidates []string
+	for _, reference := range references {
+		if strings.HasPrefix(reference, "data:") {
+			candidates = append(candidates, reference)
+			continue
+		}
+		if resolved, err := base.Parse(reference); err == nil {
+			candidates = append(candidates, resolved.String())
+		}
+	}
+	if len(candidates) == 0 && strings.HasSuffix(base.Path, ".js") {
+		guess := *base
+		guess.Path += ".map"
+		guess.RawPath = ""
+		guess.RawQuery = ""
+		guess.Fragment = ""
+		candidates = append(candidates, guess.String())
+	}
+	return candidates
+}
+
+// DecodeDataURL returns the content of an inline source map
+func DecodeDataURL(dataURL string) ([]byte, bool) {
+	if !strings.HasPrefix(dataURL, "data:") {
+		return nil, false
+	}
+	comma := strings.Index(dataURL, ",")
+	if comma == -1 {
+		return nil, false
+	}
+	meta, data := dataURL[5:comma], dataURL[comma+1:]
+	if strings.HasSuffix(meta, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, false
+		}
+		return decoded, true
+	}
+	unescaped, err := url.PathUnescape(data)
+	if err != nil {
+		return nil, false
+	}
+	return []byte(unescaped), true
+}
+
+// SourcePaths returns the original source file paths joined with the source root
+func (m *Map) SourcePaths() []string {
+	paths := make([]string, 0, len(m.Sources))
+	for _, source := range m.Sources {
+		paths = append(paths, joinRoot(m.SourceRoot, source))
+	}
+	return paths
+}
+
+// Endpoints runs endpoint extraction over the embedded original sources.
+// Sources the parser rejects, like TypeScript or JSX, are scanned with the
+// lexer, and commented-out code is always scanned. The contents without a
+// source are ignored.
+func (m *Map) Endpoints() []jsextract.Endpoint {
+	var endpoints []jsextract.Endpoint
+	seen := make(map[string]int)
+	add := func(found []jsextract.Endpoint) {
+		for _, endpoint := range found {
+			if i, ok := seen[endpoint.URL]; ok {
+				if endpoints[i].Method == "" {
+					endpoints[i] = endpoint
+				}
+				continue
+			}
+			seen[endpoint.URL] = len(endpoints)
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	for i, content := range m.SourcesContent {
+		if i >= len(m.Sources) {
+			break
+		}
+		if content == nil || *content == "" || isDependency(m.Sources[i]) {
+			continue
+		}
+		if found, err := jsextract.Extract(*content); err == nil {
+			add(found)
+			add(jsextract.ScanComments(*content))
+		} else {
+			add(jsextract.Scan(*content))
+		}
+	}
+	return endpoints
+}
+
+// isDependency returns true for the third party sources bundled from
+// node_modules, their endpoints are noise
+func isDependency(source string) bool {
+	return strings.Contains(source, "node_modules/") || strings.HasPrefix(source, "webpack/bootstrap") ||
+		strings.HasPrefix(source, "(webpack)")
+}
+
+func joinRoot(root, source string) string {
+	if root == "" || strings.Contains(source, "://") || strings.HasPrefix(source, "/") {
+		return source
+	}
+	if strings.Contains(root, "://") {
+		return strings.TrimSuffix(root, "/") + "/" + source
+	}
+	return path.Join(root, source)
+}
+
+// Result is a source map recovered for a script
+type Result struct {
+	Script    string   `json:"script"`
+	Map       string   `json:"map"`
+	Sources   []string `json:"sources"`
+	Endpoints []string `json:"endpoints,omitempty"`
+}
+
+// Recorder dedupes the scripts and maps processed by both engines and
+// collects the recovered source trees
+type Recorder struct {
+	claimed map[string]struct{}
+	results []Result
+	lock    sync.Mutex
+}
+
+// NewRecorder returns an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{claimed: make(map[string]struct{})}
+}
+
+// Claim returns true the first time it is called for a script or map URL
+func (r *Recorder) Claim(url string) bool {
+	if r == nil {
+		return false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.claimed[url]; ok {
+		return false
+	}
+	r.claimed[url] = struct{}{}
+	return true
+}
+
+// Record adds a recovered source map
+func (r *Recorder) Record(script, mapURL string, m *Map, endpoints []jsextract.Endpoint) {
+	if r == nil {
+		return
+	}
+	if strings.HasPrefix(mapURL, "data:") {
+		mapURL = "inline"
+	}
+	result := Result{Script: script, Map: mapURL, Sources: m.SourcePaths()}
+	for _, endpoint := range endpoints {
+		result.Endpoints = append(result.Endpoints, endpoint.URL)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results = append(r.results, result)
+}
+
+// Results returns the recovered source maps sorted by script
+func (r *Recorder) Results() []Result {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	results := append([]Result{}, r.results...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Script < results[j].Script
+	})
+	return results
+}
+
+// WriteJSON writes the recovered source maps to path
+func (r *Recorder) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r.Results(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,73 @@
+package sourcemap
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocate(t *testing.T) {
+	header := http.Header{}
+	header.Set("SourceMap", "/maps/app.js.map")
+	body := "var a=1;\n//# sourceMappingURL=app.min.js.map\n"
+	assert.Equal(t, []string{"https://example.com/maps/app.js.map", "https://example.com/static/app.min.js.map"},
+		Locate("https://example.com/static/app.js", header, body))
+
+	assert.Equal(t, []string{"https://example.com/static/app.js.map"},
+		Locate("https://example.com/static/app.js?v=3", http.Header{}, "var a=1;"), "should guess the map of a script without reference")
+	assert.Empty(t, Locate("https://example.com/api/config", http.Header{}, "var a=1;"))
+
+	inline := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(`{"version":3}`))
+	candidates := Locate("https://example.com/app.js", http.Header{}, "var a=1;\n//# sourceMappingURL="+inline)
+	assert.Equal(t, []string{inline}, candidates)
+	data, ok := DecodeDataURL(candidates[0])
+	assert.True(t, ok)
+	assert.Equal(t, `{"version":3}`, string(data))
+}
+
+func TestParse(t *testing.T) {
+	m, err := Parse([]byte(`)]}'
+{"version":3,"sourceRoot":"webpack:///","sources":["src/api/user.js","node_modules/axios/index.js"],
+"sourcesContent":["export const load = id => fetch('/api/v2/users/' + id, {method: 'DELETE'});\n// fetch('/api/internal/debug')","axios.get('/vendor/endpoint')"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"webpack:///src/api/user.js", "webpack:///node_modules/axios/index.js"}, m.SourcePaths())
+
+	var urls []string
+	for _, endpoint := range m.Endpoints() {
+		urls = append(urls, endpoint.URL)
+	}
+	assert.Contains(t, urls, "/api/v2/users/{id}")
+	assert.Contains(t, urls, "/api/internal/debug", "should extract commented-out code")
+	assert.NotContains(t, urls, "/vendor/endpoint", "should skip bundled dependencies")
+
+	m, err = Parse([]byte(`{"version":3,"sources":["a.js"],"sourcesContent":["fetch('/api/a')","fetch('/api/orphan')"]}`))
+	assert.Nil(t, err)
+	assert.Len(t, m.Endpoints(), 1, "should ignore the contents without a source")
+
+	_, err = Parse([]byte(`{"version":2,"sources":[]}`))
+	assert.NotNil(t, err)
+}
+
+func TestParseSections(t *testing.T) {
+	m, err := Parse([]byte(`{"version":3,"sections":[
+{"offset":{"line":0,"column":0},"map":{"version":3,"sourceRoot":"a","sources":["one.js"],"sourcesContent":["fetch('/api/one')"]}},
+{"offset":{"line":1,"column":0},"map":{"version":3,"sources":["two.ts"]}}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a/one.js", "two.ts"}, m.SourcePaths())
+	assert.Len(t, m.Endpoints(), 1)
+}
+
+func TestRecorder(t *testing.T) {
+	var recorder *Recorder
+	assert.False(t, recorder.Claim("https://example.com/app.js"), "a nil recorder should not retrieve source maps")
+
+	recorder = NewRecorder()
+	assert.True(t, recorder.Claim("https://example.com/app.js"))
+	assert.False(t, recorder.Claim("https://example.com/app.js"))
+	recorder.Record("https://example.com/app.js", "data:application/json,{}", &Map{Sources: []string{"src/app.js"}}, nil)
+	results := recorder.Results()
+	assert.Len(t, results, 1)
+	assert.Equal(t, "inline", results[0].Map)
+}
@@ -0,0 +1,231 @@
+package vhost
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"math/bits"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// DefaultThreshold is the DOM similarity above which two responses are the same page
+	DefaultThreshold = 0.9
+	// sizeTolerance is the relative size difference still considered the same page,
+	// reflected hostnames and tokens make the size of a page vary slightly
+	sizeTolerance = 0.05
+	// shingleSize is the number of consecutive DOM tokens hashed together
+	shingleSize = 3
+)
+
+// Fingerprint summarizes a response so virtual hosts can be compared
+type Fingerprint struct {
+	StatusCode int    `json:"status_code"`
+	Size       int    `json:"size"`
+	Location   string `json:"location,omitempty"`
+	Hash       uint64 `json:"hash"`
+}
+
+// NewFingerprint fingerprints a response by its status, redirect, size and DOM
+func NewFingerprint(statusCode int, location string, body string) Fingerprint {
+	return Fingerprint{
+		StatusCode: statusCode,
+		Size:       len(body),
+		Location:   location,
+		Hash:       DOMHash(body),
+	}
+}
+
+// DOMHash returns a simhash of the tag structure and the text of a page, so
+// pages sharing the same template and content get close hashes
+func DOMHash(body string) uint64 {
+	var tokens []string
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				tokens = append(tokens, body)
+			}
+			break
+		}
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tokens = append(tokens, "<"+token.Data)
+		case html.EndTagToken:
+			tokens = append(tokens, "/"+token.Data)
+		case html.TextToken:
+			tokens = append(tokens, strings.Fields(token.Data)...)
+		}
+	}
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	// repeated blocks like list items are counted once, so they do not
+	// outweigh the rest of the page
+	shingles := make(map[uint64]struct{})
+	for i := 0; i+shingleSize <= len(tokens) || i == 0; i++ {
+		end := i + shingleSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(strings.Join(tokens[i:end], " ")))
+		shingles[hasher.Sum64()] = struct{}{}
+	}
+	var weights [64]int
+	for sum := range shingles {
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash
+}
+
+// Similarity returns the similarity of two DOM hashes between 0 and 1
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// Same returns true if the fingerprints describe the same page: same status
+// and redirect, a close size and a similar DOM
+func (f Fingerprint) Same(other Fingerprint, threshold float64) bool {
+	if f.StatusCode != other.StatusCode || f.Location != other.Location {
+		return false
+	}
+	larger := f.Size
+	if other.Size > larger {
+		larger = other.Size
+	}
+	diff := f.Size - other.Size
+	if diff < 0 {
+		diff = -diff
+	}
+	if larger != 0 && float64(diff)/float64(larger) > sizeTolerance {
+		return false
+	}
+	return Similarity(f.Hash, other.Hash) >= threshold
+}
+
+// ProbeFunc requests the target with host as the Host header
+type ProbeFunc func(host string) (Fingerprint, error)
+
+// VHost is a virtual host serving distinct content
+type VHost struct {
+	Host        string      `json:"host"`
+	Fingerprint Fingerprint `json:"fingerprint"`
+	// Aliases are candidates serving the same content as Host
+	Aliases []string `json:"aliases,omitempty"`
+	// URLs are the URLs found by crawling the virtual host
+	URLs []string `json:"urls,omitempty"`
+}
+
+// Report is the result of a virtual host sweep
+type Report struct {
+	Target    string        `json:"target"`
+	Baselines []Fingerprint `json:"baselines"`
+	VHosts    []*VHost      `json:"vhosts"`
+	// Default are the candidates serving the same content as the baselines
+	Default []string `json:"default,omitempty"`
+	// Failed are the candidates which could not be probed
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Sweep probes every candidate hostname against target. The target itself
+// and a random hostname are probed first as baselines, candidates matching
+// any baseline are served by the default virtual host. Candidates matching
+// an already found virtual host are recorded as its aliases.
+func Sweep(target string, candidates []string, probe ProbeFunc, threshold float64) (*Report, error) {
+	report := &Report{Target: target}
+	baselineHosts := []string{target}
+	if len(candidates) > 0 {
+		baselineHosts = append(baselineHosts, RandomHost(candidates[0]))
+	}
+	for _, host := range baselineHosts {
+		baseline, err := probe(host)
+		if err != nil {
+			return nil, err
+		}
+		report.Baselines = append(report.Baselines, baseline)
+	}
+
+	seen := make(map[string]struct{})
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if _, ok := seen[candidate]; ok || candidate == "" {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		fingerprint, err := probe(candidate)
+		if err != nil {
+			report.Failed = append(report.Failed, candidate)
+			continue
+		}
+		if report.matchBaseline(fingerprint, threshold) {
+			report.Default = append(report.Default, candidate)
+			continue
+		}
+		if vhost := report.match(fingerprint, threshold); vhost != nil {
+			vhost.Aliases = append(vhost.Aliases, candidate)
+			continue
+		}
+		report.VHosts = append(report.VHosts, &VHost{Host: candidate, Fingerprint: fingerprint})
+	}
+	return report, nil
+}
+
+func (r *Report) matchBaseline(fingerprint Fingerprint, threshold float64) bool {
+	for _, baseline := range r.Baselines {
+		if fingerprint.Same(baseline, threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) match(fingerprint Fingerprint, threshold float64) *VHost {
+	for _, vhost := range r.VHosts {
+		if fingerprint.Same(vhost.Fingerprint, threshold) {
+			return vhost
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report to path
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// RandomHost returns a hostname which should not exist, under the parent
+// domain of host so wildcard virtual hosts are part of the baseline
+func RandomHost(host string) string {
+	random := make([]byte, 6)
+	_, _ = rand.Read(random)
+	label := "venom-" + hex.EncodeToString(random)
+	if index := strings.Index(host, "."); index != -1 {
+		return label + host[index:]
+	}
+	return label + ".invalid"
+}
@@ -0,0 +1,55 @@
+package vhost
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func page(title string, items int) string {
+	return "<html><head><title>" + title + "</title></head><body><ul>" +
+		strings.Repeat("<li><a href=\"/item\">item</a></li>", items) + "</ul></body></html>"
+}
+
+func TestFingerprintSame(t *testing.T) {
+	a := NewFingerprint(200, "", page("Welcome to nginx", 3))
+	assert.True(t, a.Same(NewFingerprint(200, "", page("Welcome to nginx", 3)), DefaultThreshold))
+	assert.False(t, a.Same(NewFingerprint(404, "", page("Welcome to nginx", 3)), DefaultThreshold))
+	assert.False(t, a.Same(NewFingerprint(200, "", page("Admin console", 40)), DefaultThreshold))
+}
+
+func TestSweep(t *testing.T) {
+	pages := map[string]string{
+		"10.0.0.8":          page("Welcome to nginx", 1),
+		"www.example.com":   page("Example shop", 20),
+		"example.com":       page("Example shop", 20),
+		"admin.example.com": page("Admin console login", 5) + "<form><input name=user></form>",
+		"dev.example.com":   page("Welcome to nginx", 1),
+	}
+	probe := func(host string) (Fingerprint, error) {
+		if host == "down.example.com" {
+			return Fingerprint{}, errors.New("timeout")
+		}
+		body, ok := pages[host]
+		if !ok {
+			body = pages["10.0.0.8"]
+		}
+		return NewFingerprint(200, "", body), nil
+	}
+	report, err := Sweep("10.0.0.8", []string{"www.example.com", "admin.example.com", "example.com", "dev.example.com", "down.example.com"}, probe, DefaultThreshold)
+	assert.Nil(t, err)
+	assert.Len(t, report.Baselines, 2)
+	assert.Len(t, report.VHosts, 2)
+	assert.Equal(t, "www.example.com", report.VHosts[0].Host)
+	assert.Equal(t, []string{"example.com"}, report.VHosts[0].Aliases)
+	assert.Equal(t, "admin.example.com", report.VHosts[1].Host)
+	assert.Equal(t, []string{"dev.example.com"}, report.Default)
+	assert.Equal(t, []string{"down.example.com"}, report.Failed)
+}
+
+func TestRandomHost(t *testing.T) {
+	assert.True(t, strings.HasSuffix(RandomHost("www.example.com"), ".example.com"))
+	assert.True(t, strings.HasSuffix(RandomHost("intranet"), ".invalid"))
+}
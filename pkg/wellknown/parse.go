@@ -0,0 +1,393 @@
+package wellknown
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxDecompressed bounds the size of a gzipped sitemap once decompressed
+const maxDecompressed = 50 << 20
+
+var (
+	// urlRegex matches the absolute URLs of a text file
+	urlRegex = regexp.MustCompile(`https?://[^\s"'<>()\[\]{},]+`)
+	// hostRegex matches a hostname, optionally with a leading wildcard label
+	hostRegex = regexp.MustCompile(`^(?:\*\.)?(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`)
+)
+
+// collector gathers the distinct URLs and hosts of a file, the URLs are
+// resolved against the file URL
+type collector struct {
+	base  *url.URL
+	urls  []string
+	hosts []string
+	seen  map[string]struct{}
+}
+
+func newCollector(base *url.URL) *collector {
+	return &collector{base: base, seen: make(map[string]struct{})}
+}
+
+// url adds a URL or a path, the host of an absolute URL is added too
+func (c *collector) url(reference string) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return
+	}
+	resolved, err := c.base.Parse(reference)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return
+	}
+	resolved.Fragment = ""
+	if value := resolved.String(); c.add("url " + value) {
+		c.urls = append(c.urls, value)
+	}
+	c.host(resolved.Hostname())
+}
+
+// host adds a hostname, a URL or an e-mail address
+func (c *collector) host(value string) {
+	value = strings.TrimSpace(value)
+	if parsed, err := url.Parse(value); err == nil && parsed.Host != "" {
+		value = parsed.Hostname()
+	}
+	if at := strings.LastIndex(value, "@"); at >= 0 {
+		value = value[at+1:]
+	}
+	value = strings.ToLower(strings.TrimSuffix(value, "."))
+	if hostRegex.MatchString(value) && c.add("host "+value) {
+		c.hosts = append(c.hosts, value)
+	}
+}
+
+func (c *collector) add(key string) bool {
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+	c.seen[key] = struct{}{}
+	return true
+}
+
+// parseRobots returns the Allow and Disallow paths and the hosts of a
+// robots.txt, and the sitemaps it declares. The paths are cut at their
+// first wildcard.
+func parseRobots(c *collector, body []byte) (sitemaps []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		directive, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "allow", "disallow":
+			if wildcard := strings.Index(value, "*"); wildcard >= 0 {
+				value = value[:wildcard]
+			}
+			value = strings.TrimSuffix(value, "$")
+			if strings.HasPrefix(value, "/") && value != "/" {
+				c.url(value)
+			}
+		case "sitemap":
+			if resolved, err := c.base.Parse(value); err == nil && value != "" {
+				sitemaps = append(sitemaps, resolved.String())
+			}
+		case "host":
+			c.host(value)
+		}
+	}
+	return sitemaps
+}
+
+type sitemap struct {
+	XMLName  xml.Name
+	Entries  []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc   string `xml:"loc"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+// parseSitemap returns the URLs of a sitemap and its alternate language
+// links, and the sitemaps listed by a sitemap index. Gzipped sitemaps and
+// text sitemaps, one URL per line, are supported.
+func parseSitemap(c *collector, body []byte) (sitemaps []string, err error) {
+	if body, err = decompress(body); err != nil {
+		return nil, err
+	}
+	if !looksLikeXML(body) {
+		if looksLikeHTML(body) {
+			return nil, errors.New("not a sitemap")
+		}
+		for _, line := range strings.Split(string(body), "\n") {
+			if line = strings.TrimSpace(line); strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+				c.url(line)
+			}
+		}
+		return nil, nil
+	}
+	document := &sitemap{}
+	if err := xml.Unmarshal(body, document); err != nil {
+		return nil, err
+	}
+	if document.XMLName.Local != "urlset" && document.XMLName.Local != "sitemapindex" {
+		return nil, errors.New("not a sitemap")
+	}
+	for _, entry := range document.Entries {
+		c.url(entry.Loc)
+		for _, link := range entry.Links {
+			c.url(link.Href)
+		}
+	}
+	for _, entry := range document.Sitemaps {
+		if resolved, err := c.base.Parse(strings.TrimSpace(entry.Loc)); err == nil && entry.Loc != "" {
+			sitemaps = append(sitemaps, resolved.String())
+		}
+	}
+	return sitemaps, nil
+}
+
+// decompress gunzips a gzipped body, other bodies are returned as is
+func decompress(body []byte) ([]byte, error) {
+	if len(body) < 2 || body[0] != 0x1f || body[1] != 0x8b {
+		return body, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, maxDecompressed))
+}
+
+// securityFields are the fields of security.txt holding URIs
+var securityFields = map[string]struct{}{
+	"contact": {}, "encryption": {}, "acknowledgments": {}, "acknowledgements": {},
+	"policy": {}, "hiring": {}, "canonical": {}, "csaf": {},
+}
+
+// parseSecurityTxt returns the URIs of the fields of a security.txt, the
+// domains of the mailto contacts are hosts
+func parseSecurityTxt(c *collector, body []byte) error {
+	if looksLikeHTML(body) {
+		return errors.New("not a security.txt")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		field, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		if _, ok := securityFields[strings.ToLower(strings.TrimSpace(field))]; !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(strings.ToLower(value), "mailto:"):
+			c.host(value[len("mailto:"):])
+		case strings.Contains(value, "@") && !strings.Contains(value, "/"):
+			c.host(value)
+		default:
+			c.url(value)
+		}
+	}
+	return nil
+}
+
+type crossDomainPolicy struct {
+	XMLName xml.Name
+	Allow   []struct {
+		Domain string `xml:"domain,attr"`
+	} `xml:"allow-access-from"`
+	AllowHeaders []struct {
+		Domain string `xml:"domain,attr"`
+	} `xml:"allow-http-request-headers-from"`
+}
+
+// parseCrossDomain returns the domains a Flash crossdomain.xml trusts
+func parseCrossDomain(c *collector, body []byte) error {
+	policy := &crossDomainPolicy{}
+	if err := xml.Unmarshal(body, policy); err != nil {
+		return err
+	}
+	if policy.XMLName.Local != "cross-domain-policy" {
+		return errors.New("not a cross domain policy")
+	}
+	for _, allow := range policy.Allow {
+		c.host(allow.Domain)
+	}
+	for _, allow := range policy.AllowHeaders {
+		c.host(allow.Domain)
+	}
+	return nil
+}
+
+type accessPolicy struct {
+	XMLName  xml.Name
+	Policies []struct {
+		Domains []struct {
+			URI string `xml:"uri,attr"`
+		} `xml:"allow-from>domain"`
+		Resources []struct {
+			Path string `xml:"path,attr"`
+		} `xml:"grant-to>resource"`
+	} `xml:"cross-domain-access>policy"`
+}
+
+// parseClientAccessPolicy returns the domains a Silverlight
+// clientaccesspolicy.xml trusts and the paths it grants access to
+func parseClientAccessPolicy(c *collector, body []byte) error {
+	policy := &accessPolicy{}
+	if err := xml.Unmarshal(body, policy); err != nil {
+		return err
+	}
+	if policy.XMLName.Local != "access-policy" {
+		return errors.New("not a client access policy")
+	}
+	for _, p := range policy.Policies {
+		for _, domain := range p.Domains {
+			c.host(domain.URI)
+		}
+		for _, resource := range p.Resources {
+			if resource.Path != "/" {
+				c.url(resource.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// manifestKeys are the keys of a web app manifest holding URLs
+var manifestKeys = map[string]struct{}{"start_url": {}, "scope": {}, "src": {}, "url": {}, "action": {}, "id": {}}
+
+// parseManifest returns the start URL, scope, icons, shortcuts, share
+// target and related applications of a web app manifest
+func parseManifest(c *collector, body []byte) error {
+	var manifest map[string]interface{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return err
+	}
+	walkJSON(manifest, func(key string, value string) {
+		if _, ok := manifestKeys[key]; ok && !strings.Contains(value, "%s") {
+			c.url(value)
+		}
+	})
+	return nil
+}
+
+// parseHumansTxt returns the URLs of a humans.txt
+func parseHumansTxt(c *collector, body []byte) error {
+	if looksLikeHTML(body) {
+		return errors.New("not a humans.txt")
+	}
+	for _, match := range urlRegex.FindAll(body, -1) {
+		c.url(strings.TrimRight(string(match), ".;"))
+	}
+	return nil
+}
+
+// adsVariables are the variables of ads.txt naming domains of the publisher
+var adsVariables = map[string]struct{}{"subdomain": {}, "ownerdomain": {}, "managerdomain": {}, "contact": {}}
+
+// parseAdsTxt returns the publisher domains and contacts of an ads.txt. The
+// records name the domains of the advertising systems, they are not hosts
+// of the site and are skipped.
+func parseAdsTxt(c *collector, body []byte) error {
+	if looksLikeHTML(body) {
+		return errors.New("not an ads.txt")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		variable, value, ok := strings.Cut(line, "=")
+		if !ok || strings.Contains(variable, ",") {
+			continue
+		}
+		if _, ok := adsVariables[strings.ToLower(strings.TrimSpace(variable))]; !ok {
+			continue
+		}
+		if value = strings.TrimSpace(value); strings.Contains(value, "://") {
+			c.url(value)
+		} else {
+			c.host(value)
+		}
+	}
+	return nil
+}
+
+// parseOpenIDConfiguration returns the issuer and the endpoints of an
+// OpenID Connect discovery document
+func parseOpenIDConfiguration(c *collector, body []byte) error {
+	var configuration map[string]interface{}
+	if err := json.Unmarshal(body, &configuration); err != nil {
+		return err
+	}
+	if _, ok := configuration["issuer"]; !ok {
+		return errors.New("not an OpenID configuration")
+	}
+	walkJSON(configuration, func(key string, value string) {
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+			c.url(value)
+		}
+	})
+	return nil
+}
+
+// walkJSON calls visit with every string of a JSON value and the key of
+// the object member holding it
+func walkJSON(value interface{}, visit func(key string, value string)) {
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case string:
+			visit(key, v)
+		case []interface{}:
+			for _, item := range v {
+				walk(key, item)
+			}
+		case map[string]interface{}:
+			for k, item := range v {
+				walk(k, item)
+			}
+		}
+	}
+	walk("", value)
+}
+
+// looksLikeHTML returns true if a body is an HTML page, sites routing
+// every path to their index page serve it for the missing files
+func looksLikeHTML(body []byte) bool {
+	head := bytes.ToLower(bytes.TrimSpace(body[:min(len(body), 512)]))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html")) ||
+		bytes.Contains(head, []byte("<head")) || bytes.Contains(head, []byte("<body"))
+}
+
+func looksLikeXML(body []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Recorder collects the well-known files found by both engines and caches
+// them per site so that each is discovered once
+type Recorder struct {
+	sites   map[string]*siteFiles
+	results map[string]Result
+	lock    sync.Mutex
+}
+
+// siteFiles are the well-known files found on a site
+type siteFiles struct {
+	once    sync.Once
+	results []Result
+}
+
+// NewRecorder returns an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{sites: make(map[string]*siteFiles), results: make(map[string]Result)}
+}
+
+// Discover requests the well-known files of the site of siteURL the first
+// time it is called for the site and records those found. The later calls
+// for the site return the files found, waiting for the first to finish.
+func (r *Recorder) Discover(siteURL string, fetch FetchFunc) []Result {
+	if r == nil {
+		return Discover(siteURL, fetch)
+	}
+	s := r.siteFiles(siteURL)
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		s.results = Discover(siteURL, fetch)
+		r.Add(s.results...)
+	})
+	return s.results
+}
+
+// Robots requests the robots.txt and the sitemaps of the site of siteURL
+// and records those found, it does not claim the site
+func (r *Recorder) Robots(siteURL string, fetch FetchFunc) []Result {
+	results := Robots(siteURL, fetch)
+	r.Add(results...)
+	return results
+}
+
+// siteFiles returns the files of the site of siteURL
+func (r *Recorder) siteFiles(siteURL string) *siteFiles {
+	parsed, err := url.Parse(siteURL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	origin := parsed.Scheme + "://" + parsed.Host
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s, ok := r.sites[origin]
+	if !ok {
+		s = &siteFiles{}
+		r.sites[origin] = s
+	}
+	return s
+}
+
+// Add records the files found
+func (r *Recorder) Add(results ...Result) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, result := range results {
+		r.results[result.URL] = result
+	}
+}
+
+// Results returns the recorded files sorted by URL
+func (r *Recorder) Results() []Result {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	results := make([]Result, 0, len(r.results))
+	for _, result := range r.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].URL < results[j].URL
+	})
+	return results
+}
+
+// Hosts returns the distinct hosts named by the recorded files, sorted
+func (r *Recorder) Hosts() []string {
+	var hosts []string
+	seen := make(map[string]struct{})
+	for _, result := range r.Results() {
+		for _, host := range result.Hosts {
+			if _, ok := seen[host]; !ok {
+				seen[host] = struct{}{}
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// WriteJSON writes the recorded files and the hosts they name to path
+func (r *Recorder) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(struct {
+		Files []Result `json:"files"`
+		Hosts []string `json:"hosts"`
+	}{r.Results(), r.Hosts()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
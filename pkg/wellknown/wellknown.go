@@ -0,0 +1,157 @@
+package wellknown
+
+import (
+	"net/url"
+)
+
+// Sources of the well-known files
+const (
+	SourceRobots             = "robots.txt"
+	SourceSitemap            = "sitemap"
+	SourceSecurityTxt        = "security.txt"
+	SourceCrossDomain        = "crossdomain.xml"
+	SourceClientAccessPolicy = "clientaccesspolicy.xml"
+	SourceManifest           = "manifest"
+	SourceHumansTxt          = "humans.txt"
+	SourceAdsTxt             = "ads.txt"
+	SourceOpenIDConfig       = "openid-configuration"
+)
+
+// maxSitemaps bounds the sitemaps fetched per site, sitemap indexes may
+// list thousands of them
+const maxSitemaps = 50
+
+// Location is the path of a well-known file, the paths of a source are
+// tried in order until one is found
+type Location struct {
+	Source string
+	Paths  []string
+}
+
+// Locations are the well-known files requested, the sitemaps declared in
+// robots.txt and listed by sitemap indexes are requested too
+var Locations = []Location{
+	{SourceRobots, []string{"/robots.txt"}},
+	{SourceSitemap, []string{"/sitemap.xml", "/sitemap_index.xml", "/sitemap.xml.gz"}},
+	{SourceSecurityTxt, []string{"/.well-known/security.txt", "/security.txt"}},
+	{SourceCrossDomain, []string{"/crossdomain.xml"}},
+	{SourceClientAccessPolicy, []string{"/clientaccesspolicy.xml"}},
+	{SourceManifest, []string{"/manifest.json", "/site.webmanifest", "/manifest.webmanifest"}},
+	{SourceHumansTxt, []string{"/humans.txt"}},
+	{SourceAdsTxt, []string{"/ads.txt"}},
+	{SourceOpenIDConfig, []string{"/.well-known/openid-configuration"}},
+}
+
+// parsers parse the files of the sources other than robots.txt and sitemaps
+var parsers = map[string]func(c *collector, body []byte) error{
+	SourceSecurityTxt:        parseSecurityTxt,
+	SourceCrossDomain:        parseCrossDomain,
+	SourceClientAccessPolicy: parseClientAccessPolicy,
+	SourceManifest:           parseManifest,
+	SourceHumansTxt:          parseHumansTxt,
+	SourceAdsTxt:             parseAdsTxt,
+	SourceOpenIDConfig:       parseOpenIDConfiguration,
+}
+
+// Result is a well-known file found with the URLs and hosts it names
+type Result struct {
+	Source string   `json:"source"`
+	URL    string   `json:"url"`
+	URLs   []string `json:"urls,omitempty"`
+	Hosts  []string `json:"hosts,omitempty"`
+}
+
+// FetchFunc requests a URL and returns the response status code and body
+type FetchFunc func(url string) (int, []byte, error)
+
+// Discover requests the well-known files of the site of siteURL and
+// returns those found
+func Discover(siteURL string, fetch FetchFunc) []Result {
+	return discover(siteURL, fetch, Locations)
+}
+
+// Robots requests the robots.txt of the site of siteURL and the sitemaps
+// it declares
+func Robots(siteURL string, fetch FetchFunc) []Result {
+	return discover(siteURL, fetch, Locations[:1])
+}
+
+func discover(siteURL string, fetch FetchFunc, locations []Location) []Result {
+	site, err := url.Parse(siteURL)
+	if err != nil || site.Host == "" {
+		return nil
+	}
+	origin := site.Scheme + "://" + site.Host
+
+	var results []Result
+	var sitemaps []string
+	for _, location := range locations {
+		for _, path := range location.Paths {
+			fileURL := origin + path
+			body, ok := get(fetch, fileURL)
+			if !ok {
+				continue
+			}
+			if location.Source == SourceSitemap {
+				// the sitemaps are parsed with those declared in robots.txt
+				sitemaps = append(sitemaps, fileURL)
+				break
+			}
+			base, _ := url.Parse(fileURL)
+			c := newCollector(base)
+			if location.Source == SourceRobots {
+				if looksLikeHTML(body) {
+					continue
+				}
+				sitemaps = append(sitemaps, parseRobots(c, body)...)
+			} else if err := parsers[location.Source](c, body); err != nil {
+				continue
+			}
+			results = append(results, Result{Source: location.Source, URL: fileURL, URLs: c.urls, Hosts: c.hosts})
+			break
+		}
+	}
+	return append(results, crawlSitemaps(sitemaps, fetch)...)
+}
+
+// crawlSitemaps requests the sitemaps and the sitemaps listed by the
+// sitemap indexes, the sitemaps already fetched are skipped
+func crawlSitemaps(queue []string, fetch FetchFunc) []Result {
+	var results []Result
+	seen := make(map[string]struct{})
+	for fetched := 0; len(queue) > 0 && fetched < maxSitemaps; {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[sitemapURL]; ok {
+			continue
+		}
+		seen[sitemapURL] = struct{}{}
+		fetched++
+
+		base, err := url.Parse(sitemapURL)
+		if err != nil {
+			continue
+		}
+		body, ok := get(fetch, sitemapURL)
+		if !ok {
+			continue
+		}
+		c := newCollector(base)
+		nested, err := parseSitemap(c, body)
+		if err != nil {
+			continue
+		}
+		queue = append(queue, nested...)
+		results = append(results, Result{Source: SourceSitemap, URL: sitemapURL, URLs: c.urls, Hosts: c.hosts})
+	}
+	return results
+}
+
+// get requests a URL and returns its body if the response is successful
+func get(fetch FetchFunc, fileURL string) ([]byte, bool) {
+	status, body, err := fetch(fileURL)
+	if err != nil || status < 200 || status >= 300 || len(body) == 0 {
+		return nil, false
+	}
+	return body, true
+}
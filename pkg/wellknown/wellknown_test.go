@@ -0,0 +1,154 @@
+package wellknown
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// site serves the files of a fake site
+type site map[string]string
+
+func (s site) fetch(fetched *[]string) FetchFunc {
+	return func(url string) (int, []byte, error) {
+		*fetched = append(*fetched, url)
+		if body, ok := s[url]; ok {
+			return 200, []byte(body), nil
+		}
+		return 404, nil, nil
+	}
+}
+
+func gzipped(t *testing.T, body string) string {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(body))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return buffer.String()
+}
+
+func TestDiscover(t *testing.T) {
+	files := site{
+		"https://example.com/robots.txt": `User-agent: *
+Disallow: /admin/ # back office
+Disallow: /search?q=*
+Allow: /api/*/public$
+Disallow: /
+Host: www.example.com
+Sitemap: https://example.com/sitemaps/index.xml`,
+		"https://example.com/sitemaps/index.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemaps/pages.xml.gz</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemaps/index.xml</loc></sitemap>
+</sitemapindex>`,
+		"https://example.com/sitemaps/pages.xml.gz": gzipped(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+  <url>
+    <loc> https://example.com/about </loc>
+    <xhtml:link rel="alternate" hreflang="de" href="https://example.de/about"/>
+  </url>
+</urlset>`),
+		"https://example.com/sitemap.xml": "https://example.com/contact\nhttps://example.com/jobs\n",
+		"https://example.com/security.txt": `Contact: mailto:security@example.com
+Contact: https://hackerone.com/example
+Policy: https://example.com/security-policy
+Expires: 2030-01-01T00:00:00.000Z`,
+		"https://example.com/crossdomain.xml": `<?xml version="1.0"?>
+<cross-domain-policy>
+  <allow-access-from domain="*.example-cdn.com"/>
+  <allow-access-from domain="*"/>
+  <allow-http-request-headers-from domain="partner.example.org" headers="*"/>
+</cross-domain-policy>`,
+		"https://example.com/clientaccesspolicy.xml": `<?xml version="1.0" encoding="utf-8"?>
+<access-policy>
+  <cross-domain-access>
+    <policy>
+      <allow-from><domain uri="https://silverlight.example.net"/></allow-from>
+      <grant-to><resource path="/services/" include-subpaths="true"/></grant-to>
+    </policy>
+  </cross-domain-access>
+</access-policy>`,
+		"https://example.com/site.webmanifest": `{"name":"Example","start_url":"/app/?source=pwa","scope":"/app/",
+  "icons":[{"src":"/icons/192.png","sizes":"192x192"}],
+  "shortcuts":[{"name":"Orders","url":"/app/orders"}],
+  "protocol_handlers":[{"protocol":"web+example","url":"/handle?uri=%s"}]}`,
+		"https://example.com/humans.txt": "/* TEAM */\nDeveloper: Jane\nSite: https://jane.example.io.\n",
+		"https://example.com/ads.txt": `google.com, pub-0000000000000000, DIRECT, f08c47fec0942fa0
+contact=https://example.com/advertising
+subdomain=shop.example.com
+ownerdomain=example.com`,
+		"https://example.com/.well-known/openid-configuration": `{"issuer":"https://login.example.com",
+  "authorization_endpoint":"https://login.example.com/oauth2/authorize",
+  "jwks_uri":"https://login.example.com/.well-known/jwks.json",
+  "response_types_supported":["code"]}`,
+	}
+	var fetched []string
+	results := Discover("https://example.com/index.html?x=1", files.fetch(&fetched))
+
+	bySource := map[string][]Result{}
+	for _, result := range results {
+		bySource[result.Source] = append(bySource[result.Source], result)
+	}
+	assert.Len(t, bySource, 9)
+
+	robots := bySource[SourceRobots][0]
+	assert.Equal(t, []string{"https://example.com/admin/", "https://example.com/search?q=", "https://example.com/api/"}, robots.URLs)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, robots.Hosts)
+
+	sitemaps := bySource[SourceSitemap]
+	assert.Len(t, sitemaps, 3, "should follow the sitemap of robots.txt and the sitemap index once")
+	assert.Equal(t, "https://example.com/sitemaps/index.xml", sitemaps[0].URL)
+	assert.Empty(t, sitemaps[0].URLs)
+	assert.Equal(t, "https://example.com/sitemap.xml", sitemaps[1].URL)
+	assert.Equal(t, []string{"https://example.com/contact", "https://example.com/jobs"}, sitemaps[1].URLs, "should read text sitemaps")
+	assert.Equal(t, []string{"https://example.com/about", "https://example.de/about"}, sitemaps[2].URLs, "should read gzipped sitemaps")
+	assert.Equal(t, []string{"example.com", "example.de"}, sitemaps[2].Hosts)
+
+	security := bySource[SourceSecurityTxt][0]
+	assert.Equal(t, "https://example.com/security.txt", security.URL, "should fall back to /security.txt")
+	assert.Equal(t, []string{"https://hackerone.com/example", "https://example.com/security-policy"}, security.URLs)
+	assert.Equal(t, []string{"example.com", "hackerone.com"}, security.Hosts)
+
+	assert.Equal(t, []string{"*.example-cdn.com", "partner.example.org"}, bySource[SourceCrossDomain][0].Hosts)
+	assert.Equal(t, []string{"https://example.com/services/"}, bySource[SourceClientAccessPolicy][0].URLs)
+	assert.Equal(t, []string{"silverlight.example.net", "example.com"}, bySource[SourceClientAccessPolicy][0].Hosts)
+
+	manifest := bySource[SourceManifest][0]
+	assert.ElementsMatch(t, []string{"https://example.com/app/?source=pwa", "https://example.com/app/",
+		"https://example.com/icons/192.png", "https://example.com/app/orders"}, manifest.URLs)
+
+	assert.Equal(t, []string{"https://jane.example.io"}, bySource[SourceHumansTxt][0].URLs)
+	assert.Equal(t, []string{"example.com", "shop.example.com"}, bySource[SourceAdsTxt][0].Hosts, "should skip the advertising systems")
+	assert.Equal(t, []string{"login.example.com"}, bySource[SourceOpenIDConfig][0].Hosts)
+	assert.Len(t, bySource[SourceOpenIDConfig][0].URLs, 3)
+}
+
+func TestDiscoverCatchAll(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title>App</title></head><body><div id="app"></div></body></html>`
+	results := Discover("https://example.com/", func(string) (int, []byte, error) {
+		return 200, []byte(page), nil
+	})
+	assert.Empty(t, results, "should reject the index page served for every path")
+}
+
+func TestRecorder(t *testing.T) {
+	files := site{"https://example.com/robots.txt": "Disallow: /private\nSitemap: /sitemap.xml"}
+	var fetched []string
+	recorder := NewRecorder()
+	assert.Len(t, recorder.Robots("https://example.com/", files.fetch(&fetched)), 1)
+	assert.Equal(t, []string{"https://example.com/robots.txt", "https://example.com/sitemap.xml"}, fetched)
+
+	assert.Len(t, recorder.Discover("https://example.com/", files.fetch(&fetched)), 1)
+	fetched = nil
+	assert.Len(t, recorder.Discover("https://example.com/other", files.fetch(&fetched)), 1, "should return the files found to the later callers")
+	assert.Empty(t, fetched, "should discover each site once")
+	assert.Len(t, recorder.Results(), 1)
+	assert.Equal(t, []string{"example.com"}, recorder.Hosts())
+
+	var disabled *Recorder
+	assert.Len(t, disabled.Discover("https://example.com/", files.fetch(&fetched)), 1)
+	assert.Nil(t, disabled.Results())
+}
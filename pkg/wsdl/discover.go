@@ -0,0 +1,296 @@
+package wsdl
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Locations are the common paths of the WADL descriptions and of the
+// service listings of the SOAP stacks
+var Locations = []string{
+	"/application.wadl", "/api/application.wadl", "/rest/application.wadl", "/?_wadl",
+	"/services", "/services/", "/ws", "/soap?wsdl", "/service?wsdl",
+}
+
+// serviceExtensions are the extensions of the SOAP endpoints serving their
+// description at ?wsdl
+var serviceExtensions = map[string]struct{}{".asmx": {}, ".svc": {}, ".jws": {}, ".cfc": {}, ".wsdl": {}}
+
+var (
+	// descriptionLinkRegex matches the links to descriptions of the service
+	// listings and the ASMX and WCF help pages
+	descriptionLinkRegex = regexp.MustCompile(`(?i)href\s*=\s*["']([^"'\s<>]*(?:[?&](?:wsdl|singlewsdl|_wadl)\b|\.wsdl|\.wadl)[^"'\s<>]*)["']`)
+	// servicePathRegex matches the paths of the SOAP endpoints of the Java stacks
+	servicePathRegex = regexp.MustCompile(`(?i)/(?:services|ws|soap|webservices?)/[\w.-]+/?$`)
+)
+
+// IsServiceURL returns true if the URL looks like a SOAP endpoint
+func IsServiceURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if _, ok := serviceExtensions[strings.ToLower(path.Ext(parsed.Path))]; ok {
+		return true
+	}
+	return servicePathRegex.MatchString(parsed.Path)
+}
+
+// DescriptionURLs returns the description URLs of a page: the ?wsdl suffix
+// of a SOAP endpoint and the descriptions linked by a service listing
+func DescriptionURLs(pageURL string, body []byte) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	seen := map[string]struct{}{pageURL: {}}
+	add := func(value string) {
+		if _, ok := seen[value]; !ok {
+			seen[value] = struct{}{}
+			urls = append(urls, value)
+		}
+	}
+	if IsServiceURL(pageURL) {
+		service := *base
+		service.RawQuery, service.Fragment = "", ""
+		if strings.EqualFold(path.Ext(service.Path), ".wsdl") {
+			add(service.String())
+		} else {
+			add(service.String() + "?wsdl")
+		}
+	}
+	for _, match := range descriptionLinkRegex.FindAllSubmatch(body, -1) {
+		if resolved, err := base.Parse(string(match[1])); err == nil && (resolved.Scheme == "http" || resolved.Scheme == "https") {
+			resolved.Fragment = ""
+			add(resolved.String())
+		}
+	}
+	return urls
+}
+
+// FetchFunc requests a URL and returns the response status code and body
+type FetchFunc func(url string) (int, []byte, error)
+
+// Entry is a description recorded in a catalog with its operations
+type Entry struct {
+	URL        string      `json:"url"`
+	Kind       string      `json:"kind"`
+	Operations []Operation `json:"operations"`
+}
+
+// Catalog collects the service descriptions found by both engines
+type Catalog struct {
+	replay  bool
+	claimed map[string]struct{}
+	entries map[string]*Entry
+	lock    sync.Mutex
+}
+
+// NewCatalog returns an empty catalog, the SOAP operations and the WADL
+// methods changing the state of the server are returned to be crawled
+// when replay is true
+func NewCatalog(replay bool) *Catalog {
+	return &Catalog{replay: replay, claimed: make(map[string]struct{}), entries: make(map[string]*Entry)}
+}
+
+// Claim returns true the first time it is called for a description URL
+func (c *Catalog) Claim(url string) bool {
+	if c == nil {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.claimed[url]; ok {
+		return false
+	}
+	c.claimed[url] = struct{}{}
+	return true
+}
+
+// Add records a description with all its operations and returns those to
+// crawl: the GET, HEAD and OPTIONS methods, and the others when the
+// catalog replays them
+func (c *Catalog) Add(document *Document) []Operation {
+	operations := document.Operations()
+	if c == nil {
+		return Safe(operations)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[document.URL]; !ok {
+		c.entries[document.URL] = &Entry{URL: document.URL, Kind: document.Kind, Operations: operations}
+	}
+	if c.replay {
+		return operations
+	}
+	return Safe(operations)
+}
+
+// Safe returns the operations which do not change the state of the
+// server, a SOAP operation is always sent with POST
+func Safe(operations []Operation) []Operation {
+	var safe []Operation
+	for _, operation := range operations {
+		switch operation.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			safe = append(safe, operation)
+		}
+	}
+	return safe
+}
+
+// Discover records the description served at pageURL, or the descriptions
+// of the SOAP endpoint or service listing at pageURL, and returns the
+// operations of the descriptions not seen before
+func (c *Catalog) Discover(pageURL string, body []byte, fetch FetchFunc) []Operation {
+	if c == nil {
+		return nil
+	}
+	if isDescription(body) && c.Claim(pageURL) {
+		if document, err := Load(pageURL, body, fetch); err == nil {
+			return c.Add(document)
+		}
+	}
+	var operations []Operation
+	for _, descriptionURL := range DescriptionURLs(pageURL, body) {
+		if !c.Claim(descriptionURL) {
+			continue
+		}
+		status, data, err := fetch(descriptionURL)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		if document, err := Load(descriptionURL, data, fetch); err == nil {
+			operations = append(operations, c.Add(document)...)
+		}
+	}
+	return operations
+}
+
+// Probe requests the common locations of the site of siteURL and returns
+// the operations of the descriptions found
+func (c *Catalog) Probe(siteURL string, fetch FetchFunc) []Operation {
+	site, err := url.Parse(siteURL)
+	if c == nil || err != nil || site.Host == "" {
+		return nil
+	}
+	var operations []Operation
+	for _, location := range Locations {
+		target := site.Scheme + "://" + site.Host + location
+		if !c.Claim(target) {
+			continue
+		}
+		status, body, err := fetch(target)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		if document, err := Load(target, body, fetch); err == nil {
+			operations = append(operations, c.Add(document)...)
+			continue
+		}
+		// a service listing links the descriptions of its services
+		for _, descriptionURL := range DescriptionURLs(target, body) {
+			if !c.Claim(descriptionURL) {
+				continue
+			}
+			status, data, err := fetch(descriptionURL)
+			if err != nil || status < 200 || status >= 300 {
+				continue
+			}
+			if document, err := Load(descriptionURL, data, fetch); err == nil {
+				operations = append(operations, c.Add(document)...)
+			}
+		}
+	}
+	return operations
+}
+
+// Entries returns the recorded descriptions sorted by URL
+func (c *Catalog) Entries() []Entry {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+	return entries
+}
+
+// WriteJSON writes the recorded descriptions and their operations to path
+func (c *Catalog) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(c.Entries(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// isDescription returns true if the body looks like a WSDL or WADL document
+func isDescription(body []byte) bool {
+	head := body
+	if len(head) > 2048 {
+		head = head[:2048]
+	}
+	head = bytes.TrimSpace(head)
+	return bytes.HasPrefix(head, []byte("<")) &&
+		(bytes.Contains(head, []byte("definitions")) || bytes.Contains(head, []byte("application")))
+}
+
+// RequestKey identifies the SOAP operation sent by a request from its
+// action and the first element of the envelope body, it is empty if the
+// request is not a SOAP request
+func RequestKey(contentType string, soapAction string, body string) string {
+	action := strings.Trim(soapAction, `"`)
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil {
+		if action == "" {
+			action = params["action"]
+		}
+		if action == "" && !strings.Contains(mediaType, "xml") {
+			return ""
+		}
+	} else if action == "" {
+		return ""
+	}
+	operation := bodyElement(body)
+	if action == "" && operation == "" {
+		return ""
+	}
+	return action + " " + operation
+}
+
+// bodyElement returns the name of the first element of a SOAP envelope body
+func bodyElement(body string) string {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if inBody {
+			return start.Name.Space + " " + start.Name.Local
+		}
+		inBody = start.Name.Local == "Body" && (start.Name.Space == soap11Envelope || start.Name.Space == soap12Envelope)
+	}
+}
@@ -0,0 +1,300 @@
+package wsdl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// maxDepth bounds the nesting of generated example messages
+const maxDepth = 8
+
+// schema is an XML schema embedded in or imported by a description
+type schema struct {
+	TargetNamespace    string         `xml:"targetNamespace,attr"`
+	ElementFormDefault string         `xml:"elementFormDefault,attr"`
+	Elements           []*element     `xml:"element"`
+	ComplexTypes       []*complexType `xml:"complexType"`
+	SimpleTypes        []*simpleType  `xml:"simpleType"`
+	Imports            []struct {
+		SchemaLocation string `xml:"schemaLocation,attr"`
+	} `xml:"import"`
+	Includes []struct {
+		SchemaLocation string `xml:"schemaLocation,attr"`
+	} `xml:"include"`
+}
+
+type element struct {
+	Name        string       `xml:"name,attr"`
+	Type        string       `xml:"type,attr"`
+	Ref         string       `xml:"ref,attr"`
+	Form        string       `xml:"form,attr"`
+	ComplexType *complexType `xml:"complexType"`
+	SimpleType  *simpleType  `xml:"simpleType"`
+}
+
+type complexType struct {
+	Name           string `xml:"name,attr"`
+	Sequence       *group `xml:"sequence"`
+	All            *group `xml:"all"`
+	Choice         *group `xml:"choice"`
+	ComplexContent *struct {
+		Extension   *derivation `xml:"extension"`
+		Restriction *derivation `xml:"restriction"`
+	} `xml:"complexContent"`
+}
+
+// group is a sequence, all or choice of elements
+type group struct {
+	Elements  []*element `xml:"element"`
+	Sequences []*group   `xml:"sequence"`
+	Choices   []*group   `xml:"choice"`
+}
+
+type derivation struct {
+	Base     string `xml:"base,attr"`
+	Sequence *group `xml:"sequence"`
+	All      *group `xml:"all"`
+	Choice   *group `xml:"choice"`
+}
+
+type simpleType struct {
+	Name        string `xml:"name,attr"`
+	Restriction *struct {
+		Base         string `xml:"base,attr"`
+		Enumerations []struct {
+			Value string `xml:"value,attr"`
+		} `xml:"enumeration"`
+	} `xml:"restriction"`
+}
+
+// builtinExamples are the example values of the XML schema built-in types
+// and of the serialization types of WCF
+var builtinExamples = map[string]string{
+	"string": "test", "normalizedString": "test", "token": "test", "anyType": "test", "anySimpleType": "test",
+	"int": "1", "integer": "1", "long": "1", "short": "1", "byte": "1", "positiveInteger": "1",
+	"nonNegativeInteger": "1", "unsignedInt": "1", "unsignedLong": "1", "unsignedShort": "1", "unsignedByte": "1",
+	"negativeInteger": "-1", "nonPositiveInteger": "-1",
+	"decimal": "1.5", "float": "1.5", "double": "1.5",
+	"boolean":      "true",
+	"dateTime":     "2024-01-01T00:00:00Z",
+	"date":         "2024-01-01",
+	"time":         "00:00:00",
+	"duration":     "P1D",
+	"base64Binary": "dGVzdA==",
+	"hexBinary":    "74657374",
+	"anyURI":       "https://example.com",
+	"QName":        "test",
+	"guid":         "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"char":         "116",
+}
+
+// localName strips the namespace prefix of a qualified name
+func localName(qname string) string {
+	return qname[strings.LastIndex(qname, ":")+1:]
+}
+
+// schemaSet indexes the global components of the schemas of a description
+// by local name, prefixes are not resolved
+type schemaSet struct {
+	elements     map[string]*element
+	complexTypes map[string]*complexType
+	simpleTypes  map[string]*simpleType
+	owners       map[interface{}]*schema
+}
+
+func newSchemaSet(schemas []*schema) *schemaSet {
+	set := &schemaSet{
+		elements:     make(map[string]*element),
+		complexTypes: make(map[string]*complexType),
+		simpleTypes:  make(map[string]*simpleType),
+		owners:       make(map[interface{}]*schema),
+	}
+	for _, s := range schemas {
+		for _, e := range s.Elements {
+			set.elements[e.Name] = e
+			set.owners[e] = s
+		}
+		for _, t := range s.ComplexTypes {
+			set.complexTypes[t.Name] = t
+			set.owners[t] = s
+		}
+		for _, t := range s.SimpleTypes {
+			set.simpleTypes[t.Name] = t
+			set.owners[t] = s
+		}
+	}
+	return set
+}
+
+// generator writes example XML for the elements and types of a schema set
+type generator struct {
+	set      *schemaSet
+	buffer   bytes.Buffer
+	visiting map[string]struct{}
+}
+
+func newGenerator(set *schemaSet) *generator {
+	return &generator{set: set, visiting: make(map[string]struct{})}
+}
+
+// globalElement writes a global element in its target namespace, set as
+// the default namespace, and returns false if it is not defined
+func (g *generator) globalElement(name string) bool {
+	e, ok := g.set.elements[localName(name)]
+	if !ok {
+		return false
+	}
+	s := g.set.owners[e]
+	g.open(e.Name, s.TargetNamespace, true)
+	g.content(e, s, s.TargetNamespace, 0)
+	g.close(e.Name)
+	return true
+}
+
+// typed writes an element of a named type, the element is unqualified
+func (g *generator) typed(name string, typeName string, defaultNamespace string) {
+	g.open(name, "", defaultNamespace != "")
+	g.typeContent(typeName, "", 0)
+	g.close(name)
+}
+
+// localElement writes an element declared in a complex type, defaultNamespace
+// is the default namespace in scope
+func (g *generator) localElement(e *element, s *schema, defaultNamespace string, depth int) {
+	if e.Ref != "" {
+		ref, ok := g.set.elements[localName(e.Ref)]
+		if !ok {
+			return
+		}
+		owner := g.set.owners[ref]
+		g.open(ref.Name, owner.TargetNamespace, owner.TargetNamespace != defaultNamespace)
+		g.content(ref, owner, owner.TargetNamespace, depth+1)
+		g.close(ref.Name)
+		return
+	}
+	namespace := ""
+	if e.Form == "qualified" || (e.Form == "" && s.ElementFormDefault == "qualified") {
+		namespace = s.TargetNamespace
+	}
+	g.open(e.Name, namespace, namespace != defaultNamespace)
+	g.content(e, s, namespace, depth+1)
+	g.close(e.Name)
+}
+
+// content writes the content of an element
+func (g *generator) content(e *element, s *schema, defaultNamespace string, depth int) {
+	switch {
+	case e.ComplexType != nil:
+		g.complexContent(e.ComplexType, s, defaultNamespace, depth)
+	case e.SimpleType != nil:
+		g.text(g.simpleExample(e.SimpleType))
+	case e.Type != "":
+		g.typeContent(e.Type, defaultNamespace, depth)
+	default:
+		g.text("test")
+	}
+}
+
+// typeContent writes the content of a named type
+func (g *generator) typeContent(typeName string, defaultNamespace string, depth int) {
+	name := localName(typeName)
+	if t, ok := g.set.complexTypes[name]; ok {
+		if _, ok := g.visiting[name]; ok || depth > maxDepth {
+			return
+		}
+		g.visiting[name] = struct{}{}
+		defer delete(g.visiting, name)
+		g.complexContent(t, g.set.owners[t], defaultNamespace, depth)
+		return
+	}
+	if t, ok := g.set.simpleTypes[name]; ok {
+		g.text(g.simpleExample(t))
+		return
+	}
+	if example, ok := builtinExamples[name]; ok {
+		g.text(example)
+		return
+	}
+	g.text("test")
+}
+
+// complexContent writes the elements of a complex type, the first element
+// of a choice only
+func (g *generator) complexContent(t *complexType, s *schema, defaultNamespace string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	if content := t.ComplexContent; content != nil {
+		derived := content.Extension
+		if derived == nil {
+			derived = content.Restriction
+		}
+		if derived != nil {
+			if content.Extension != nil && derived.Base != "" {
+				g.typeContent(derived.Base, defaultNamespace, depth+1)
+			}
+			g.group(derived.Sequence, s, defaultNamespace, depth, false)
+			g.group(derived.All, s, defaultNamespace, depth, false)
+			g.group(derived.Choice, s, defaultNamespace, depth, true)
+		}
+	}
+	g.group(t.Sequence, s, defaultNamespace, depth, false)
+	g.group(t.All, s, defaultNamespace, depth, false)
+	g.group(t.Choice, s, defaultNamespace, depth, true)
+}
+
+func (g *generator) group(elements *group, s *schema, defaultNamespace string, depth int, choice bool) {
+	if elements == nil {
+		return
+	}
+	for _, e := range elements.Elements {
+		g.localElement(e, s, defaultNamespace, depth)
+		if choice {
+			return
+		}
+	}
+	for _, sequence := range elements.Sequences {
+		g.group(sequence, s, defaultNamespace, depth, false)
+	}
+	for _, nested := range elements.Choices {
+		g.group(nested, s, defaultNamespace, depth, true)
+	}
+}
+
+func (g *generator) simpleExample(t *simpleType) string {
+	if t.Restriction == nil {
+		return "test"
+	}
+	if len(t.Restriction.Enumerations) > 0 {
+		return t.Restriction.Enumerations[0].Value
+	}
+	if example, ok := builtinExamples[localName(t.Restriction.Base)]; ok {
+		return example
+	}
+	return "test"
+}
+
+// open writes a start tag, declaring namespace as the default namespace
+// when declare is true
+func (g *generator) open(name string, namespace string, declare bool) {
+	g.buffer.WriteString("<" + name)
+	if declare {
+		g.buffer.WriteString(` xmlns="`)
+		_ = xml.EscapeText(&g.buffer, []byte(namespace))
+		g.buffer.WriteString(`"`)
+	}
+	g.buffer.WriteString(">")
+}
+
+func (g *generator) close(name string) {
+	g.buffer.WriteString("</" + name + ">")
+}
+
+func (g *generator) text(value string) {
+	_ = xml.EscapeText(&g.buffer, []byte(value))
+}
+
+func (g *generator) String() string {
+	return g.buffer.String()
+}
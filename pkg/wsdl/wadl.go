@@ -0,0 +1,160 @@
+package wsdl
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// application is a WADL description
+type application struct {
+	Resources []struct {
+		Base      string          `xml:"base,attr"`
+		Resources []*wadlResource `xml:"resource"`
+	} `xml:"resources"`
+}
+
+type wadlResource struct {
+	Path      string          `xml:"path,attr"`
+	Params    []wadlParam     `xml:"param"`
+	Methods   []wadlMethod    `xml:"method"`
+	Resources []*wadlResource `xml:"resource"`
+}
+
+type wadlMethod struct {
+	Name    string `xml:"name,attr"`
+	ID      string `xml:"id,attr"`
+	Request struct {
+		Params          []wadlParam `xml:"param"`
+		Representations []struct {
+			MediaType string      `xml:"mediaType,attr"`
+			Params    []wadlParam `xml:"param"`
+		} `xml:"representation"`
+	} `xml:"request"`
+}
+
+type wadlParam struct {
+	Name    string `xml:"name,attr"`
+	Style   string `xml:"style,attr"`
+	Type    string `xml:"type,attr"`
+	Default string `xml:"default,attr"`
+	Fixed   string `xml:"fixed,attr"`
+	Options []struct {
+		Value string `xml:"value,attr"`
+	} `xml:"option"`
+}
+
+// example returns the fixed, default or first option value of a parameter,
+// otherwise an example of its type
+func (p wadlParam) example() string {
+	switch {
+	case p.Fixed != "":
+		return p.Fixed
+	case p.Default != "":
+		return p.Default
+	case len(p.Options) > 0:
+		return p.Options[0].Value
+	}
+	if example, ok := builtinExamples[localName(p.Type)]; ok {
+		return example
+	}
+	return "test"
+}
+
+// wadlOperations returns an operation for every method of every resource
+func (d *Document) wadlOperations() []Operation {
+	var operations []Operation
+	for _, resources := range d.application.Resources {
+		base := d.endpoint(resources.Base)
+		for _, resource := range resources.Resources {
+			operations = append(operations, wadlResourceOperations(resource, strings.TrimSuffix(base, "/"), nil)...)
+		}
+	}
+	return operations
+}
+
+// wadlResourceOperations returns the operations of a resource and of its
+// sub resources, the parameters of the parent resources apply
+func wadlResourceOperations(resource *wadlResource, parent string, params []wadlParam) []Operation {
+	path := parent + "/" + strings.Trim(resource.Path, "/")
+	params = append(append([]wadlParam{}, params...), resource.Params...)
+
+	var operations []Operation
+	for _, method := range resource.Methods {
+		operations = append(operations, wadlOperation(method, path, params))
+	}
+	for _, child := range resource.Resources {
+		operations = append(operations, wadlResourceOperations(child, strings.TrimSuffix(path, "/"), params)...)
+	}
+	return operations
+}
+
+func wadlOperation(method wadlMethod, path string, params []wadlParam) Operation {
+	operation := Operation{Name: method.ID}
+	if operation.Name == "" {
+		operation.Name = method.Name + " " + path
+	}
+	request := Request{Method: strings.ToUpper(method.Name), Headers: map[string]string{}}
+	query := url.Values{}
+	for _, param := range append(append([]wadlParam{}, params...), method.Request.Params...) {
+		operation.Input = append(operation.Input, Part{Name: param.Name, Type: param.Type})
+		switch param.Style {
+		case "template":
+			path = templateRegex(param.Name).ReplaceAllString(path, url.PathEscape(param.example()))
+		case "query":
+			query.Add(param.Name, param.example())
+		case "header":
+			request.Headers[param.Name] = param.example()
+		case "matrix":
+			path += ";" + param.Name + "=" + url.PathEscape(param.example())
+		}
+	}
+	request.URL = path
+	if len(query) > 0 {
+		request.URL += "?" + query.Encode()
+	}
+
+	// the form representation first, then JSON
+	representations := method.Request.Representations
+	sort.SliceStable(representations, func(i, j int) bool {
+		return representationRank(representations[i].MediaType) < representationRank(representations[j].MediaType)
+	})
+	for _, representation := range representations {
+		switch representationRank(representation.MediaType) {
+		case 0:
+			form := url.Values{}
+			for _, param := range representation.Params {
+				operation.Input = append(operation.Input, Part{Name: param.Name, Type: param.Type})
+				form.Add(param.Name, param.example())
+			}
+			request.Body = form.Encode()
+		case 1:
+			request.Body = "{}"
+		default:
+			continue
+		}
+		request.Headers["Content-Type"] = representation.MediaType
+		break
+	}
+	if len(request.Headers) == 0 {
+		request.Headers = nil
+	}
+	operation.Request = request
+	return operation
+}
+
+// templateRegex matches a template parameter of a path, {name} or {name: regex}
+func templateRegex(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\{\s*` + regexp.QuoteMeta(name) + `\s*(?::[^}]*)?\}`)
+}
+
+func representationRank(mediaType string) int {
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return 0
+	case strings.Contains(mediaType, "json"):
+		return 1
+	}
+	return 2
+}
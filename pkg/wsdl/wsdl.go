@@ -0,0 +1,403 @@
+package wsdl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Kinds of service descriptions
+const (
+	KindWSDL = "wsdl"
+	KindWADL = "wadl"
+)
+
+// Namespaces of the SOAP bindings and envelopes
+const (
+	soap11Binding  = "http://schemas.xmlsoap.org/wsdl/soap/"
+	soap12Binding  = "http://schemas.xmlsoap.org/wsdl/soap12/"
+	soap11Envelope = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Envelope = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// maxImports bounds the documents imported by a description
+const maxImports = 20
+
+// Operation is an operation of a service with an example request
+type Operation struct {
+	Service string `json:"service,omitempty"`
+	Name    string `json:"name"`
+	// SOAPAction is the action of a SOAP operation, sent in the SOAPAction
+	// header with SOAP 1.1 and in the Content-Type with SOAP 1.2
+	SOAPAction  string  `json:"soap_action,omitempty"`
+	SOAPVersion string  `json:"soap_version,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Input       []Part  `json:"input,omitempty"`
+	Output      []Part  `json:"output,omitempty"`
+	Request     Request `json:"request"`
+}
+
+// Part is a part of a message, or a parameter of a WADL method, with its
+// schema element or type
+type Part struct {
+	Name    string `json:"name" xml:"name,attr"`
+	Element string `json:"element,omitempty" xml:"element,attr"`
+	Type    string `json:"type,omitempty" xml:"type,attr"`
+}
+
+// Request is an example request for an operation
+type Request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// Document is a WSDL or WADL description with the documents it imports
+type Document struct {
+	URL         string
+	Kind        string
+	definitions []*definitions
+	schemas     []*schema
+	application *application
+	// imports are the URLs of the documents to import
+	imports []string
+}
+
+type definitions struct {
+	TargetNamespace string `xml:"targetNamespace,attr"`
+	Imports         []struct {
+		Location string `xml:"location,attr"`
+	} `xml:"import"`
+	Types struct {
+		Schemas []*schema `xml:"schema"`
+	} `xml:"types"`
+	Messages []struct {
+		Name  string `xml:"name,attr"`
+		Parts []Part `xml:"part"`
+	} `xml:"message"`
+	PortTypes []struct {
+		Name       string `xml:"name,attr"`
+		Operations []struct {
+			Name  string `xml:"name,attr"`
+			Input struct {
+				Message string `xml:"message,attr"`
+			} `xml:"input"`
+			Output struct {
+				Message string `xml:"message,attr"`
+			} `xml:"output"`
+		} `xml:"operation"`
+	} `xml:"portType"`
+	Bindings []struct {
+		Name    string `xml:"name,attr"`
+		Type    string `xml:"type,attr"`
+		Binding []struct {
+			XMLName xml.Name
+			Style   string `xml:"style,attr"`
+		} `xml:"binding"`
+		Operations []struct {
+			Name      string `xml:"name,attr"`
+			Operation []struct {
+				SOAPAction string `xml:"soapAction,attr"`
+				Style      string `xml:"style,attr"`
+			} `xml:"operation"`
+			Input struct {
+				Body []struct {
+					Namespace string `xml:"namespace,attr"`
+				} `xml:"body"`
+			} `xml:"input"`
+		} `xml:"operation"`
+	} `xml:"binding"`
+	Services []struct {
+		Name  string `xml:"name,attr"`
+		Ports []struct {
+			Binding string `xml:"binding,attr"`
+			Address []struct {
+				Location string `xml:"location,attr"`
+			} `xml:"address"`
+		} `xml:"port"`
+	} `xml:"service"`
+}
+
+// Parse parses a WSDL 1.1 or WADL description served at docURL
+func Parse(docURL string, data []byte) (*Document, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+	document := &Document{URL: docURL}
+	switch root.Local {
+	case "definitions":
+		document.Kind = KindWSDL
+		err = document.addDefinitions(docURL, data)
+	case "application":
+		document.Kind = KindWADL
+		document.application = &application{}
+		err = xml.Unmarshal(data, document.application)
+	default:
+		return nil, errors.New("not a WSDL 1.1 or WADL description")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Load parses a description and fetches the WSDL and XML schema documents
+// it imports
+func Load(docURL string, data []byte, fetch FetchFunc) (*Document, error) {
+	document, err := Parse(docURL, data)
+	if err != nil {
+		return nil, err
+	}
+	visited := map[string]struct{}{docURL: {}}
+	for len(document.imports) > 0 && len(visited) <= maxImports {
+		location := document.imports[0]
+		document.imports = document.imports[1:]
+		if _, ok := visited[location]; ok {
+			continue
+		}
+		visited[location] = struct{}{}
+		status, body, err := fetch(location)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		_ = document.addImport(location, body)
+	}
+	return document, nil
+}
+
+// addImport adds an imported WSDL or XML schema document
+func (d *Document) addImport(location string, data []byte) error {
+	root, err := rootElement(data)
+	if err != nil {
+		return err
+	}
+	switch root.Local {
+	case "definitions":
+		return d.addDefinitions(location, data)
+	case "schema":
+		s := &schema{}
+		if err := xml.Unmarshal(data, s); err != nil {
+			return err
+		}
+		d.addSchemas(location, s)
+		return nil
+	}
+	return errors.New("unexpected imported document " + root.Local)
+}
+
+func (d *Document) addDefinitions(location string, data []byte) error {
+	defs := &definitions{}
+	if err := xml.Unmarshal(data, defs); err != nil {
+		return err
+	}
+	d.definitions = append(d.definitions, defs)
+	for _, imported := range defs.Imports {
+		d.addLocation(location, imported.Location)
+	}
+	d.addSchemas(location, defs.Types.Schemas...)
+	return nil
+}
+
+func (d *Document) addSchemas(location string, schemas ...*schema) {
+	for _, s := range schemas {
+		d.schemas = append(d.schemas, s)
+		for _, imported := range s.Imports {
+			d.addLocation(location, imported.SchemaLocation)
+		}
+		for _, included := range s.Includes {
+			d.addLocation(location, included.SchemaLocation)
+		}
+	}
+}
+
+func (d *Document) addLocation(base string, location string) {
+	if location == "" {
+		return
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return
+	}
+	if resolved, err := baseURL.Parse(location); err == nil {
+		d.imports = append(d.imports, resolved.String())
+	}
+}
+
+// Operations returns the operations of the description with an example
+// request for each
+func (d *Document) Operations() []Operation {
+	if d.Kind == KindWADL {
+		return d.wadlOperations()
+	}
+	return d.soapOperations()
+}
+
+// soapOperations returns an operation for every SOAP port of every service
+func (d *Document) soapOperations() []Operation {
+	set := newSchemaSet(d.schemas)
+	var operations []Operation
+	for _, defs := range d.definitions {
+		for _, service := range defs.Services {
+			for _, port := range service.Ports {
+				if len(port.Address) == 0 {
+					continue
+				}
+				endpoint := d.endpoint(port.Address[0].Location)
+				operations = append(operations, d.portOperations(set, service.Name, localName(port.Binding), endpoint)...)
+			}
+		}
+	}
+	return operations
+}
+
+func (d *Document) portOperations(set *schemaSet, service string, bindingName string, endpoint string) []Operation {
+	var operations []Operation
+	for _, defs := range d.definitions {
+		for _, binding := range defs.Bindings {
+			if binding.Name != bindingName {
+				continue
+			}
+			version, bindingStyle := "", "document"
+			for _, soapBinding := range binding.Binding {
+				switch soapBinding.XMLName.Space {
+				case soap11Binding:
+					version = "1.1"
+				case soap12Binding:
+					version = "1.2"
+				}
+				if soapBinding.Style != "" {
+					bindingStyle = soapBinding.Style
+				}
+			}
+			// HTTP bindings have no SOAP binding
+			if version == "" {
+				continue
+			}
+			for _, bindingOperation := range binding.Operations {
+				operation := Operation{Service: service, Name: bindingOperation.Name, SOAPVersion: version, Style: bindingStyle}
+				if len(bindingOperation.Operation) > 0 {
+					operation.SOAPAction = bindingOperation.Operation[0].SOAPAction
+					if style := bindingOperation.Operation[0].Style; style != "" {
+						operation.Style = style
+					}
+				}
+				input, output := d.messages(localName(binding.Type), bindingOperation.Name)
+				operation.Input, operation.Output = d.parts(input), d.parts(output)
+
+				namespace := defs.TargetNamespace
+				if body := bindingOperation.Input.Body; len(body) > 0 && body[0].Namespace != "" {
+					namespace = body[0].Namespace
+				}
+				operation.Request = operation.request(set, endpoint, namespace)
+				operations = append(operations, operation)
+			}
+		}
+	}
+	return operations
+}
+
+// messages returns the input and output message names of an operation of a port type
+func (d *Document) messages(portTypeName string, operationName string) (string, string) {
+	for _, defs := range d.definitions {
+		for _, portType := range defs.PortTypes {
+			if portType.Name != portTypeName {
+				continue
+			}
+			for _, operation := range portType.Operations {
+				if operation.Name == operationName {
+					return localName(operation.Input.Message), localName(operation.Output.Message)
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+func (d *Document) parts(messageName string) []Part {
+	for _, defs := range d.definitions {
+		for _, message := range defs.Messages {
+			if message.Name == messageName {
+				return message.Parts
+			}
+		}
+	}
+	return nil
+}
+
+// request builds the SOAP request of an operation, the body holds the part
+// elements for document style and an operation wrapper for rpc style
+func (o Operation) request(set *schemaSet, endpoint string, namespace string) Request {
+	g := newGenerator(set)
+	if o.Style == "rpc" {
+		g.buffer.WriteString("<ns:" + o.Name + ` xmlns:ns="`)
+		g.text(namespace)
+		g.buffer.WriteString(`">`)
+		for _, part := range o.Input {
+			if part.Element != "" {
+				g.globalElement(part.Element)
+			} else {
+				g.typed(part.Name, part.Type, "")
+			}
+		}
+		g.buffer.WriteString("</ns:" + o.Name + ">")
+	} else {
+		for _, part := range o.Input {
+			if part.Element == "" || !g.globalElement(part.Element) {
+				g.typed(part.Name, part.Type, "")
+			}
+		}
+	}
+
+	request := Request{Method: "POST", URL: endpoint, Headers: map[string]string{}}
+	envelope := soap11Envelope
+	if o.SOAPVersion == "1.2" {
+		envelope = soap12Envelope
+		request.Headers["Content-Type"] = "application/soap+xml; charset=utf-8"
+		if o.SOAPAction != "" {
+			request.Headers["Content-Type"] += `; action="` + o.SOAPAction + `"`
+		}
+	} else {
+		request.Headers["Content-Type"] = "text/xml; charset=utf-8"
+		request.Headers["SOAPAction"] = `"` + o.SOAPAction + `"`
+	}
+	request.Body = `<?xml version="1.0" encoding="utf-8"?>` +
+		`<soap:Envelope xmlns:soap="` + envelope + `"><soap:Body>` + g.String() + `</soap:Body></soap:Envelope>`
+	return request
+}
+
+// endpoint resolves the address of a port against the description URL. The
+// address of services behind a proxy often names an internal host, the
+// host of the description is used instead.
+func (d *Document) endpoint(location string) string {
+	docURL, err := url.Parse(d.URL)
+	if err != nil {
+		return location
+	}
+	address, err := docURL.Parse(location)
+	if err != nil || location == "" {
+		docURL.RawQuery = ""
+		return docURL.String()
+	}
+	if host := address.Hostname(); host == "localhost" || !strings.Contains(host, ".") || strings.HasPrefix(host, "127.") {
+		address.Scheme, address.Host = docURL.Scheme, docURL.Host
+	}
+	return address.String()
+}
+
+// rootElement returns the name of the root element of an XML document
+func rootElement(data []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package wsdl
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testdataURLs are the URLs served by fetchTestdata
+var testdataURLs = map[string]string{
+	"https://example.com/svc/types.xsd":        "types.xsd",
+	"https://example.com/api/application.wadl": "application.wadl",
+}
+
+// fetchTestdata serves the files of testdata and records the URLs fetched
+func fetchTestdata(fetched *[]string) FetchFunc {
+	return func(url string) (int, []byte, error) {
+		*fetched = append(*fetched, url)
+		name, ok := testdataURLs[url]
+		if !ok {
+			return 404, nil, nil
+		}
+		data, err := os.ReadFile("testdata/" + name)
+		return 200, data, err
+	}
+}
+
+func TestLoadWSDL(t *testing.T) {
+	data, err := os.ReadFile("testdata/calculator.wsdl")
+	assert.Nil(t, err)
+	var fetched []string
+	document, err := Load("https://example.com/svc/calculator.wsdl", data, fetchTestdata(&fetched))
+	assert.Nil(t, err)
+	assert.Equal(t, KindWSDL, document.Kind)
+	assert.Equal(t, []string{"https://example.com/svc/types.xsd"}, fetched, "should fetch the imported schemas")
+
+	operations := document.Operations()
+	assert.Len(t, operations, 3, "should skip the HTTP bindings")
+
+	add := operations[0]
+	assert.Equal(t, "Calculator", add.Service)
+	assert.Equal(t, "Add", add.Name)
+	assert.Equal(t, "1.1", add.SOAPVersion)
+	assert.Equal(t, []Part{{Name: "parameters", Element: "tns:Add"}}, add.Input)
+	assert.Equal(t, Request{
+		Method:  "POST",
+		URL:     "https://example.com/calculator.asmx",
+		Headers: map[string]string{"Content-Type": "text/xml; charset=utf-8", "SOAPAction": `"http://tempuri.org/Add"`},
+		Body: `<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+			`<Add xmlns="http://tempuri.org/"><intA>1</intA><intB>1</intB></Add></soap:Body></soap:Envelope>`,
+	}, add.Request, "should replace the internal host of the address")
+
+	assert.Contains(t, operations[1].Request.Body,
+		`<SaveUser xmlns="http://tempuri.org/"><user><name>test</name><role>admin</role><manager></manager><email>test</email></user></SaveUser>`,
+		"should expand the imported types, stop recursion and keep the first element of a choice")
+
+	soap12 := operations[2]
+	assert.Equal(t, "1.2", soap12.SOAPVersion)
+	assert.Equal(t, map[string]string{"Content-Type": `application/soap+xml; charset=utf-8; action="http://tempuri.org/Add"`}, soap12.Request.Headers)
+	assert.Contains(t, soap12.Request.Body, `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">`)
+}
+
+func TestRPCStyle(t *testing.T) {
+	data, err := os.ReadFile("testdata/stock.wsdl")
+	assert.Nil(t, err)
+	document, err := Parse("https://example.com/stockquote?wsdl", data)
+	assert.Nil(t, err)
+	operations := document.Operations()
+	assert.Len(t, operations, 1)
+	assert.Equal(t, "rpc", operations[0].Style)
+	assert.Equal(t, "https://api.example.org/stockquote", operations[0].Request.URL)
+	assert.Contains(t, operations[0].Request.Body,
+		`<soap:Body><ns:GetLastTradePrice xmlns:ns="http://example.com/stockquote"><tickerSymbol>test</tickerSymbol><time>2024-01-01T00:00:00Z</time></ns:GetLastTradePrice></soap:Body>`)
+}
+
+func TestWADL(t *testing.T) {
+	data, err := os.ReadFile("testdata/application.wadl")
+	assert.Nil(t, err)
+	document, err := Parse("https://example.com/api/application.wadl", data)
+	assert.Nil(t, err)
+	assert.Equal(t, KindWADL, document.Kind)
+
+	operations := document.Operations()
+	assert.Len(t, operations, 4)
+	assert.Equal(t, Request{Method: "GET", URL: "https://example.com/api/users?page=1", Headers: map[string]string{"X-Tenant": "test"}}, operations[0].Request)
+	assert.Equal(t, Request{
+		Method: "POST", URL: "https://example.com/api/users",
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, Body: "admin=true&name=test",
+	}, operations[1].Request, "should prefer the form representation")
+	assert.Equal(t, Request{Method: "DELETE", URL: "https://example.com/api/users/1"}, operations[2].Request)
+	assert.Equal(t, "https://example.com/api/users/1/avatar", operations[3].Request.URL, "should apply the parameters of the parent resources")
+	assert.Equal(t, "{}", operations[3].Request.Body)
+
+	_, err = Parse("https://example.com/", []byte("<html><body>ok</body></html>"))
+	assert.NotNil(t, err)
+}
+
+func TestDescriptionURLs(t *testing.T) {
+	assert.Equal(t, []string{"https://example.com/calculator.asmx?wsdl"}, DescriptionURLs("https://example.com/calculator.asmx?op=Add", nil))
+	assert.Equal(t, []string{"https://example.com/axis/services/Version?wsdl"}, DescriptionURLs("https://example.com/axis/services/Version", nil))
+	assert.Equal(t, []string{"https://example.com/services/Echo?wsdl", "https://example.com/api/application.wadl"},
+		DescriptionURLs("https://example.com/services", []byte(`<a href="/services/Echo?wsdl">Echo</a> <a href='api/application.wadl'>WADL</a> <a href="/about">About</a>`)))
+	assert.Empty(t, DescriptionURLs("https://example.com/index.php", []byte(`<a href="/login">login</a>`)))
+}
+
+func TestCatalog(t *testing.T) {
+	var fetched []string
+	catalog := NewCatalog(true)
+	operations := catalog.Discover("https://example.com/svc/calculator.asmx?op=Add", []byte("<html>Calculator</html>"), fetchTestdata(&fetched))
+	assert.Empty(t, operations, "the description of calculator.asmx?wsdl is not served")
+	assert.Equal(t, []string{"https://example.com/svc/calculator.asmx?wsdl"}, fetched)
+
+	data, err := os.ReadFile("testdata/calculator.wsdl")
+	assert.Nil(t, err)
+	operations = catalog.Discover("https://example.com/svc/calculator.wsdl", data, fetchTestdata(&fetched))
+	assert.Len(t, operations, 3, "should parse the description crawled")
+	assert.Empty(t, catalog.Discover("https://example.com/svc/calculator.wsdl", data, fetchTestdata(&fetched)), "should claim each description once")
+
+	fetched = nil
+	operations = catalog.Probe("https://example.com/index.html", fetchTestdata(&fetched))
+	assert.Len(t, operations, 4, "should find the WADL of the common locations")
+	assert.Equal(t, len(Locations), len(fetched))
+	assert.Empty(t, catalog.Probe("https://example.com/other", fetchTestdata(&fetched)), "should probe each site once")
+
+	entries := catalog.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "https://example.com/api/application.wadl", entries[0].URL)
+	assert.Equal(t, KindWADL, entries[0].Kind)
+
+	safe := NewCatalog(false)
+	assert.Empty(t, safe.Discover("https://example.com/svc/calculator.wsdl", data, fetchTestdata(&fetched)), "should not crawl the SOAP operations")
+	probed := safe.Probe("https://example.com/index.html", fetchTestdata(&fetched))
+	assert.NotEmpty(t, probed)
+	for _, operation := range probed {
+		assert.Equal(t, "GET", operation.Request.Method, "should not crawl the WADL methods changing the server state")
+	}
+	assert.Len(t, safe.Entries(), 2, "should record every description")
+	assert.Len(t, safe.Entries()[1].Operations, 3)
+
+	var disabled *Catalog
+	assert.Nil(t, disabled.Discover("https://example.com/svc/calculator.wsdl", data, fetchTestdata(&fetched)))
+	assert.Nil(t, disabled.Entries())
+}
+
+func TestRequestKey(t *testing.T) {
+	add := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Add xmlns="http://tempuri.org/"><intA>1</intA></Add></soap:Body></soap:Envelope>`
+	addOther := strings.Replace(add, "<intA>1</intA>", "<intA>2</intA><intB>3</intB>", 1)
+	subtract := strings.Replace(strings.Replace(add, "<Add ", "<Subtract ", 1), "</Add>", "</Subtract>", 1)
+
+	a := RequestKey("text/xml; charset=utf-8", `"http://tempuri.org/Add"`, add)
+	assert.NotEmpty(t, a)
+	assert.Equal(t, a, RequestKey("text/xml", `"http://tempuri.org/Add"`, addOther), "should ignore the parameters")
+	assert.NotEqual(t, RequestKey("text/xml", "", add), RequestKey("text/xml", "", subtract), "should tell the operations of an endpoint apart")
+	assert.NotEqual(t, a, RequestKey(`application/soap+xml; action="http://tempuri.org/Subtract"`, "", subtract))
+	assert.Empty(t, RequestKey("application/x-www-form-urlencoded", "", "a=1"))
+	assert.Empty(t, RequestKey("text/xml", "", "<user><name>a</name></user>"))
+}